@@ -7,6 +7,12 @@ import (
 
 type Config struct {
 	Port int `envconfig:"PORT" default:"5000"`
+	// CursorSecret signs pagination cursors, a random one is generated at startup when empty
+	CursorSecret string `envconfig:"CURSOR_SECRET"`
+	// ExportsDir is where export jobs and their results are stored
+	ExportsDir string `envconfig:"EXPORTS_DIR" default:"exports"`
+	// ExportsToken is the github token resuming unfinished exports at startup, they wait for a client to resume them when empty
+	ExportsToken string `envconfig:"EXPORTS_TOKEN"`
 }
 
 func newConfig() (*Config, error) {
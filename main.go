@@ -12,31 +12,57 @@ import (
 )
 
 func main() {
-	mux := initDependencies()
+	if err := godotenv.Load(); err != nil {
+		log.Fatalf("Warning: .env file not found")
+	}
 
 	cfg, err := newConfig()
 	if err != nil {
 		log.Fatal(err)
 	}
 
+	mux := initDependencies(cfg)
+
 	log.Printf("Server starting on %d", cfg.Port)
 	if err := http.ListenAndServe(fmt.Sprintf(":%d", cfg.Port), mux); err != nil {
 		log.Fatal(err)
 	}
 }
 
-func initDependencies() *http.ServeMux {
-	if err := godotenv.Load(); err != nil {
-		log.Fatalf("Warning: .env file not found")
-	}
-
+func initDependencies(cfg *Config) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	rg := repositories.NewGitHubRepository()
-	ru := usecases.NewRepositoryUseCase(rg)
+	ru := usecases.NewRepositoryUseCase(rg, []byte(cfg.CursorSecret))
 	rc := controllers.NewRepositoryController(ru)
 
+	es, err := repositories.NewExportStore(cfg.ExportsDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	exportsHeader := ""
+	if cfg.ExportsToken != "" {
+		exportsHeader = "Bearer " + cfg.ExportsToken
+	}
+	eu := usecases.NewExportUseCase(ru, es, exportsHeader)
+	if err := eu.ResumeExports(); err != nil {
+		log.Fatal(err)
+	}
+	ec := controllers.NewExportController(ru, eu)
+
+	lu := usecases.NewLanguageUseCase(rg)
+	lc := controllers.NewLanguageController(lu)
+
 	mux.HandleFunc("/repos", rc.SearchRepositories)
+	mux.HandleFunc("/repos/search", rc.SearchStructured)
+	mux.HandleFunc("/repos/explain", rc.ExplainQuery)
+	mux.HandleFunc("/repos/batch", rc.SearchBatch)
+	mux.HandleFunc("/repos/", rc.GetRepository)
+	mux.HandleFunc("/code", rc.SearchCode)
+	mux.HandleFunc("/exports", ec.CreateExport)
+	mux.HandleFunc("/exports/", ec.GetExport)
+	mux.HandleFunc("/languages/share", lc.LanguageShare)
+	mux.HandleFunc("/languages/", lc.LanguageTimeseries)
 
 	return mux
 }
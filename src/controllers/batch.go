@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+const (
+	// maxBatchQueries bounds the searches of a batch
+	maxBatchQueries = 20
+	// maxBatchBodySize bounds the JSON documents accepted by POST /repos/batch
+	maxBatchBodySize = 1 << 20
+)
+
+// SearchBatch handles POST /repos/batch, it runs several searches and answers the outcome of each of them
+// Every query is validated first, a single invalid query rejects the whole batch before GitHub is called.
+func (rc *RepositoryController) SearchBatch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		renderError(w, http.StatusMethodNotAllowed, "method not allowed, use POST to run a batch of searches")
+		return
+	}
+
+	header := r.Header.Get("Authorization")
+	err := validateHeader(&header)
+	if err != nil {
+		renderError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBatchBodySize))
+	decoder.DisallowUnknownFields()
+
+	var batch models.BatchSearchRequest
+	if err := decoder.Decode(&batch); err != nil {
+		renderError(w, http.StatusBadRequest, fmt.Sprintf("invalid batch document: %s", err))
+		return
+	}
+
+	if len(batch.Queries) == 0 || len(batch.Queries) > maxBatchQueries {
+		renderError(w, http.StatusBadRequest, fmt.Sprintf("queries must contain between 1 and %d searches", maxBatchQueries))
+		return
+	}
+
+	params := make([]*models.RepositorySearchParams, 0, len(batch.Queries))
+	var invalid []string
+	for i, query := range batch.Queries {
+		p, err := rc.batchQueryParams(query, header)
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("queries[%d]: %s", i, err))
+			continue
+		}
+		params = append(params, p)
+	}
+
+	if len(invalid) > 0 {
+		renderError(w, http.StatusBadRequest, strings.Join(invalid, "; "))
+		return
+	}
+
+	results := rc.ru.SearchBatch(params)
+
+	response := models.BatchSearchResponse{Results: make([]models.BatchSearchResult, len(results))}
+	for i, result := range results {
+		response.Results[i].Query = params[i].Query
+
+		if result.Err != nil {
+			response.Results[i].Status = searchErrorStatus(result.Err)
+			response.Results[i].Error = result.Err.Error()
+			continue
+		}
+
+		// Cursors of each search are read from GET /repos
+		result.Response.Links = pageLinks("/repos", result.Response.Cursors)
+		response.Results[i].Status = http.StatusOK
+		response.Results[i].Result = result.Response
+	}
+
+	renderJSON(w, http.StatusOK, response)
+}
+
+// batchQueryParams validates a search of a batch like /repos validates its parameters
+func (rc *RepositoryController) batchQueryParams(query models.BatchQuery, header string) (*models.RepositorySearchParams, error) {
+	in := searchInput{
+		query:         query.Q,
+		languagesMode: query.Languages,
+		sort:          query.Sort,
+		order:         query.Order,
+		fill:          strconv.FormatBool(query.Fill),
+	}
+	if query.PerPage != 0 {
+		in.perPage = strconv.Itoa(query.PerPage)
+	}
+	if query.Page != 0 {
+		in.page = strconv.Itoa(query.Page)
+	}
+
+	params, err := rc.searchParams(in, header)
+	if err != nil {
+		return nil, err
+	}
+
+	maxCalls := ""
+	if query.MaxGitHubCalls != 0 {
+		maxCalls = strconv.Itoa(query.MaxGitHubCalls)
+	}
+	if err := validateBudget(maxCalls, query.OnBudget, "", params); err != nil {
+		return nil, err
+	}
+
+	// Batches only fetch the languages
+	if err := validateInclude("", params); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/usecases"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchBatchEndpoint(t *testing.T) {
+	header := "Bearer tokentoken"
+	goParams := &models.RepositorySearchParams{
+		Query:         "language:go",
+		Header:        header,
+		Language:      "go",
+		PerPage:       "10",
+		Page:          "1",
+		LanguagesMode: models.LanguagesModeRequested,
+		Order:         models.OrderDesc,
+	}
+	rustParams := &models.RepositorySearchParams{
+		Query:          "language:rust",
+		Header:         header,
+		Language:       "rust",
+		PerPage:        "100",
+		Page:           "1",
+		LanguagesMode:  models.LanguagesModeRequested,
+		Order:          models.OrderDesc,
+		MaxGitHubCalls: 5,
+	}
+
+	tests := map[string]struct {
+		method         string
+		body           string
+		header         string
+		mockCall       func(*mockRepositoryUseCase)
+		expectedStatus int
+		checkBody      func(*testing.T, string)
+	}{
+		"nominal": {
+			method: http.MethodPost,
+			body:   `{"queries":[{"q":"language:go","per_page":10},{"q":"language:rust","max_github_calls":5}]}`,
+			header: header,
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ValidateQuery", "language:go").Return("go", nil)
+				m.On("ValidateQuery", "language:rust").Return("rust", nil)
+				m.On("SearchBatch", []*models.RepositorySearchParams{goParams, rustParams}).Return([]usecases.BatchResult{
+					{Response: &models.RepositorySearchResponse{TotalCount: 1, Cursors: &models.PageLinks{First: "first"}}},
+					{Err: usecases.ErrBudgetExceeded},
+				})
+			},
+			expectedStatus: http.StatusOK,
+			checkBody: func(t *testing.T, body string) {
+				var response models.BatchSearchResponse
+				assert.NoError(t, json.Unmarshal([]byte(body), &response))
+				assert.Len(t, response.Results, 2)
+
+				assert.Equal(t, "language:go", response.Results[0].Query)
+				assert.Equal(t, http.StatusOK, response.Results[0].Status)
+				assert.Equal(t, "/repos?cursor=first", response.Results[0].Result.Links.First)
+
+				assert.Equal(t, "language:rust", response.Results[1].Query)
+				assert.Equal(t, http.StatusUnprocessableEntity, response.Results[1].Status)
+				assert.NotEmpty(t, response.Results[1].Error)
+				assert.Nil(t, response.Results[1].Result)
+			},
+		},
+		"invalid query, return error": {
+			method: http.MethodPost,
+			body:   `{"queries":[{"q":"language:go"},{"q":"tetris"}]}`,
+			header: header,
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ValidateQuery", "language:go").Return("go", nil)
+				m.On("ValidateQuery", "tetris").Return("", errors.New("no language filter set"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			checkBody: func(t *testing.T, body string) {
+				assert.Contains(t, body, "queries[1]: no language filter set")
+			},
+		},
+		"no query, return error": {
+			method:         http.MethodPost,
+			body:           `{"queries":[]}`,
+			header:         header,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"too many queries, return error": {
+			method:         http.MethodPost,
+			body:           `{"queries":[` + strings.Repeat(`{"q":"language:go"},`, maxBatchQueries) + `{"q":"language:go"}]}`,
+			header:         header,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"unknown field, return error": {
+			method:         http.MethodPost,
+			body:           `{"queries":[{"query":"language:go"}]}`,
+			header:         header,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"missing header, return error": {
+			method:         http.MethodPost,
+			body:           `{"queries":[{"q":"language:go"}]}`,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"wrong method, return error": {
+			method:         http.MethodGet,
+			header:         header,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/repos/batch", strings.NewReader(tt.body))
+			if tt.header != "" {
+				req.Header.Add("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			m := new(mockRepositoryUseCase)
+			if tt.mockCall != nil {
+				tt.mockCall(m)
+			}
+
+			NewRepositoryController(m).SearchBatch(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.checkBody != nil {
+				tt.checkBody(t, w.Body.String())
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
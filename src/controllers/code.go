@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// SearchCode handles GET /code, the files matching a code search grouped by repository
+// Each repository comes with its languages, like a repository search hit.
+func (rc *RepositoryController) SearchCode(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		renderError(w, http.StatusMethodNotAllowed, "method not allowed, use GET to search code")
+		return
+	}
+
+	header := r.Header.Get("Authorization")
+	err := validateHeader(&header)
+	if err != nil {
+		renderError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	values := r.URL.Query()
+	perPage, page := values.Get("per_page"), values.Get("page")
+	if err := validatePagination(&perPage, &page); err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	languagesMode := values.Get("languages")
+	if languagesMode == "" {
+		languagesMode = models.LanguagesModeAll
+	}
+	if err := validateLanguagesMode(&languagesMode); err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	result, err := rc.ru.SearchCode(&models.CodeSearchParams{
+		Query:         values.Get("q"),
+		PerPage:       perPage,
+		Page:          page,
+		Header:        header,
+		LanguagesMode: languagesMode,
+	})
+	if err != nil {
+		renderError(w, repositoryErrorStatus(err), err.Error())
+		return
+	}
+
+	renderJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/usecases"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchCodeEndpoint(t *testing.T) {
+	header := "Bearer tokentoken"
+
+	tests := map[string]struct {
+		method         string
+		url            string
+		header         string
+		mockCall       func(*mockRepositoryUseCase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		"nominal": {
+			method: http.MethodGet,
+			url:    "/code?q=handler+language:go+org:scalingo&per_page=20",
+			header: header,
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("SearchCode", &models.CodeSearchParams{
+					Query:         "handler language:go org:scalingo",
+					PerPage:       "20",
+					Page:          "1",
+					Header:        header,
+					LanguagesMode: models.LanguagesModeAll,
+				}).Return(&models.CodeSearchResult{
+					TotalCount: 1,
+					Repositories: []models.CodeRepository{{
+						Repository: models.Repository{FullName: "scalingo/cli"},
+						Files:      []models.CodeFile{{Name: "main.go", Path: "cmd/main.go"}},
+					}},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"files":[{"name":"main.go","path":"cmd/main.go"`,
+		},
+		"invalid query, return error": {
+			method: http.MethodGet,
+			url:    "/code?q=stars:>10",
+			header: header,
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("SearchCode", mock.Anything).Return((*models.CodeSearchResult)(nil), errors.New("unknown code search qualifier: stars"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"rate limited, return error": {
+			method: http.MethodGet,
+			url:    "/code?q=handler",
+			header: header,
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("SearchCode", mock.Anything).Return((*models.CodeSearchResult)(nil), fmt.Errorf("error searching code: %w", usecases.ErrRateLimited))
+			},
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		"invalid pagination, return error": {
+			method:         http.MethodGet,
+			url:            "/code?q=handler&per_page=500",
+			header:         header,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"invalid languages mode, return error": {
+			method:         http.MethodGet,
+			url:            "/code?q=handler&languages=some",
+			header:         header,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"no Authorization header, return error": {
+			method:         http.MethodGet,
+			url:            "/code?q=handler",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"wrong method, return error": {
+			method:         http.MethodPost,
+			url:            "/code?q=handler",
+			header:         header,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			if tt.header != "" {
+				req.Header.Add("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			m := new(mockRepositoryUseCase)
+			if tt.mockCall != nil {
+				tt.mockCall(m)
+			}
+
+			NewRepositoryController(m).SearchCode(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			m.AssertExpectations(t)
+		})
+	}
+}
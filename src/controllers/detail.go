@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/usecases"
+)
+
+// GetRepository handles GET /repos/{owner}/{name}, the repository is enriched like a search hit
+// Without languages, the full language breakdown is returned.
+func (rc *RepositoryController) GetRepository(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		renderError(w, http.StatusMethodNotAllowed, "method not allowed, use GET to read a repository")
+		return
+	}
+
+	owner, name, err := parseRepositoryPath(r.URL.Path)
+	if err != nil {
+		renderError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	header := r.Header.Get("Authorization")
+	err = validateHeader(&header)
+	if err != nil {
+		renderError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	_, encoder, status, err := negotiateEncoder(r)
+	if err != nil {
+		renderError(w, status, err.Error())
+		return
+	}
+
+	values := r.URL.Query()
+	languagesMode := values.Get("languages")
+	if languagesMode == "" {
+		languagesMode = models.LanguagesModeAll
+	}
+	if err := validateLanguagesMode(&languagesMode); err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	fields, err := validateFields(values.Get("fields"))
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	repo, err := rc.ru.GetRepository(&models.RepositoryParams{
+		Owner:         owner,
+		Name:          name,
+		Header:        header,
+		Language:      values.Get("language"),
+		LanguagesMode: languagesMode,
+	})
+	if err != nil {
+		renderError(w, repositoryErrorStatus(err), err.Error())
+		return
+	}
+
+	repos := []models.Repository{*repo}
+	selectFields(repos, fields)
+
+	renderEncoded(w, encoder, http.StatusOK, repos[0], repos)
+}
+
+// parseRepositoryPath splits /repos/{owner}/{name}
+func parseRepositoryPath(path string) (owner, name string, err error) {
+	parts := strings.Split(strings.TrimPrefix(path, "/repos/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("unknown repository resource %s", path)
+	}
+	return parts[0], parts[1], nil
+}
+
+// repositoryErrorStatus is the status of a failed repository lookup
+func repositoryErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecases.ErrRepositoryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrRateLimited):
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/usecases"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetRepositoryEndpoint(t *testing.T) {
+	header := "Bearer tokentoken"
+
+	tests := map[string]struct {
+		method         string
+		url            string
+		header         string
+		mockCall       func(*mockRepositoryUseCase)
+		expectedStatus int
+		expectedBody   string
+	}{
+		"nominal": {
+			method: http.MethodGet,
+			url:    "/repos/scalingo/cli",
+			header: header,
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("GetRepository", &models.RepositoryParams{
+					Owner:         "scalingo",
+					Name:          "cli",
+					Header:        header,
+					LanguagesMode: models.LanguagesModeAll,
+				}).Return(&models.Repository{FullName: "scalingo/cli"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `"full_name":"scalingo/cli"`,
+		},
+		"requested language with sparse fields": {
+			method: http.MethodGet,
+			url:    "/repos/scalingo/cli?language=go&languages=requested&fields=full_name,languages",
+			header: header,
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("GetRepository", &models.RepositoryParams{
+					Owner:         "scalingo",
+					Name:          "cli",
+					Header:        header,
+					Language:      "go",
+					LanguagesMode: models.LanguagesModeRequested,
+				}).Return(&models.Repository{FullName: "scalingo/cli", Description: "hidden", Languages: models.Languages{"Go": 1}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"full_name":"scalingo/cli","languages":{"Go":1}}` + "\n",
+		},
+		"not found, return error": {
+			method: http.MethodGet,
+			url:    "/repos/scalingo/missing",
+			header: header,
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("GetRepository", mock.Anything).Return((*models.Repository)(nil), fmt.Errorf("error fetching repository: %w", usecases.ErrRepositoryNotFound))
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		"invalid name, return error": {
+			method: http.MethodGet,
+			url:    "/repos/scalingo/..",
+			header: header,
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("GetRepository", mock.Anything).Return((*models.Repository)(nil), errors.New("invalid repository name"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"unknown resource, return error": {
+			method:         http.MethodGet,
+			url:            "/repos/scalingo/cli/issues",
+			header:         header,
+			expectedStatus: http.StatusNotFound,
+		},
+		"invalid languages mode, return error": {
+			method:         http.MethodGet,
+			url:            "/repos/scalingo/cli?languages=some",
+			header:         header,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"no Authorization header, return error": {
+			method:         http.MethodGet,
+			url:            "/repos/scalingo/cli",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"wrong method, return error": {
+			method:         http.MethodPost,
+			url:            "/repos/scalingo/cli",
+			header:         header,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			if tt.header != "" {
+				req.Header.Add("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			m := new(mockRepositoryUseCase)
+			if tt.mockCall != nil {
+				tt.mockCall(m)
+			}
+
+			NewRepositoryController(m).GetRepository(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.expectedBody)
+			m.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// Encoder writes a response in a given format
+// Document formats (JSON) render the whole response, row formats (CSV, NDJSON) only render its repositories
+type Encoder interface {
+	ContentType() string
+	Encode(w io.Writer, response interface{}, repos []models.Repository) error
+}
+
+// encoders are the formats available through the format parameter, by name
+var encoders = map[string]Encoder{
+	"json":   jsonEncoder{},
+	"csv":    csvEncoder{},
+	"ndjson": ndjsonEncoder{},
+}
+
+// RegisterEncoder makes a new format available, or replaces an existing one
+func RegisterEncoder(format string, e Encoder) {
+	encoders[format] = e
+}
+
+// negotiateEncoder picks the encoder from the format parameter, or from the Accept header
+// The supported media type with the highest q value wins, JSON is used when the client accepts anything
+// or sends no Accept header.
+// The returned status is the one to answer when no encoder matches.
+func negotiateEncoder(r *http.Request) (string, Encoder, int, error) {
+	if format := r.URL.Query().Get("format"); format != "" {
+		e, ok := encoders[format]
+		if !ok {
+			return "", nil, http.StatusBadRequest, fmt.Errorf("unknown format %s", format)
+		}
+		return format, e, 0, nil
+	}
+
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return "json", encoders["json"], 0, nil
+	}
+
+	// Formats are sorted so two formats sharing a content type always resolve the same way
+	formats := make([]string, 0, len(encoders))
+	for format := range encoders {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+
+	for _, mediaType := range acceptedMediaTypes(accept) {
+		if mediaType == "*/*" || mediaType == "application/*" {
+			return "json", encoders["json"], 0, nil
+		}
+
+		for _, format := range formats {
+			if encoders[format].ContentType() == mediaType {
+				return format, encoders[format], 0, nil
+			}
+		}
+	}
+
+	return "", nil, http.StatusNotAcceptable, fmt.Errorf("none of the accepted formats %s is supported", accept)
+}
+
+// acceptedMediaTypes returns the media types of an Accept header from the most to the least preferred
+// Types are ranked by their q value, types of equal q keep the order of the header, q=0 excludes a type.
+func acceptedMediaTypes(accept string) []string {
+	type accepted struct {
+		mediaType string
+		q         float64
+	}
+
+	ranked := make([]accepted, 0)
+	for _, mediaRange := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(mediaRange))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if value, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(value, 64)
+			if err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		if q == 0 {
+			continue
+		}
+
+		ranked = append(ranked, accepted{mediaType: mediaType, q: q})
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].q > ranked[j].q
+	})
+
+	mediaTypes := make([]string, len(ranked))
+	for i, a := range ranked {
+		mediaTypes[i] = a.mediaType
+	}
+	return mediaTypes
+}
+
+// renderEncoded writes the response with the negotiated encoder
+func renderEncoded(w http.ResponseWriter, e Encoder, status int, response interface{}, repos []models.Repository) {
+	w.Header().Set("Content-Type", e.ContentType())
+	w.WriteHeader(status)
+	if err := e.Encode(w, response, repos); err != nil {
+		log.Print("error encoding response: ", err)
+	}
+}
+
+type jsonEncoder struct{}
+
+func (jsonEncoder) ContentType() string {
+	return "application/json"
+}
+
+func (jsonEncoder) Encode(w io.Writer, response interface{}, _ []models.Repository) error {
+	return json.NewEncoder(w).Encode(response)
+}
+
+// ndjsonEncoder writes one repository per line
+// https://github.com/ndjson/ndjson-spec
+type ndjsonEncoder struct{}
+
+func (ndjsonEncoder) ContentType() string {
+	return "application/x-ndjson"
+}
+
+func (ndjsonEncoder) Encode(w io.Writer, _ interface{}, repos []models.Repository) error {
+	encoder := json.NewEncoder(w)
+	for _, repo := range repos {
+		if err := encoder.Encode(repo); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// csvEncoder writes one row per language of each repository
+type csvEncoder struct{}
+
+var csvHeader = []string{"owner", "full_name", "description", "language", "bytes", "share"}
+
+func (csvEncoder) ContentType() string {
+	return "text/csv"
+}
+
+func (csvEncoder) Encode(w io.Writer, _ interface{}, repos []models.Repository) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, repo := range repos {
+		if err := writer.WriteAll(csvRows(repo)); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// csvRows returns the rows of a repository, sorted by language so the output is stable
+func csvRows(repo models.Repository) [][]string {
+	if len(repo.Languages) == 0 {
+		return [][]string{{repo.Owner.Login, repo.FullName, repo.Description, "", "", ""}}
+	}
+
+	languages := make([]string, 0, len(repo.Languages))
+	for language := range repo.Languages {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	rows := make([][]string, 0, len(languages))
+	for _, language := range languages {
+		share := ""
+		if repo.LanguageStats != nil {
+			share = strconv.FormatFloat(repo.LanguageStats.Percentages[language], 'f', -1, 64)
+		}
+
+		rows = append(rows, []string{
+			repo.Owner.Login,
+			repo.FullName,
+			repo.Description,
+			language,
+			strconv.Itoa(repo.Languages[language]),
+			share,
+		})
+	}
+
+	return rows
+}
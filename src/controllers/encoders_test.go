@@ -0,0 +1,208 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+var encodedRepos = []models.Repository{
+	{
+		FullName:    "scalingo/scalingo-test",
+		Description: "test, with comma",
+		Owner:       models.Owner{Login: "scalingo"},
+		Languages:   models.Languages{"Go": 300, "Shell": 100},
+		LanguageStats: &models.LanguageStats{
+			Percentages: map[string]float64{"Go": 75, "Shell": 25},
+		},
+	},
+	{
+		FullName: "scalingo/empty",
+		Owner:    models.Owner{Login: "scalingo"},
+	},
+}
+
+func TestNegotiateEncoder(t *testing.T) {
+	tests := map[string]struct {
+		url        string
+		accept     string
+		wantFormat string
+		wantStatus int
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		"default to json": {
+			url:        "/repos",
+			wantFormat: "json",
+			wantErr:    assert.NoError,
+		},
+		"format parameter": {
+			url:        "/repos?format=csv",
+			accept:     "application/json",
+			wantFormat: "csv",
+			wantErr:    assert.NoError,
+		},
+		"accept header": {
+			url:        "/repos",
+			accept:     "application/x-ndjson",
+			wantFormat: "ndjson",
+			wantErr:    assert.NoError,
+		},
+		"first supported media type": {
+			url:        "/repos",
+			accept:     "application/xml, text/csv;charset=utf-8, application/json",
+			wantFormat: "csv",
+			wantErr:    assert.NoError,
+		},
+		"highest q value": {
+			url:        "/repos",
+			accept:     "text/csv;q=0.5, application/json",
+			wantFormat: "json",
+			wantErr:    assert.NoError,
+		},
+		"highest q value listed first": {
+			url:        "/repos",
+			accept:     "application/json;q=0.9, text/csv;q=0.5",
+			wantFormat: "json",
+			wantErr:    assert.NoError,
+		},
+		"equal q values, first listed": {
+			url:        "/repos",
+			accept:     "application/x-ndjson;q=0.8, application/json;q=0.8",
+			wantFormat: "ndjson",
+			wantErr:    assert.NoError,
+		},
+		"wildcard ranked below": {
+			url:        "/repos",
+			accept:     "*/*;q=0.1, text/csv",
+			wantFormat: "csv",
+			wantErr:    assert.NoError,
+		},
+		"q zero excluded, return error": {
+			url:        "/repos",
+			accept:     "application/json;q=0",
+			wantStatus: http.StatusNotAcceptable,
+			wantErr:    assert.Error,
+		},
+		"wildcard": {
+			url:        "/repos",
+			accept:     "*/*",
+			wantFormat: "json",
+			wantErr:    assert.NoError,
+		},
+		"unknown format parameter, return error": {
+			url:        "/repos?format=xml",
+			wantStatus: http.StatusBadRequest,
+			wantErr:    assert.Error,
+		},
+		"unsupported accept header, return error": {
+			url:        "/repos",
+			accept:     "application/xml",
+			wantStatus: http.StatusNotAcceptable,
+			wantErr:    assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+
+			format, _, status, err := negotiateEncoder(req)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.wantFormat, format)
+			assert.Equal(t, tt.wantStatus, status)
+		})
+	}
+}
+
+func TestEncoders(t *testing.T) {
+	tests := map[string]struct {
+		encoder     Encoder
+		contentType string
+		check       func(*testing.T, string)
+	}{
+		"json": {
+			encoder:     jsonEncoder{},
+			contentType: "application/json",
+			check: func(t *testing.T, body string) {
+				assert.Equal(t, `{"count":2}`+"\n", body)
+			},
+		},
+		"ndjson": {
+			encoder:     ndjsonEncoder{},
+			contentType: "application/x-ndjson",
+			check: func(t *testing.T, body string) {
+				lines := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+				assert.Len(t, lines, 2)
+
+				for i, line := range lines {
+					var repo models.Repository
+					assert.NoError(t, json.Unmarshal([]byte(line), &repo))
+					assert.Equal(t, encodedRepos[i].FullName, repo.FullName)
+				}
+			},
+		},
+		"csv": {
+			encoder:     csvEncoder{},
+			contentType: "text/csv",
+			check: func(t *testing.T, body string) {
+				assert.Equal(t, "owner,full_name,description,language,bytes,share\n"+
+					"scalingo,scalingo/scalingo-test,\"test, with comma\",Go,300,75\n"+
+					"scalingo,scalingo/scalingo-test,\"test, with comma\",Shell,100,25\n"+
+					"scalingo,scalingo/empty,,,,\n", body)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+			err := tt.encoder.Encode(&buf, map[string]int{"count": 2}, encodedRepos)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.contentType, tt.encoder.ContentType())
+			tt.check(t, buf.String())
+		})
+	}
+}
+
+type textEncoder struct{}
+
+func (textEncoder) ContentType() string {
+	return "text/plain"
+}
+
+func (textEncoder) Encode(w io.Writer, _ interface{}, repos []models.Repository) error {
+	for _, repo := range repos {
+		if _, err := io.WriteString(w, repo.FullName+"\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	RegisterEncoder("text", textEncoder{})
+	defer delete(encoders, "text")
+
+	req := httptest.NewRequest(http.MethodGet, "/repos", nil)
+	req.Header.Set("Accept", "text/plain")
+
+	format, encoder, _, err := negotiateEncoder(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "text", format)
+
+	w := httptest.NewRecorder()
+	renderEncoded(w, encoder, http.StatusOK, nil, encodedRepos)
+	assert.Equal(t, "text/plain", w.Header().Get("Content-Type"))
+	assert.Equal(t, "scalingo/scalingo-test\nscalingo/empty\n", w.Body.String())
+}
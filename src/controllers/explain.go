@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"net/http"
+)
+
+// ExplainQuery handles GET /repos/explain, it takes the parameters of /repos and describes the search without running it
+// GitHub is not called, so no Authorization header is needed.
+func (rc *RepositoryController) ExplainQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		renderError(w, http.StatusMethodNotAllowed, "method not allowed, use GET to explain a query")
+		return
+	}
+
+	in, err := rc.searchInputFromURL(r.URL.Query())
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params, err := rc.searchParams(in, "")
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = validateInclude(in.include, params)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	explanation, err := rc.ru.ExplainQuery(params)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	renderJSON(w, http.StatusOK, explanation)
+}
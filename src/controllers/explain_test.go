@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainQueryEndpoint(t *testing.T) {
+	tests := map[string]struct {
+		method         string
+		url            string
+		mockCall       func(*mockRepositoryUseCase)
+		expectedStatus int
+	}{
+		"nominal, without Authorization header": {
+			method: http.MethodGet,
+			url:    "/repos/explain?q=tetris+language:go&per_page=10",
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ValidateQuery", "tetris language:go").Return("go", nil)
+				m.On("ExplainQuery", &models.RepositorySearchParams{
+					Query:         "tetris language:go",
+					PerPage:       "10",
+					Page:          "1",
+					Language:      "go",
+					LanguagesMode: models.LanguagesModeRequested,
+					Order:         models.OrderDesc,
+				}).Return(&models.QueryExplanation{Query: "tetris language:go"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"invalid query, return error": {
+			method: http.MethodGet,
+			url:    "/repos/explain?q=tetris",
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ValidateQuery", "tetris").Return("", errors.New("no language filter set"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"invalid cursor, return error": {
+			method: http.MethodGet,
+			url:    "/repos/explain?cursor=forged",
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ResolveCursor", "forged").Return((*models.RepositorySearchParams)(nil), errors.New("invalid cursor"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"wrong method, return error": {
+			method:         http.MethodPost,
+			url:            "/repos/explain?q=language:go",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			w := httptest.NewRecorder()
+
+			m := new(mockRepositoryUseCase)
+			if tt.mockCall != nil {
+				tt.mockCall(m)
+			}
+
+			NewRepositoryController(m).ExplainQuery(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			m.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/usecases"
+)
+
+type ExportController struct {
+	ru usecases.RepositoryUseCase
+	eu usecases.ExportUseCase
+}
+
+func NewExportController(ru usecases.RepositoryUseCase, eu usecases.ExportUseCase) *ExportController {
+	return &ExportController{
+		ru: ru,
+		eu: eu,
+	}
+}
+
+// CreateExport handles POST /exports, q is read from the query string or from a form body
+func (ec *ExportController) CreateExport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		renderError(w, http.StatusMethodNotAllowed, "method not allowed, use POST to create an export")
+		return
+	}
+
+	header := r.Header.Get("Authorization")
+	err := validateHeader(&header)
+	if err != nil {
+		renderError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	query := r.FormValue("q")
+	language, err := ec.ru.ValidateQuery(query)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Exports only fetch the languages, dependencies are unknown
+	if hasQualifier(query, models.QualifierDependsOn) {
+		renderError(w, http.StatusBadRequest, fmt.Sprintf("%s cannot be used in exports", models.QualifierDependsOn))
+		return
+	}
+
+	languagesMode := r.FormValue("languages")
+	err = validateLanguagesMode(&languagesMode)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	job, err := ec.eu.CreateExport(&models.RepositorySearchParams{
+		Query:         query,
+		Header:        header,
+		Language:      language,
+		LanguagesMode: languagesMode,
+	})
+	if err != nil {
+		renderError(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	w.Header().Set("Location", "/exports/"+job.ID)
+	renderJSON(w, http.StatusAccepted, job)
+}
+
+// GetExport handles GET /exports/{id} for the status of a job and GET /exports/{id}/result for its result
+// POST /exports/{id}/resume is routed to resumeExport.
+func (ec *ExportController) GetExport(w http.ResponseWriter, r *http.Request) {
+	id, resource, err := parseExportPath(r.URL.Path)
+	if err != nil {
+		renderError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	if resource == "resume" {
+		ec.resumeExport(w, r, id)
+		return
+	}
+
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		renderError(w, http.StatusMethodNotAllowed, "method not allowed, use GET to read an export")
+		return
+	}
+
+	if resource == "" {
+		job, err := ec.eu.GetExport(id)
+		if err != nil {
+			renderError(w, exportErrorStatus(err), err.Error())
+			return
+		}
+		renderJSON(w, http.StatusOK, job)
+		return
+	}
+
+	format, encoder, status, err := negotiateEncoder(r)
+	if err != nil {
+		renderError(w, status, err.Error())
+		return
+	}
+
+	result, err := ec.eu.GetExportResult(id)
+	if err != nil {
+		renderError(w, exportErrorStatus(err), err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="export-%s.%s"`, id, format))
+	renderEncoded(w, encoder, http.StatusOK, result, result.Items)
+}
+
+// resumeExport handles POST /exports/{id}/resume, the job interrupted by a restart goes on with the token of the request
+func (ec *ExportController) resumeExport(w http.ResponseWriter, r *http.Request, id string) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		renderError(w, http.StatusMethodNotAllowed, "method not allowed, use POST to resume an export")
+		return
+	}
+
+	header := r.Header.Get("Authorization")
+	err := validateHeader(&header)
+	if err != nil {
+		renderError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	job, err := ec.eu.ResumeExport(id, header)
+	if err != nil {
+		renderError(w, exportErrorStatus(err), err.Error())
+		return
+	}
+
+	w.Header().Set("Location", "/exports/"+job.ID)
+	renderJSON(w, http.StatusAccepted, job)
+}
+
+// parseExportPath splits /exports/{id}, /exports/{id}/result and /exports/{id}/resume
+func parseExportPath(path string) (id, resource string, err error) {
+	parts := strings.Split(strings.TrimPrefix(path, "/exports/"), "/")
+
+	switch {
+	case len(parts) == 1 && parts[0] != "":
+		return parts[0], "", nil
+	case len(parts) == 2 && parts[0] != "" && (parts[1] == "result" || parts[1] == "resume"):
+		return parts[0], parts[1], nil
+	default:
+		return "", "", fmt.Errorf("unknown export resource %s", path)
+	}
+}
+
+func exportErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, usecases.ErrExportNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecases.ErrExportNotDone), errors.Is(err, usecases.ErrExportNotInterrupted):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,274 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/usecases"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockExportUseCase struct {
+	mock.Mock
+}
+
+func (m *mockExportUseCase) CreateExport(rsp *models.RepositorySearchParams) (*models.ExportJob, error) {
+	args := m.Called(rsp)
+	return args.Get(0).(*models.ExportJob), args.Error(1)
+}
+
+func (m *mockExportUseCase) GetExport(id string) (*models.ExportJob, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.ExportJob), args.Error(1)
+}
+
+func (m *mockExportUseCase) GetExportResult(id string) (*models.ExportResult, error) {
+	args := m.Called(id)
+	return args.Get(0).(*models.ExportResult), args.Error(1)
+}
+
+func (m *mockExportUseCase) ResumeExports() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *mockExportUseCase) ResumeExport(id, header string) (*models.ExportJob, error) {
+	args := m.Called(id, header)
+	return args.Get(0).(*models.ExportJob), args.Error(1)
+}
+
+func TestCreateExportEndpoint(t *testing.T) {
+	header := "Bearer tokentoken"
+
+	tests := map[string]struct {
+		method         string
+		url            string
+		header         string
+		mockCall       func(*mockRepositoryUseCase, *mockExportUseCase)
+		expectedStatus int
+	}{
+		"nominal": {
+			method: http.MethodPost,
+			url:    "/exports?q=language:go+stars:>50",
+			header: header,
+			mockCall: func(ru *mockRepositoryUseCase, eu *mockExportUseCase) {
+				ru.On("ValidateQuery", "language:go stars:>50").Return("go", nil)
+				eu.On("CreateExport", &models.RepositorySearchParams{
+					Query:         "language:go stars:>50",
+					Header:        header,
+					Language:      "go",
+					LanguagesMode: models.LanguagesModeRequested,
+				}).Return(&models.ExportJob{ID: "abc", Status: models.ExportStatusPending}, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		"wrong method, return error": {
+			method:         http.MethodGet,
+			url:            "/exports?q=language:go",
+			header:         header,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		"missing header, return error": {
+			method:         http.MethodPost,
+			url:            "/exports?q=language:go",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"invalid query, return error": {
+			method: http.MethodPost,
+			url:    "/exports?q=stars:>50",
+			header: header,
+			mockCall: func(ru *mockRepositoryUseCase, eu *mockExportUseCase) {
+				ru.On("ValidateQuery", "stars:>50").Return("", errors.New("no language filter set"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"usecase error, return error": {
+			method: http.MethodPost,
+			url:    "/exports?q=language:go",
+			header: header,
+			mockCall: func(ru *mockRepositoryUseCase, eu *mockExportUseCase) {
+				ru.On("ValidateQuery", "language:go").Return("go", nil)
+				eu.On("CreateExport", mock.Anything).Return((*models.ExportJob)(nil), errors.New("disk full"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			if tt.header != "" {
+				req.Header.Add("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			ru := new(mockRepositoryUseCase)
+			eu := new(mockExportUseCase)
+			if tt.mockCall != nil {
+				tt.mockCall(ru, eu)
+			}
+
+			NewExportController(ru, eu).CreateExport(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			if tt.expectedStatus == http.StatusAccepted {
+				assert.Equal(t, "/exports/abc", w.Header().Get("Location"))
+			}
+			ru.AssertExpectations(t)
+			eu.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetExportEndpoint(t *testing.T) {
+	tests := map[string]struct {
+		method         string
+		url            string
+		header         string
+		mockCall       func(*mockExportUseCase)
+		expectedStatus int
+	}{
+		"job status": {
+			method: http.MethodGet,
+			url:    "/exports/abc",
+			mockCall: func(m *mockExportUseCase) {
+				m.On("GetExport", "abc").Return(&models.ExportJob{ID: "abc", Status: models.ExportStatusRunning}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"unknown job, return error": {
+			method: http.MethodGet,
+			url:    "/exports/abc",
+			mockCall: func(m *mockExportUseCase) {
+				m.On("GetExport", "abc").Return((*models.ExportJob)(nil), usecases.ErrExportNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		"job result": {
+			method: http.MethodGet,
+			url:    "/exports/abc/result",
+			mockCall: func(m *mockExportUseCase) {
+				m.On("GetExportResult", "abc").Return(&models.ExportResult{Query: "language:go"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"job result not done, return error": {
+			method: http.MethodGet,
+			url:    "/exports/abc/result",
+			mockCall: func(m *mockExportUseCase) {
+				m.On("GetExportResult", "abc").Return((*models.ExportResult)(nil), usecases.ErrExportNotDone)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		"resume": {
+			method: http.MethodPost,
+			url:    "/exports/abc/resume",
+			header: "Bearer tokentoken",
+			mockCall: func(m *mockExportUseCase) {
+				m.On("ResumeExport", "abc", "Bearer tokentoken").Return(&models.ExportJob{ID: "abc", Status: models.ExportStatusPending}, nil)
+			},
+			expectedStatus: http.StatusAccepted,
+		},
+		"resume without header, return error": {
+			method:         http.MethodPost,
+			url:            "/exports/abc/resume",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"resume not interrupted, return error": {
+			method: http.MethodPost,
+			url:    "/exports/abc/resume",
+			header: "Bearer tokentoken",
+			mockCall: func(m *mockExportUseCase) {
+				m.On("ResumeExport", "abc", "Bearer tokentoken").Return((*models.ExportJob)(nil), usecases.ErrExportNotInterrupted)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		"resume wrong method, return error": {
+			method:         http.MethodGet,
+			url:            "/exports/abc/resume",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		"unknown resource, return error": {
+			method:         http.MethodGet,
+			url:            "/exports/abc/logs",
+			expectedStatus: http.StatusNotFound,
+		},
+		"wrong method, return error": {
+			method:         http.MethodDelete,
+			url:            "/exports/abc",
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			if tt.header != "" {
+				req.Header.Add("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			eu := new(mockExportUseCase)
+			if tt.mockCall != nil {
+				tt.mockCall(eu)
+			}
+
+			NewExportController(new(mockRepositoryUseCase), eu).GetExport(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			eu.AssertExpectations(t)
+		})
+	}
+}
+
+func TestParseExportPath(t *testing.T) {
+	tests := map[string]struct {
+		path         string
+		wantID       string
+		wantResource string
+		wantErr      assert.ErrorAssertionFunc
+	}{
+		"job": {
+			path:    "/exports/abc",
+			wantID:  "abc",
+			wantErr: assert.NoError,
+		},
+		"result": {
+			path:         "/exports/abc/result",
+			wantID:       "abc",
+			wantResource: "result",
+			wantErr:      assert.NoError,
+		},
+		"missing id": {
+			path:    "/exports/",
+			wantErr: assert.Error,
+		},
+		"resume": {
+			path:         "/exports/abc/resume",
+			wantID:       "abc",
+			wantResource: "resume",
+			wantErr:      assert.NoError,
+		},
+		"unknown resource": {
+			path:    "/exports/abc/other",
+			wantErr: assert.Error,
+		},
+		"too deep": {
+			path:    "/exports/abc/result/more",
+			wantErr: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			id, resource, err := parseExportPath(tt.path)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.wantID, id)
+			assert.Equal(t, tt.wantResource, resource)
+		})
+	}
+}
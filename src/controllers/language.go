@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/usecases"
+)
+
+type LanguageController struct {
+	lu usecases.LanguageUseCase
+}
+
+func NewLanguageController(lu usecases.LanguageUseCase) *LanguageController {
+	return &LanguageController{
+		lu: lu,
+	}
+}
+
+// LanguageShare handles GET /languages/share, it compares the number of repositories of comma separated languages
+func (lc *LanguageController) LanguageShare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		renderError(w, http.StatusMethodNotAllowed, "method not allowed, use GET to compare languages")
+		return
+	}
+
+	header := r.Header.Get("Authorization")
+	err := validateHeader(&header)
+	if err != nil {
+		renderError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	values := r.URL.Query()
+	languages := values.Get("languages")
+	if languages == "" {
+		renderError(w, http.StatusBadRequest, "languages cannot be empty, give comma separated languages")
+		return
+	}
+
+	share, err := lc.lu.LanguageShare(&models.LanguageShareParams{
+		Languages: strings.Split(languages, ","),
+		Query:     values.Get("q"),
+		Header:    header,
+	})
+	if err != nil {
+		renderError(w, languageErrorStatus(err), err.Error())
+		return
+	}
+
+	renderJSON(w, http.StatusOK, share)
+}
+
+// LanguageTimeseries handles GET /languages/{language}/timeseries, the monthly counts of repositories of a language
+// A series interrupted by the rate limit is still returned, with complete set to false.
+func (lc *LanguageController) LanguageTimeseries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		renderError(w, http.StatusMethodNotAllowed, "method not allowed, use GET to read a time series")
+		return
+	}
+
+	language, err := parseLanguagePath(r.URL.Path)
+	if err != nil {
+		renderError(w, http.StatusNotFound, err.Error())
+		return
+	}
+
+	header := r.Header.Get("Authorization")
+	err = validateHeader(&header)
+	if err != nil {
+		renderError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	values := r.URL.Query()
+	series, err := lc.lu.LanguageTimeseries(&models.TimeseriesParams{
+		Language: language,
+		From:     values.Get("from"),
+		To:       values.Get("to"),
+		By:       values.Get("by"),
+		Query:    values.Get("q"),
+		Header:   header,
+	})
+	if err != nil {
+		renderError(w, languageErrorStatus(err), err.Error())
+		return
+	}
+
+	renderJSON(w, http.StatusOK, series)
+}
+
+// parseLanguagePath reads the language of /languages/{language}/timeseries
+func parseLanguagePath(path string) (string, error) {
+	parts := strings.Split(strings.TrimPrefix(path, "/languages/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] != "timeseries" {
+		return "", fmt.Errorf("unknown language resource %s", path)
+	}
+	return parts[0], nil
+}
+
+// languageErrorStatus is the status of a failed count, a rate limited token may retry later
+func languageErrorStatus(err error) int {
+	if errors.Is(err, usecases.ErrRateLimited) {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusBadRequest
+}
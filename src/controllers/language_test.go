@@ -0,0 +1,195 @@
+package controllers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/usecases"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockLanguageUseCase struct {
+	mock.Mock
+}
+
+func (m *mockLanguageUseCase) LanguageShare(params *models.LanguageShareParams) (*models.LanguageShare, error) {
+	args := m.Called(params)
+	return args.Get(0).(*models.LanguageShare), args.Error(1)
+}
+
+func (m *mockLanguageUseCase) LanguageTimeseries(params *models.TimeseriesParams) (*models.LanguageTimeseries, error) {
+	args := m.Called(params)
+	return args.Get(0).(*models.LanguageTimeseries), args.Error(1)
+}
+
+func TestLanguageShareEndpoint(t *testing.T) {
+	header := "Bearer tokentoken"
+
+	tests := map[string]struct {
+		method         string
+		url            string
+		header         string
+		mockCall       func(*mockLanguageUseCase)
+		expectedStatus int
+	}{
+		"nominal": {
+			method: http.MethodGet,
+			url:    "/languages/share?languages=go,rust&q=created:2024-01-01..2024-12-31",
+			header: header,
+			mockCall: func(m *mockLanguageUseCase) {
+				m.On("LanguageShare", &models.LanguageShareParams{
+					Languages: []string{"go", "rust"},
+					Query:     "created:2024-01-01..2024-12-31",
+					Header:    header,
+				}).Return(&models.LanguageShare{Total: 10}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"use case error, return error": {
+			method: http.MethodGet,
+			url:    "/languages/share?languages=go",
+			header: header,
+			mockCall: func(m *mockLanguageUseCase) {
+				m.On("LanguageShare", mock.Anything).Return((*models.LanguageShare)(nil), errors.New("rate limit exceeded"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"rate limited, return error": {
+			method: http.MethodGet,
+			url:    "/languages/share?languages=go",
+			header: header,
+			mockCall: func(m *mockLanguageUseCase) {
+				m.On("LanguageShare", mock.Anything).Return((*models.LanguageShare)(nil), fmt.Errorf("error counting go repositories: %w", usecases.ErrRateLimited))
+			},
+			expectedStatus: http.StatusTooManyRequests,
+		},
+		"no languages, return error": {
+			method:         http.MethodGet,
+			url:            "/languages/share?q=stars:>10",
+			header:         header,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"no Authorization header, return error": {
+			method:         http.MethodGet,
+			url:            "/languages/share?languages=go",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"wrong method, return error": {
+			method:         http.MethodPost,
+			url:            "/languages/share?languages=go",
+			header:         header,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			if tt.header != "" {
+				req.Header.Add("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			m := new(mockLanguageUseCase)
+			if tt.mockCall != nil {
+				tt.mockCall(m)
+			}
+
+			NewLanguageController(m).LanguageShare(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLanguageTimeseriesEndpoint(t *testing.T) {
+	header := "Bearer tokentoken"
+
+	tests := map[string]struct {
+		method         string
+		url            string
+		header         string
+		mockCall       func(*mockLanguageUseCase)
+		expectedStatus int
+	}{
+		"nominal": {
+			method: http.MethodGet,
+			url:    "/languages/go/timeseries?from=2020-01&to=2024-12&by=pushed&q=stars:>10",
+			header: header,
+			mockCall: func(m *mockLanguageUseCase) {
+				m.On("LanguageTimeseries", &models.TimeseriesParams{
+					Language: "go",
+					From:     "2020-01",
+					To:       "2024-12",
+					By:       "pushed",
+					Query:    "stars:>10",
+					Header:   header,
+				}).Return(&models.LanguageTimeseries{Language: "go", Complete: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"escaped language": {
+			method: http.MethodGet,
+			url:    "/languages/c%23/timeseries?from=2020-01&to=2020-02",
+			header: header,
+			mockCall: func(m *mockLanguageUseCase) {
+				m.On("LanguageTimeseries", mock.MatchedBy(func(params *models.TimeseriesParams) bool {
+					return params.Language == "c#"
+				})).Return(&models.LanguageTimeseries{Language: "c#", Complete: true}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"invalid period, return error": {
+			method: http.MethodGet,
+			url:    "/languages/go/timeseries?from=2020",
+			header: header,
+			mockCall: func(m *mockLanguageUseCase) {
+				m.On("LanguageTimeseries", mock.Anything).Return((*models.LanguageTimeseries)(nil), errors.New("from must be a month in YYYY-MM format"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"unknown resource, return error": {
+			method:         http.MethodGet,
+			url:            "/languages/go/share",
+			header:         header,
+			expectedStatus: http.StatusNotFound,
+		},
+		"no Authorization header, return error": {
+			method:         http.MethodGet,
+			url:            "/languages/go/timeseries?from=2020-01&to=2020-02",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"wrong method, return error": {
+			method:         http.MethodDelete,
+			url:            "/languages/go/timeseries",
+			header:         header,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			if tt.header != "" {
+				req.Header.Add("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			m := new(mockLanguageUseCase)
+			if tt.mockCall != nil {
+				tt.mockCall(m)
+			}
+
+			NewLanguageController(m).LanguageTimeseries(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			m.AssertExpectations(t)
+		})
+	}
+}
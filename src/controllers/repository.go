@@ -2,8 +2,10 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -33,6 +35,12 @@ func renderError(w http.ResponseWriter, status int, message string) {
 	})
 }
 
+func renderJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
 func (rc *RepositoryController) SearchRepositories(w http.ResponseWriter, r *http.Request) {
 	header := r.Header.Get("Authorization")
 	err := validateHeader(&header)
@@ -41,39 +49,238 @@ func (rc *RepositoryController) SearchRepositories(w http.ResponseWriter, r *htt
 		return
 	}
 
-	query := r.URL.Query().Get("q")
-	language, err := rc.ru.ValidateQuery(query)
+	stream, err := validateStream(r)
 	if err != nil {
 		renderError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	perPage := r.URL.Query().Get("per_page")
-	page := r.URL.Query().Get("page")
+	// Streams have their own formats, the encoder is only negotiated for regular responses
+	var encoder Encoder
+	if stream == nil {
+		var status int
+		_, encoder, status, err = negotiateEncoder(r)
+		if err != nil {
+			renderError(w, status, err.Error())
+			return
+		}
+	}
 
-	err = validatePagination(&perPage, &page)
+	in, err := rc.searchInputFromURL(r.URL.Query())
 	if err != nil {
 		renderError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	params := models.RepositorySearchParams{
-		Query:    query,
-		PerPage:  perPage,
-		Page:     page,
-		Header:   header,
-		Language: language,
+	params, err := rc.searchParams(in, header)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
 	}
+	params.Context = r.Context()
 
-	repos, err := rc.ru.SearchRepositories(&params)
+	// The budget belongs to the request, it is read even when a cursor replaces the search parameters
+	values := r.URL.Query()
+	err = validateBudget(values.Get("max_github_calls"), values.Get("on_budget"), values.Get("dry_run"), params)
 	if err != nil {
 		renderError(w, http.StatusBadRequest, err.Error())
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(repos)
+	err = validateFacets(values.Get("facets"), values.Get("facet_pages"), params)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = validateInclude(in.include, params)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params.Highlight, err = validateHighlight(in.highlight)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	fields, err := validateFields(values.Get("fields"))
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if stream != nil {
+		if params.MaxGitHubCalls != 0 || params.DryRun {
+			renderError(w, http.StatusBadRequest, "max_github_calls and dry_run cannot be used when streaming")
+			return
+		}
+
+		if len(params.Facets) > 0 {
+			renderError(w, http.StatusBadRequest, "facets cannot be used when streaming")
+			return
+		}
+
+		rc.streamRepositories(w, r.URL.Path, stream, params, fields)
+		return
+	}
+
+	repos, err := rc.ru.SearchRepositories(params)
+	if err != nil {
+		renderError(w, searchErrorStatus(err), err.Error())
+		return
+	}
+
+	setPageLinks(w, r.URL.Path, repos)
+	selectFields(repos.Items, fields)
+
+	renderEncoded(w, encoder, http.StatusOK, repos, repos.Items)
+}
+
+// searchInput holds the raw search parameters, whether they come from the URL, a cursor or a JSON body
+type searchInput struct {
+	query         string
+	perPage       string
+	page          string
+	languagesMode string
+	sort          string
+	order         string
+	fill          string
+	include       string
+	highlight     string
+	cursor        string
+}
+
+// searchInputFromURL reads the search parameters of the query string
+// A cursor records a whole search, it replaces the other parameters.
+func (rc *RepositoryController) searchInputFromURL(values url.Values) (searchInput, error) {
+	c := values.Get("cursor")
+	if c == "" {
+		return searchInput{
+			query:         values.Get("q"),
+			perPage:       values.Get("per_page"),
+			page:          values.Get("page"),
+			languagesMode: values.Get("languages"),
+			sort:          values.Get("sort"),
+			order:         values.Get("order"),
+			fill:          values.Get("fill"),
+			include:       values.Get("include"),
+			highlight:     values.Get("highlight"),
+		}, nil
+	}
+
+	resolved, err := rc.ru.ResolveCursor(c)
+	if err != nil {
+		return searchInput{}, err
+	}
+
+	return searchInput{
+		query:         resolved.Query,
+		perPage:       resolved.PerPage,
+		page:          resolved.Page,
+		languagesMode: resolved.LanguagesMode,
+		sort:          resolved.Sort,
+		order:         resolved.Order,
+		fill:          strconv.FormatBool(resolved.Fill),
+		include:       strings.Join(resolved.Include, ","),
+		highlight:     strconv.FormatBool(resolved.Highlight),
+		cursor:        resolved.Cursor,
+	}, nil
+}
+
+// searchParams validates the raw search parameters and applies their defaults
+func (rc *RepositoryController) searchParams(in searchInput, header string) (*models.RepositorySearchParams, error) {
+	language, err := rc.ru.ValidateQuery(in.query)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validatePagination(&in.perPage, &in.page); err != nil {
+		return nil, err
+	}
+
+	if err := validateLanguagesMode(&in.languagesMode); err != nil {
+		return nil, err
+	}
+
+	if err := validateSort(&in.sort, &in.order); err != nil {
+		return nil, err
+	}
+
+	fill, err := validateFill(in.fill)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.RepositorySearchParams{
+		Query:         in.query,
+		PerPage:       in.perPage,
+		Page:          in.page,
+		Header:        header,
+		Language:      language,
+		LanguagesMode: in.languagesMode,
+		Sort:          in.sort,
+		Order:         in.order,
+		Fill:          fill,
+		Cursor:        in.cursor,
+	}, nil
+}
+
+// setPageLinks turns the cursors of the response into URLs, in the body and in a Link header
+func setPageLinks(w http.ResponseWriter, path string, repos *models.RepositorySearchResponse) {
+	repos.Links = pageLinks(path, repos.Cursors)
+	setLinkHeader(w, repos.Links)
+}
+
+// pageLinks turns navigation cursors into URLs of the given path
+func pageLinks(path string, cursors *models.PageLinks) *models.PageLinks {
+	if cursors == nil {
+		return nil
+	}
+
+	link := func(cursor string) string {
+		if cursor == "" {
+			return ""
+		}
+		return path + "?cursor=" + url.QueryEscape(cursor)
+	}
+
+	return &models.PageLinks{
+		First: link(cursors.First),
+		Prev:  link(cursors.Prev),
+		Next:  link(cursors.Next),
+		Last:  link(cursors.Last),
+	}
+}
+
+// setLinkHeader writes the navigation links in a Link header
+// https://www.rfc-editor.org/rfc/rfc8288
+func setLinkHeader(w http.ResponseWriter, pageLinks *models.PageLinks) {
+	if pageLinks == nil {
+		return
+	}
+
+	relations := []struct {
+		rel    string
+		target string
+	}{
+		{rel: "first", target: pageLinks.First},
+		{rel: "prev", target: pageLinks.Prev},
+		{rel: "next", target: pageLinks.Next},
+		{rel: "last", target: pageLinks.Last},
+	}
+
+	links := make([]string, 0, len(relations))
+	for _, relation := range relations {
+		if relation.target != "" {
+			links = append(links, fmt.Sprintf(`<%s>; rel="%s"`, relation.target, relation.rel))
+		}
+	}
+
+	if len(links) > 0 {
+		w.Header().Set("Link", strings.Join(links, ", "))
+	}
 }
 
 func validatePagination(perPage, page *string) error {
@@ -82,8 +289,8 @@ func validatePagination(perPage, page *string) error {
 	}
 
 	pp, err := strconv.Atoi(*perPage)
-	if err != nil || pp < 0 || pp > 100 {
-		return fmt.Errorf("per_page must be a number between 0 and 100")
+	if err != nil || pp < 1 || pp > 100 {
+		return fmt.Errorf("per_page must be a number between 1 and 100")
 	}
 
 	if *page == "" {
@@ -95,9 +302,244 @@ func validatePagination(perPage, page *string) error {
 		return fmt.Errorf("page must be a positive number")
 	}
 
+	if (p-1)*pp >= models.SearchResultsLimit {
+		return fmt.Errorf("page %d with per_page %d is beyond the first %d results, the only ones GitHub search can return", p, pp, models.SearchResultsLimit)
+	}
+
+	return nil
+}
+
+func validateLanguagesMode(mode *string) error {
+	switch *mode {
+	case "":
+		*mode = models.LanguagesModeRequested
+	case models.LanguagesModeRequested, models.LanguagesModeAll:
+	default:
+		return fmt.Errorf("languages must be either '%s' or '%s'", models.LanguagesModeAll, models.LanguagesModeRequested)
+	}
+
 	return nil
 }
 
+// validateSort accepts both GitHub sort keys and the ones we compute locally
+func validateSort(sort, order *string) error {
+	if *sort != "" && !models.GitHubSorts[*sort] && !models.LocalSorts[*sort] {
+		return fmt.Errorf("sort must be one of stars, forks, help-wanted-issues, updated, %s, %s or %s", models.SortLanguageBytes, models.SortLanguageShare, models.SortActivity)
+	}
+
+	switch *order {
+	case "":
+		*order = models.OrderDesc
+	case models.OrderAsc, models.OrderDesc:
+	default:
+		return fmt.Errorf("order must be either '%s' or '%s'", models.OrderAsc, models.OrderDesc)
+	}
+
+	return nil
+}
+
+func validateFill(fill string) (bool, error) {
+	if fill == "" {
+		return false, nil
+	}
+
+	f, err := strconv.ParseBool(fill)
+	if err != nil {
+		return false, fmt.Errorf("fill must be a boolean")
+	}
+
+	return f, nil
+}
+
+// validateHighlight reads whether the fragments matching the search terms are returned
+func validateHighlight(highlight string) (bool, error) {
+	if highlight == "" {
+		return false, nil
+	}
+
+	h, err := strconv.ParseBool(highlight)
+	if err != nil {
+		return false, fmt.Errorf("highlight must be a boolean")
+	}
+
+	return h, nil
+}
+
+// validateBudget reads the budget of GitHub calls of a request into its parameters
+func validateBudget(maxCalls, onBudget, dryRun string, params *models.RepositorySearchParams) error {
+	if maxCalls != "" {
+		calls, err := strconv.Atoi(maxCalls)
+		if err != nil || calls < 1 {
+			return fmt.Errorf("max_github_calls must be a positive number")
+		}
+		params.MaxGitHubCalls = calls
+	}
+
+	// Without on_budget, requests beyond their budget are refused
+	switch onBudget {
+	case "", models.BudgetRefuse, models.BudgetDegrade:
+	default:
+		return fmt.Errorf("on_budget must be either '%s' or '%s'", models.BudgetRefuse, models.BudgetDegrade)
+	}
+	params.OnBudget = onBudget
+
+	if dryRun != "" {
+		d, err := strconv.ParseBool(dryRun)
+		if err != nil {
+			return fmt.Errorf("dry_run must be a boolean")
+		}
+		params.DryRun = d
+	}
+
+	return nil
+}
+
+// validateFacets reads the comma separated facets of a request and the number of pages they are computed over
+func validateFacets(facets, facetPages string, params *models.RepositorySearchParams) error {
+	if facets == "" {
+		if facetPages != "" {
+			return fmt.Errorf("facet_pages cannot be used without facets")
+		}
+		return nil
+	}
+
+	names, err := facetNames(strings.Split(facets, ","))
+	if err != nil {
+		return err
+	}
+	params.Facets = names
+
+	params.FacetPages = 1
+	if facetPages != "" {
+		pages, err := strconv.Atoi(facetPages)
+		if err != nil || pages < 1 || pages > models.MaxFacetPages {
+			return fmt.Errorf("facet_pages must be a number between 1 and %d", models.MaxFacetPages)
+		}
+		if pages > 1 && params.Fill {
+			return fmt.Errorf("facet_pages cannot be used with fill")
+		}
+		params.FacetPages = pages
+	}
+
+	return nil
+}
+
+// facetNames verifies facet names and expands FacetAll, duplicates are dropped
+func facetNames(requested []string) ([]string, error) {
+	known := make(map[string]bool, len(models.FacetNames))
+	for _, name := range models.FacetNames {
+		known[name] = true
+	}
+
+	seen := make(map[string]bool, len(requested))
+	names := make([]string, 0, len(requested))
+	for _, name := range requested {
+		name = strings.TrimSpace(name)
+		if name == models.FacetAll {
+			return models.FacetNames, nil
+		}
+		if !known[name] {
+			return nil, fmt.Errorf("unknown facet %q, facets must be among %s or %s", name, strings.Join(models.FacetNames, ", "), models.FacetAll)
+		}
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	return names, nil
+}
+
+// validateInclude reads the comma separated enrichments of a request, duplicates are dropped
+// Without include, only the languages are fetched. Local sorts and qualifiers need the enrichment they are computed from.
+func validateInclude(include string, params *models.RepositorySearchParams) error {
+	seen := make(map[string]bool)
+	if include == "" {
+		for _, name := range models.DefaultIncludes {
+			seen[name] = true
+		}
+	} else {
+		known := make(map[string]bool, len(models.IncludeNames))
+		for _, name := range models.IncludeNames {
+			known[name] = true
+		}
+
+		names := make([]string, 0, strings.Count(include, ",")+1)
+		for _, name := range strings.Split(include, ",") {
+			name = strings.TrimSpace(name)
+			if !known[name] {
+				return fmt.Errorf("unknown include %q, include must be among %s", name, strings.Join(models.IncludeNames, ", "))
+			}
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		params.Include = names
+	}
+
+	if needed, ok := models.LocalSortIncludes[params.Sort]; ok && !seen[needed] {
+		return fmt.Errorf("sort %s needs %s to be included", params.Sort, needed)
+	}
+
+	if hasQualifier(params.Query, models.QualifierDependsOn) && !seen[models.IncludeSBOM] && !seen[models.IncludeManifests] {
+		return fmt.Errorf("%s needs %s or %s to be included", models.QualifierDependsOn, models.IncludeSBOM, models.IncludeManifests)
+	}
+
+	return nil
+}
+
+// hasQualifier tells whether the query uses the qualifier
+func hasQualifier(query, qualifier string) bool {
+	for _, part := range strings.Fields(query) {
+		if strings.HasPrefix(part, qualifier+":") {
+			return true
+		}
+	}
+	return false
+}
+
+// validateFields reads the comma separated fields kept in each repository, nil keeps all of them
+func validateFields(fields string) ([]string, error) {
+	if fields == "" {
+		return nil, nil
+	}
+
+	known := make(map[string]bool, len(models.RepositoryFields))
+	for _, name := range models.RepositoryFields {
+		known[name] = true
+	}
+
+	selected := make([]string, 0, strings.Count(fields, ",")+1)
+	for _, name := range strings.Split(fields, ",") {
+		name = strings.TrimSpace(name)
+		if !known[name] {
+			return nil, fmt.Errorf("unknown field %q, fields must be among %s", name, strings.Join(models.RepositoryFields, ", "))
+		}
+		selected = append(selected, name)
+	}
+
+	return selected, nil
+}
+
+// selectFields keeps only the selected fields when repositories are encoded as JSON
+func selectFields(repos []models.Repository, fields []string) {
+	if len(fields) == 0 {
+		return
+	}
+	for i := range repos {
+		repos[i] = repos[i].SelectFields(fields)
+	}
+}
+
+// searchErrorStatus is the status of a failed search, running out of budget is not a malformed request
+func searchErrorStatus(err error) int {
+	if errors.Is(err, usecases.ErrBudgetExceeded) {
+		return http.StatusUnprocessableEntity
+	}
+	return http.StatusBadRequest
+}
+
 func validateHeader(h *string) error {
 	if h == nil || *h == "" {
 		return fmt.Errorf("missing Authorization header")
@@ -1,12 +1,16 @@
 package controllers
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/usecases"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -20,6 +24,53 @@ func (m *mockRepositoryUseCase) SearchRepositories(rsp *models.RepositorySearchP
 	return args.Get(0).(*models.RepositorySearchResponse), args.Error(1)
 }
 
+func (m *mockRepositoryUseCase) ResolveCursor(cursor string) (*models.RepositorySearchParams, error) {
+	args := m.Called(cursor)
+	return args.Get(0).(*models.RepositorySearchParams), args.Error(1)
+}
+
+func (m *mockRepositoryUseCase) ExportRepositories(rsp *models.RepositorySearchParams, checkpoint *models.ExportCheckpoint, onCheckpoint usecases.ExportCheckpointFunc) (*models.ExportResult, error) {
+	args := m.Called(rsp, checkpoint)
+	return args.Get(0).(*models.ExportResult), args.Error(1)
+}
+
+func (m *mockRepositoryUseCase) StreamRepositories(rsp *models.RepositorySearchParams, emit usecases.StreamFunc) error {
+	args := m.Called(rsp)
+	if events, ok := args.Get(0).([]streamedEvent); ok {
+		for _, e := range events {
+			if err := emit(e.event, e.data); err != nil {
+				return err
+			}
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *mockRepositoryUseCase) CompileQuery(search *models.StructuredSearch) (string, error) {
+	args := m.Called(search)
+	return args.Get(0).(string), args.Error(1)
+}
+
+func (m *mockRepositoryUseCase) ExplainQuery(rsp *models.RepositorySearchParams) (*models.QueryExplanation, error) {
+	args := m.Called(rsp)
+	return args.Get(0).(*models.QueryExplanation), args.Error(1)
+}
+
+func (m *mockRepositoryUseCase) SearchBatch(rsps []*models.RepositorySearchParams) []usecases.BatchResult {
+	args := m.Called(rsps)
+	return args.Get(0).([]usecases.BatchResult)
+}
+
+func (m *mockRepositoryUseCase) GetRepository(params *models.RepositoryParams) (*models.Repository, error) {
+	args := m.Called(params)
+	return args.Get(0).(*models.Repository), args.Error(1)
+}
+
+func (m *mockRepositoryUseCase) SearchCode(params *models.CodeSearchParams) (*models.CodeSearchResult, error) {
+	args := m.Called(params)
+	return args.Get(0).(*models.CodeSearchResult), args.Error(1)
+}
+
 func (m *mockRepositoryUseCase) ValidateQuery(query string) (language string, err error) {
 	args := m.Called(query)
 	return args.Get(0).(string), args.Error(1)
@@ -43,11 +94,14 @@ func TestSearchRepositoriesEndpoint(t *testing.T) {
 			mockCall: func(m *mockRepositoryUseCase) {
 				m.On("ValidateQuery", "golang language:go").Return("go", nil)
 				m.On("SearchRepositories", &models.RepositorySearchParams{
-					Query:    "golang language:go",
-					Header:   header,
-					Language: "go",
-					PerPage:  "100",
-					Page:     "1",
+					Query:         "golang language:go",
+					Header:        header,
+					Language:      "go",
+					PerPage:       "100",
+					Page:          "1",
+					LanguagesMode: models.LanguagesModeRequested,
+					Order:         models.OrderDesc,
+					Context:       context.Background(),
 				}).Return(&models.RepositorySearchResponse{
 					TotalCount: 1,
 					Items: []models.Repository{
@@ -69,6 +123,7 @@ func TestSearchRepositoriesEndpoint(t *testing.T) {
 					Language: "",
 					PerPage:  "100",
 					Page:     "1",
+					Context:  context.Background(),
 				}).Return(&models.RepositorySearchResponse{}, errors.New("usecase error"))
 			},
 			expectedStatus: http.StatusUnauthorized,
@@ -83,11 +138,14 @@ func TestSearchRepositoriesEndpoint(t *testing.T) {
 			mockCall: func(m *mockRepositoryUseCase) {
 				m.On("ValidateQuery", "wow").Return("", nil)
 				m.On("SearchRepositories", &models.RepositorySearchParams{
-					Query:    "wow",
-					Header:   header,
-					Language: "",
-					PerPage:  "100",
-					Page:     "1",
+					Query:         "wow",
+					Header:        header,
+					Language:      "",
+					PerPage:       "100",
+					Page:          "1",
+					LanguagesMode: models.LanguagesModeRequested,
+					Order:         models.OrderDesc,
+					Context:       context.Background(),
 				}).Return(&models.RepositorySearchResponse{}, errors.New("usecase error"))
 			},
 			expectedStatus: http.StatusBadRequest,
@@ -101,6 +159,128 @@ func TestSearchRepositoriesEndpoint(t *testing.T) {
 			},
 			expectedStatus: http.StatusBadRequest,
 		},
+		"full language breakdown": {
+			rsp: &models.RepositorySearchParams{
+				Query:  "golang+language:go&languages=all",
+				Header: header,
+			},
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ValidateQuery", "golang language:go").Return("go", nil)
+				m.On("SearchRepositories", &models.RepositorySearchParams{
+					Query:         "golang language:go",
+					Header:        header,
+					Language:      "go",
+					PerPage:       "100",
+					Page:          "1",
+					LanguagesMode: models.LanguagesModeAll,
+					Order:         models.OrderDesc,
+					Context:       context.Background(),
+				}).Return(&models.RepositorySearchResponse{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"invalid languages mode, return error": {
+			rsp: &models.RepositorySearchParams{
+				Query:  "golang+language:go&languages=some",
+				Header: header,
+			},
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ValidateQuery", "golang language:go").Return("go", nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"sorted by language share": {
+			rsp: &models.RepositorySearchParams{
+				Query:  "golang+language:go&sort=language_share&order=asc",
+				Header: header,
+			},
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ValidateQuery", "golang language:go").Return("go", nil)
+				m.On("SearchRepositories", &models.RepositorySearchParams{
+					Query:         "golang language:go",
+					Header:        header,
+					Language:      "go",
+					PerPage:       "100",
+					Page:          "1",
+					LanguagesMode: models.LanguagesModeRequested,
+					Sort:          models.SortLanguageShare,
+					Order:         models.OrderAsc,
+					Context:       context.Background(),
+				}).Return(&models.RepositorySearchResponse{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"filled page from cursor": {
+			rsp: &models.RepositorySearchParams{
+				Query:  "ignored&cursor=signedcursor",
+				Header: header,
+			},
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ResolveCursor", "signedcursor").Return(&models.RepositorySearchParams{
+					Query:         "golang language:go",
+					PerPage:       "50",
+					Page:          "2",
+					LanguagesMode: models.LanguagesModeRequested,
+					Order:         models.OrderDesc,
+					Fill:          true,
+					Cursor:        "signedcursor",
+				}, nil)
+				m.On("ValidateQuery", "golang language:go").Return("go", nil)
+				m.On("SearchRepositories", &models.RepositorySearchParams{
+					Query:         "golang language:go",
+					Header:        header,
+					Language:      "go",
+					PerPage:       "50",
+					Page:          "2",
+					LanguagesMode: models.LanguagesModeRequested,
+					Order:         models.OrderDesc,
+					Fill:          true,
+					Cursor:        "signedcursor",
+					Context:       context.Background(),
+				}).Return(&models.RepositorySearchResponse{}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		"invalid cursor, return error": {
+			rsp: &models.RepositorySearchParams{
+				Query:  "golang+language:go&cursor=forged",
+				Header: header,
+			},
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ResolveCursor", "forged").Return((*models.RepositorySearchParams)(nil), errors.New("invalid cursor"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"page beyond search window, return error": {
+			rsp: &models.RepositorySearchParams{
+				Query:  "golang+language:go&per_page=100&page=11",
+				Header: header,
+			},
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ValidateQuery", "golang language:go").Return("go", nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"invalid fill, return error": {
+			rsp: &models.RepositorySearchParams{
+				Query:  "golang+language:go&fill=maybe",
+				Header: header,
+			},
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ValidateQuery", "golang language:go").Return("go", nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"invalid sort, return error": {
+			rsp: &models.RepositorySearchParams{
+				Query:  "golang+language:go&sort=size",
+				Header: header,
+			},
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ValidateQuery", "golang language:go").Return("go", nil)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
 		"invalid per_page, return error": {
 			rsp: &models.RepositorySearchParams{
 				Query:  "golang+language:go&per_page=abc",
@@ -140,6 +320,59 @@ func TestSearchRepositoriesEndpoint(t *testing.T) {
 	}
 }
 
+func TestSearchRepositoriesFollowNextLink(t *testing.T) {
+	header := "Bearer tokentoken"
+	params := &models.RepositorySearchParams{
+		Query:         "golang language:go",
+		Header:        header,
+		Language:      "go",
+		PerPage:       "100",
+		Page:          "1",
+		LanguagesMode: models.LanguagesModeRequested,
+		Sort:          models.SortActivity,
+		Order:         models.OrderDesc,
+		Include:       []string{models.IncludeActivity},
+		Highlight:     true,
+		Context:       context.Background(),
+	}
+	next := *params
+	next.Page = "2"
+
+	mockUseCase := new(mockRepositoryUseCase)
+	mockUseCase.On("ValidateQuery", "golang language:go").Return("go", nil)
+	mockUseCase.On("SearchRepositories", params).Return(&models.RepositorySearchResponse{
+		TotalCount: 200,
+		Cursors:    &models.PageLinks{Next: "nextcursor"},
+	}, nil)
+	// The cursor carries the includes and the highlight, the link has no other parameter
+	resolved := next
+	resolved.Header = ""
+	resolved.Language = ""
+	resolved.Context = nil
+	mockUseCase.On("ResolveCursor", "nextcursor").Return(&resolved, nil)
+	mockUseCase.On("SearchRepositories", &next).Return(&models.RepositorySearchResponse{TotalCount: 200}, nil)
+
+	controller := NewRepositoryController(mockUseCase)
+
+	req := httptest.NewRequest(http.MethodGet, "/repos?q=golang+language:go&sort=activity&include=activity&highlight=true", nil)
+	req.Header.Add("Authorization", header)
+	w := httptest.NewRecorder()
+	controller.SearchRepositories(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var body models.RepositorySearchResponse
+	assert.NoError(t, json.NewDecoder(w.Body).Decode(&body))
+	assert.Equal(t, "/repos?cursor=nextcursor", body.Links.Next)
+
+	req = httptest.NewRequest(http.MethodGet, body.Links.Next, nil)
+	req.Header.Add("Authorization", header)
+	w = httptest.NewRecorder()
+	controller.SearchRepositories(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mockUseCase.AssertExpectations(t)
+}
+
 func TestValidatePagination(t *testing.T) {
 	tests := map[string]struct {
 		perPage     string
@@ -172,6 +405,11 @@ func TestValidatePagination(t *testing.T) {
 			page:    "1",
 			wantErr: assert.Error,
 		},
+		"per_page zero, cursors need at least one item per page": {
+			perPage: "0",
+			page:    "1",
+			wantErr: assert.Error,
+		},
 		"per_page negative": {
 			perPage: "-1",
 			page:    "1",
@@ -192,6 +430,18 @@ func TestValidatePagination(t *testing.T) {
 			page:    "0",
 			wantErr: assert.Error,
 		},
+		"last page of search window": {
+			perPage:     "100",
+			page:        "10",
+			wantPerPage: "100",
+			wantPage:    "10",
+			wantErr:     assert.NoError,
+		},
+		"page beyond search window": {
+			perPage: "30",
+			page:    "35",
+			wantErr: assert.Error,
+		},
 	}
 
 	for name, tt := range tests {
@@ -205,6 +455,439 @@ func TestValidatePagination(t *testing.T) {
 	}
 }
 
+func TestSetPageLinks(t *testing.T) {
+	t.Run("links from cursors", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		repos := &models.RepositorySearchResponse{
+			Cursors: &models.PageLinks{First: "first", Next: "n+xt", Last: "last"},
+		}
+
+		setPageLinks(w, "/repos", repos)
+
+		assert.Equal(t, &models.PageLinks{
+			First: "/repos?cursor=first",
+			Next:  "/repos?cursor=n%2Bxt",
+			Last:  "/repos?cursor=last",
+		}, repos.Links)
+		assert.Equal(t, `</repos?cursor=first>; rel="first", </repos?cursor=n%2Bxt>; rel="next", </repos?cursor=last>; rel="last"`, w.Header().Get("Link"))
+	})
+
+	t.Run("no cursors", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		repos := &models.RepositorySearchResponse{}
+
+		setPageLinks(w, "/repos", repos)
+
+		assert.Nil(t, repos.Links)
+		assert.Empty(t, w.Header().Get("Link"))
+	})
+}
+
+func TestValidateLanguagesMode(t *testing.T) {
+	tests := map[string]struct {
+		mode     string
+		wantMode string
+		wantErr  assert.ErrorAssertionFunc
+	}{
+		"default value when empty": {
+			mode:     "",
+			wantMode: models.LanguagesModeRequested,
+			wantErr:  assert.NoError,
+		},
+		"all languages": {
+			mode:     "all",
+			wantMode: models.LanguagesModeAll,
+			wantErr:  assert.NoError,
+		},
+		"requested language": {
+			mode:     "requested",
+			wantMode: models.LanguagesModeRequested,
+			wantErr:  assert.NoError,
+		},
+		"unknown mode": {
+			mode:     "primary",
+			wantMode: "primary",
+			wantErr:  assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mode := tt.mode
+
+			err := validateLanguagesMode(&mode)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.wantMode, mode)
+		})
+	}
+}
+
+func TestValidateSort(t *testing.T) {
+	tests := map[string]struct {
+		sort      string
+		order     string
+		wantOrder string
+		wantErr   assert.ErrorAssertionFunc
+	}{
+		"default values when empty": {
+			wantOrder: models.OrderDesc,
+			wantErr:   assert.NoError,
+		},
+		"github sort": {
+			sort:      "help-wanted-issues",
+			order:     "asc",
+			wantOrder: models.OrderAsc,
+			wantErr:   assert.NoError,
+		},
+		"local sort": {
+			sort:      models.SortLanguageBytes,
+			wantOrder: models.OrderDesc,
+			wantErr:   assert.NoError,
+		},
+		"unknown sort": {
+			sort:    "size",
+			wantErr: assert.Error,
+		},
+		"unknown order": {
+			sort:      "stars",
+			order:     "up",
+			wantOrder: "up",
+			wantErr:   assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sort := tt.sort
+			order := tt.order
+
+			err := validateSort(&sort, &order)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.wantOrder, order)
+		})
+	}
+}
+
+func TestValidateFill(t *testing.T) {
+	tests := map[string]struct {
+		fill     string
+		wantFill bool
+		wantErr  assert.ErrorAssertionFunc
+	}{
+		"default value when empty": {
+			fill:     "",
+			wantFill: false,
+			wantErr:  assert.NoError,
+		},
+		"enabled": {
+			fill:     "true",
+			wantFill: true,
+			wantErr:  assert.NoError,
+		},
+		"disabled": {
+			fill:     "0",
+			wantFill: false,
+			wantErr:  assert.NoError,
+		},
+		"not a boolean": {
+			fill:    "yes please",
+			wantErr: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			fill, err := validateFill(tt.fill)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.wantFill, fill)
+		})
+	}
+}
+
+func TestValidateHighlight(t *testing.T) {
+	tests := map[string]struct {
+		highlight     string
+		wantHighlight bool
+		wantErr       assert.ErrorAssertionFunc
+	}{
+		"default value when empty": {
+			highlight: "",
+			wantErr:   assert.NoError,
+		},
+		"enabled": {
+			highlight:     "true",
+			wantHighlight: true,
+			wantErr:       assert.NoError,
+		},
+		"not a boolean": {
+			highlight: "bold",
+			wantErr:   assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			highlight, err := validateHighlight(tt.highlight)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.wantHighlight, highlight)
+		})
+	}
+}
+
+func TestValidateBudget(t *testing.T) {
+	tests := map[string]struct {
+		maxCalls   string
+		onBudget   string
+		dryRun     string
+		wantParams *models.RepositorySearchParams
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		"no budget": {
+			wantParams: &models.RepositorySearchParams{},
+			wantErr:    assert.NoError,
+		},
+		"degraded budget": {
+			maxCalls:   "20",
+			onBudget:   models.BudgetDegrade,
+			wantParams: &models.RepositorySearchParams{MaxGitHubCalls: 20, OnBudget: models.BudgetDegrade},
+			wantErr:    assert.NoError,
+		},
+		"dry run": {
+			dryRun:     "true",
+			wantParams: &models.RepositorySearchParams{DryRun: true},
+			wantErr:    assert.NoError,
+		},
+		"zero budget, return error": {
+			maxCalls: "0",
+			wantErr:  assert.Error,
+		},
+		"invalid budget, return error": {
+			maxCalls: "many",
+			wantErr:  assert.Error,
+		},
+		"invalid on_budget, return error": {
+			onBudget: "ignore",
+			wantErr:  assert.Error,
+		},
+		"invalid dry_run, return error": {
+			dryRun:  "maybe",
+			wantErr: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			params := &models.RepositorySearchParams{}
+			err := validateBudget(tt.maxCalls, tt.onBudget, tt.dryRun, params)
+			tt.wantErr(t, err)
+			if tt.wantParams != nil {
+				assert.Equal(t, tt.wantParams, params)
+			}
+		})
+	}
+}
+
+func TestValidateFacets(t *testing.T) {
+	tests := map[string]struct {
+		facets     string
+		facetPages string
+		fill       bool
+		wantParams *models.RepositorySearchParams
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		"no facets": {
+			wantParams: &models.RepositorySearchParams{},
+			wantErr:    assert.NoError,
+		},
+		"some facets, duplicates dropped": {
+			facets:     "license, topic,license",
+			wantParams: &models.RepositorySearchParams{Facets: []string{models.FacetLicense, models.FacetTopic}, FacetPages: 1},
+			wantErr:    assert.NoError,
+		},
+		"all facets over several pages": {
+			facets:     "all",
+			facetPages: "3",
+			wantParams: &models.RepositorySearchParams{Facets: models.FacetNames, FacetPages: 3},
+			wantErr:    assert.NoError,
+		},
+		"unknown facet, return error": {
+			facets:  "license,color",
+			wantErr: assert.Error,
+		},
+		"facet_pages without facets, return error": {
+			facetPages: "2",
+			wantErr:    assert.Error,
+		},
+		"too many facet_pages, return error": {
+			facets:     "license",
+			facetPages: "11",
+			wantErr:    assert.Error,
+		},
+		"facet_pages with fill, return error": {
+			facets:     "license",
+			facetPages: "2",
+			fill:       true,
+			wantErr:    assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			params := &models.RepositorySearchParams{Fill: tt.fill}
+			err := validateFacets(tt.facets, tt.facetPages, params)
+			tt.wantErr(t, err)
+			if tt.wantParams != nil {
+				assert.Equal(t, tt.wantParams, params)
+			}
+		})
+	}
+}
+
+func TestValidateInclude(t *testing.T) {
+	tests := map[string]struct {
+		include    string
+		sort       string
+		query      string
+		wantParams *models.RepositorySearchParams
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		"no include": {
+			wantParams: &models.RepositorySearchParams{},
+			wantErr:    assert.NoError,
+		},
+		"some includes, duplicates dropped": {
+			include:    "releases, languages,releases",
+			wantParams: &models.RepositorySearchParams{Include: []string{models.IncludeReleases, models.IncludeLanguages}},
+			wantErr:    assert.NoError,
+		},
+		"unknown include, return error": {
+			include: "languages,stars",
+			wantErr: assert.Error,
+		},
+		"activity sort with activity": {
+			include:    "activity",
+			sort:       models.SortActivity,
+			wantParams: &models.RepositorySearchParams{Sort: models.SortActivity, Include: []string{models.IncludeActivity}},
+			wantErr:    assert.NoError,
+		},
+		"activity sort without activity, return error": {
+			include: "languages",
+			sort:    models.SortActivity,
+			wantErr: assert.Error,
+		},
+		"activity sort without include, return error": {
+			sort:    models.SortActivity,
+			wantErr: assert.Error,
+		},
+		"depends_on with sbom": {
+			include:    "languages,sbom",
+			query:      "language:go depends_on:github.com/pkg/errors",
+			wantParams: &models.RepositorySearchParams{Query: "language:go depends_on:github.com/pkg/errors", Include: []string{models.IncludeLanguages, models.IncludeSBOM}},
+			wantErr:    assert.NoError,
+		},
+		"depends_on with manifests": {
+			include:    "manifests",
+			query:      "depends_on:react",
+			wantParams: &models.RepositorySearchParams{Query: "depends_on:react", Include: []string{models.IncludeManifests}},
+			wantErr:    assert.NoError,
+		},
+		"depends_on without sbom, return error": {
+			query:   "language:go depends_on:github.com/pkg/errors",
+			wantErr: assert.Error,
+		},
+		"local sort without languages, return error": {
+			include: "releases",
+			sort:    models.SortLanguageShare,
+			wantErr: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			params := &models.RepositorySearchParams{Sort: tt.sort, Query: tt.query}
+			err := validateInclude(tt.include, params)
+			tt.wantErr(t, err)
+			if tt.wantParams != nil {
+				assert.Equal(t, tt.wantParams, params)
+			}
+		})
+	}
+}
+
+func TestValidateFields(t *testing.T) {
+	tests := map[string]struct {
+		fields     string
+		wantFields []string
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		"no fields": {
+			wantErr: assert.NoError,
+		},
+		"some fields": {
+			fields:     "full_name, stargazers_count,owner",
+			wantFields: []string{"full_name", "stargazers_count", "owner"},
+			wantErr:    assert.NoError,
+		},
+		"unknown field, return error": {
+			fields:  "full_name,stars",
+			wantErr: assert.Error,
+		},
+		"empty field, return error": {
+			fields:  "full_name,",
+			wantErr: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			fields, err := validateFields(tt.fields)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.wantFields, fields)
+		})
+	}
+}
+
+func TestSearchRepositoriesFields(t *testing.T) {
+	tests := map[string]struct {
+		format   string
+		wantBody string
+	}{
+		"json": {
+			format:   "json",
+			wantBody: `"items":[{"full_name":"scalingo/scalingo-test","stargazers_count":42}]`,
+		},
+		"ndjson": {
+			format:   "ndjson",
+			wantBody: `{"full_name":"scalingo/scalingo-test","stargazers_count":42}` + "\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/repos?q=language:go&fields=stargazers_count,full_name&format="+tt.format, nil)
+			req.Header.Add("Authorization", "Bearer tokentoken")
+			w := httptest.NewRecorder()
+
+			m := new(mockRepositoryUseCase)
+			m.On("ValidateQuery", "language:go").Return("go", nil)
+			m.On("SearchRepositories", mock.Anything).Return(&models.RepositorySearchResponse{
+				TotalCount: 1,
+				Items:      []models.Repository{{FullName: "scalingo/scalingo-test", StargazersCount: 42, Description: "hidden"}},
+			}, nil)
+
+			NewRepositoryController(m).SearchRepositories(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+		})
+	}
+}
+
+func TestSearchErrorStatus(t *testing.T) {
+	assert.Equal(t, http.StatusUnprocessableEntity, searchErrorStatus(fmt.Errorf("%w, 1 calls spent", usecases.ErrBudgetExceeded)))
+	assert.Equal(t, http.StatusBadRequest, searchErrorStatus(errors.New("rate limit exceeded")))
+}
+
 // this should be a helper function but we use it only here for now
 func ptr(s string) *string {
 	return &s
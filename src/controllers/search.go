@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// maxSearchBodySize bounds the JSON documents accepted by POST /repos/search
+const maxSearchBodySize = 1 << 20
+
+// SearchStructured handles POST /repos/search, the search is described by a JSON document compiled into a q string
+func (rc *RepositoryController) SearchStructured(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		renderError(w, http.StatusMethodNotAllowed, "method not allowed, use POST to search with a JSON document")
+		return
+	}
+
+	header := r.Header.Get("Authorization")
+	err := validateHeader(&header)
+	if err != nil {
+		renderError(w, http.StatusUnauthorized, err.Error())
+		return
+	}
+
+	_, encoder, status, err := negotiateEncoder(r)
+	if err != nil {
+		renderError(w, status, err.Error())
+		return
+	}
+
+	search, err := decodeStructuredSearch(w, r)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query, err := rc.ru.CompileQuery(search)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	in := searchInput{
+		query:         query,
+		languagesMode: search.Languages,
+		sort:          search.Sort,
+		order:         search.Order,
+		fill:          strconv.FormatBool(search.Fill),
+	}
+	if search.PerPage != 0 {
+		in.perPage = strconv.Itoa(search.PerPage)
+	}
+	if search.Page != 0 {
+		in.page = strconv.Itoa(search.Page)
+	}
+
+	params, err := rc.searchParams(in, header)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, fmt.Sprintf("invalid compiled query %q: %s", query, err))
+		return
+	}
+	params.Context = r.Context()
+
+	maxCalls := ""
+	if search.MaxGitHubCalls != 0 {
+		maxCalls = strconv.Itoa(search.MaxGitHubCalls)
+	}
+	err = validateBudget(maxCalls, search.OnBudget, strconv.FormatBool(search.DryRun), params)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	facetPages := ""
+	if search.FacetPages != 0 {
+		facetPages = strconv.Itoa(search.FacetPages)
+	}
+	err = validateFacets(strings.Join(search.Facets, ","), facetPages, params)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	err = validateInclude(strings.Join(search.Include, ","), params)
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	params.Highlight = search.Highlight
+
+	fields, err := validateFields(strings.Join(search.Fields, ","))
+	if err != nil {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	repos, err := rc.ru.SearchRepositories(params)
+	if err != nil {
+		renderError(w, searchErrorStatus(err), err.Error())
+		return
+	}
+	repos.Query = query
+	selectFields(repos.Items, fields)
+
+	// Cursors record the compiled query, the following pages are read from GET /repos
+	setPageLinks(w, "/repos", repos)
+
+	renderEncoded(w, encoder, http.StatusOK, repos, repos.Items)
+}
+
+// decodeStructuredSearch reads the JSON document of the request
+// Unknown fields are rejected so a typo does not silently widen the search.
+func decodeStructuredSearch(w http.ResponseWriter, r *http.Request) (*models.StructuredSearch, error) {
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxSearchBodySize))
+	decoder.DisallowUnknownFields()
+
+	var search models.StructuredSearch
+	if err := decoder.Decode(&search); err != nil {
+		return nil, fmt.Errorf("invalid search document: %w", err)
+	}
+
+	return &search, nil
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchStructuredEndpoint(t *testing.T) {
+	header := "Bearer tokentoken"
+
+	tests := map[string]struct {
+		method         string
+		body           string
+		header         string
+		mockCall       func(*mockRepositoryUseCase)
+		expectedStatus int
+		wantBody       string
+	}{
+		"nominal": {
+			method: http.MethodPost,
+			body:   `{"text":"tetris","language":["go"],"stars":{"gte":10},"per_page":10,"sort":"stars"}`,
+			header: header,
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("CompileQuery", mock.Anything).Return("tetris language:go stars:>=10", nil)
+				m.On("ValidateQuery", "tetris language:go stars:>=10").Return("go", nil)
+				m.On("SearchRepositories", &models.RepositorySearchParams{
+					Query:         "tetris language:go stars:>=10",
+					Header:        header,
+					Language:      "go",
+					PerPage:       "10",
+					Page:          "1",
+					LanguagesMode: models.LanguagesModeRequested,
+					Sort:          "stars",
+					Order:         models.OrderDesc,
+					Context:       context.Background(),
+				}).Return(&models.RepositorySearchResponse{TotalCount: 1, Items: []models.Repository{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			wantBody:       `"query":"tetris language:go stars:`,
+		},
+		"wrong method, return error": {
+			method:         http.MethodGet,
+			header:         header,
+			expectedStatus: http.StatusMethodNotAllowed,
+		},
+		"missing header, return error": {
+			method:         http.MethodPost,
+			body:           `{"language":["go"]}`,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		"invalid document, return error": {
+			method:         http.MethodPost,
+			body:           `{"language":`,
+			header:         header,
+			expectedStatus: http.StatusBadRequest,
+		},
+		"unknown field, return error": {
+			method:         http.MethodPost,
+			body:           `{"language":["go"],"star":{"gte":10}}`,
+			header:         header,
+			expectedStatus: http.StatusBadRequest,
+			wantBody:       `unknown field`,
+		},
+		"compile error, return error": {
+			method: http.MethodPost,
+			body:   `{"text":"tetris"}`,
+			header: header,
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("CompileQuery", &models.StructuredSearch{Text: "tetris"}).Return("", errors.New("language must contain exactly one language"))
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		"invalid compiled query, return error": {
+			method: http.MethodPost,
+			body:   `{"language":["go"],"created":{"from":"2023-13-45"}}`,
+			header: header,
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("CompileQuery", mock.Anything).Return("language:go created:>=2023-13-45", nil)
+				m.On("ValidateQuery", "language:go created:>=2023-13-45").Return("", errors.New("created must be a valid date"))
+			},
+			expectedStatus: http.StatusBadRequest,
+			wantBody:       `invalid compiled query`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/repos/search", strings.NewReader(tt.body))
+			if tt.header != "" {
+				req.Header.Add("Authorization", tt.header)
+			}
+			w := httptest.NewRecorder()
+
+			m := new(mockRepositoryUseCase)
+			if tt.mockCall != nil {
+				tt.mockCall(m)
+			}
+
+			NewRepositoryController(m).SearchStructured(w, req)
+
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Contains(t, w.Body.String(), tt.wantBody)
+			m.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// streamFormat writes the events of a stream
+type streamFormat struct {
+	contentType string
+	write       func(w io.Writer, event string, data interface{}) error
+}
+
+// streamFormats are the formats available through the stream parameter, by name
+var streamFormats = map[string]*streamFormat{
+	"sse":    {contentType: "text/event-stream", write: writeServerSentEvent},
+	"ndjson": {contentType: "application/x-ndjson", write: writeNDJSONEvent},
+}
+
+// writeServerSentEvent writes an event of a text/event-stream
+// https://html.spec.whatwg.org/multipage/server-sent-events.html
+func writeServerSentEvent(w io.Writer, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	return err
+}
+
+// writeNDJSONEvent writes an event as a single JSON line
+func writeNDJSONEvent(w io.Writer, event string, data interface{}) error {
+	return json.NewEncoder(w).Encode(struct {
+		Event string      `json:"event"`
+		Data  interface{} `json:"data"`
+	}{
+		Event: event,
+		Data:  data,
+	})
+}
+
+// validateStream returns the stream format asked by the stream parameter, or by an Accept header of text/event-stream
+// It returns nil when the response must not be streamed.
+func validateStream(r *http.Request) (*streamFormat, error) {
+	stream := r.URL.Query().Get("stream")
+	if stream == "" {
+		if r.Header.Get("Accept") == streamFormats["sse"].contentType {
+			return streamFormats["sse"], nil
+		}
+		return nil, nil
+	}
+
+	format, ok := streamFormats[stream]
+	if !ok {
+		return nil, fmt.Errorf("stream must be either 'sse' or 'ndjson'")
+	}
+
+	return format, nil
+}
+
+// streamRepositories writes and flushes each event as soon as the use case emits it
+// Errors happening before the first event are rendered as usual, later ones can only be logged.
+// Repositories are sent with the selected fields only, all of them when fields is empty.
+func (rc *RepositoryController) streamRepositories(w http.ResponseWriter, path string, format *streamFormat, params *models.RepositorySearchParams, fields []string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		renderError(w, http.StatusInternalServerError, "streaming is not supported")
+		return
+	}
+
+	started := false
+	err := rc.ru.StreamRepositories(params, func(event string, data interface{}) error {
+		if !started {
+			if metadata, ok := data.(*models.StreamMetadata); ok {
+				metadata.Links = pageLinks(path, metadata.Cursors)
+				setLinkHeader(w, metadata.Links)
+			}
+
+			w.Header().Set("Content-Type", format.contentType)
+			w.Header().Set("Cache-Control", "no-cache")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+
+		if repo, ok := data.(*models.Repository); ok && len(fields) > 0 {
+			selected := repo.SelectFields(fields)
+			data = &selected
+		}
+
+		if err := format.write(w, event, data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	})
+	if err == nil {
+		return
+	}
+
+	if !started {
+		renderError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	log.Print("error streaming repositories: ", err)
+}
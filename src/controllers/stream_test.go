@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// streamedEvent is an event the mocked use case emits
+type streamedEvent struct {
+	event string
+	data  interface{}
+}
+
+var streamedEvents = []streamedEvent{
+	{event: models.StreamEventMetadata, data: &models.StreamMetadata{TotalCount: 1, Pending: 1, Cursors: &models.PageLinks{First: "first"}}},
+	{event: models.StreamEventRepository, data: &models.Repository{FullName: "scalingo/scalingo-test"}},
+	{event: models.StreamEventSummary, data: &models.StreamSummary{Count: 1, Errors: []string{}}},
+}
+
+func TestStreamRepositoriesEndpoint(t *testing.T) {
+	header := "Bearer tokentoken"
+
+	tests := map[string]struct {
+		url             string
+		accept          string
+		mockCall        func(*mockRepositoryUseCase)
+		wantStatus      int
+		wantContentType string
+		wantBody        string
+	}{
+		"server-sent events": {
+			url: "/repos?q=language:go&stream=sse&fields=full_name",
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ValidateQuery", "language:go").Return("go", nil)
+				m.On("StreamRepositories", mock.Anything).Return(streamedEvents, nil)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/event-stream",
+			wantBody: "event: metadata\n" +
+				`data: {"total_count":1,"per_page":0,"page":0,"incomplete_results":false,"pending":1,"links":{"first":"/repos?cursor=first"}}` + "\n\n" +
+				"event: repository\n" +
+				`data: {"full_name":"scalingo/scalingo-test"}` + "\n\n" +
+				"event: summary\n" +
+				`data: {"count":1,"dropped":0,"failed":0,"errors":[]}` + "\n\n",
+		},
+		"server-sent events from accept header": {
+			url:    "/repos?q=language:go",
+			accept: "text/event-stream",
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ValidateQuery", "language:go").Return("go", nil)
+				m.On("StreamRepositories", mock.Anything).Return(streamedEvents[2:], nil)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "text/event-stream",
+			wantBody:        "event: summary\n" + `data: {"count":1,"dropped":0,"failed":0,"errors":[]}` + "\n\n",
+		},
+		"ndjson": {
+			url: "/repos?q=language:go&stream=ndjson",
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ValidateQuery", "language:go").Return("go", nil)
+				m.On("StreamRepositories", mock.Anything).Return(streamedEvents[2:], nil)
+			},
+			wantStatus:      http.StatusOK,
+			wantContentType: "application/x-ndjson",
+			wantBody:        `{"event":"summary","data":{"count":1,"dropped":0,"failed":0,"errors":[]}}` + "\n",
+		},
+		"unknown stream format, return error": {
+			url:             "/repos?q=language:go&stream=websocket",
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/json",
+			wantBody:        `{"error":"stream must be either 'sse' or 'ndjson'"}` + "\n",
+		},
+		"budget, return error": {
+			url: "/repos?q=language:go&stream=sse&max_github_calls=10",
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ValidateQuery", "language:go").Return("go", nil)
+			},
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/json",
+			wantBody:        `{"error":"max_github_calls and dry_run cannot be used when streaming"}` + "\n",
+		},
+		"error before the first event, return error": {
+			url: "/repos?q=language:go&stream=sse",
+			mockCall: func(m *mockRepositoryUseCase) {
+				m.On("ValidateQuery", "language:go").Return("go", nil)
+				m.On("StreamRepositories", mock.Anything).Return(nil, errors.New("rate limit exceeded"))
+			},
+			wantStatus:      http.StatusBadRequest,
+			wantContentType: "application/json",
+			wantBody:        `{"error":"rate limit exceeded"}` + "\n",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.url, nil)
+			req.Header.Add("Authorization", header)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			w := httptest.NewRecorder()
+
+			m := new(mockRepositoryUseCase)
+			if tt.mockCall != nil {
+				tt.mockCall(m)
+			}
+
+			NewRepositoryController(m).SearchRepositories(w, req)
+
+			assert.Equal(t, tt.wantStatus, w.Code)
+			assert.Equal(t, tt.wantContentType, w.Header().Get("Content-Type"))
+			assert.Equal(t, tt.wantBody, w.Body.String())
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStreamRepositoriesLinkHeader(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/repos?q=language:go&stream=ndjson", nil)
+	req.Header.Add("Authorization", "Bearer tokentoken")
+	w := httptest.NewRecorder()
+
+	m := new(mockRepositoryUseCase)
+	m.On("ValidateQuery", "language:go").Return("go", nil)
+	m.On("StreamRepositories", mock.Anything).Return(streamedEvents[:1], nil)
+
+	NewRepositoryController(m).SearchRepositories(w, req)
+
+	assert.Equal(t, `</repos?cursor=first>; rel="first"`, w.Header().Get("Link"))
+	assert.True(t, w.Flushed)
+}
@@ -0,0 +1,32 @@
+package models
+
+// BatchSearchRequest holds several searches executed in a single request
+type BatchSearchRequest struct {
+	Queries []BatchQuery `json:"queries"`
+}
+
+// BatchQuery is a search of a batch, it takes the parameters of /repos
+type BatchQuery struct {
+	Q              string `json:"q"`
+	PerPage        int    `json:"per_page"`
+	Page           int    `json:"page"`
+	Languages      string `json:"languages"`
+	Sort           string `json:"sort"`
+	Order          string `json:"order"`
+	Fill           bool   `json:"fill"`
+	MaxGitHubCalls int    `json:"max_github_calls"`
+	OnBudget       string `json:"on_budget"`
+}
+
+// BatchSearchResponse holds the outcome of each search of a batch, in the order of the request
+type BatchSearchResponse struct {
+	Results []BatchSearchResult `json:"results"`
+}
+
+// BatchSearchResult is the outcome of a search of a batch, Status is the one /repos would have answered
+type BatchSearchResult struct {
+	Query  string                    `json:"query"`
+	Status int                       `json:"status"`
+	Error  string                    `json:"error,omitempty"`
+	Result *RepositorySearchResponse `json:"result,omitempty"`
+}
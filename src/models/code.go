@@ -0,0 +1,49 @@
+package models
+
+// CodeSearchParams are the parameters of a code search
+type CodeSearchParams struct {
+	Query   string
+	PerPage string
+	Page    string
+	Header  string
+	// LanguagesMode is either LanguagesModeRequested or LanguagesModeAll
+	LanguagesMode string
+}
+
+// CodeFile is a file matching a code search
+type CodeFile struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	SHA     string `json:"sha"`
+	HTMLURL string `json:"html_url"`
+}
+
+// CodeSearchItem is a file of the GitHub code search response, with the repository it belongs to
+type CodeSearchItem struct {
+	CodeFile
+	// Repository is the short form GitHub returns with code results, without stars, forks or dates
+	Repository Repository `json:"repository"`
+}
+
+// CodeSearchResponse is the response of the GitHub code search API
+type CodeSearchResponse struct {
+	TotalCount        int              `json:"total_count"`
+	IncompleteResults bool             `json:"incomplete_results"`
+	Items             []CodeSearchItem `json:"items"`
+}
+
+// CodeSearchResult are the files of a code search grouped by repository
+type CodeSearchResult struct {
+	// TotalCount is the number of files matching the search
+	TotalCount        int              `json:"total_count"`
+	IncompleteResults bool             `json:"incomplete_results"`
+	PerPage           string           `json:"per_page"`
+	Page              string           `json:"page"`
+	Repositories      []CodeRepository `json:"repositories"`
+}
+
+// CodeRepository is a repository with its files matching a code search
+type CodeRepository struct {
+	Repository Repository `json:"repository"`
+	Files      []CodeFile `json:"files"`
+}
@@ -0,0 +1,21 @@
+package models
+
+const (
+	// BudgetRefuse fails requests whose cost exceeds their budget
+	BudgetRefuse = "refuse"
+	// BudgetDegrade gives up fill, repositories or enrichment to stay within the budget
+	BudgetDegrade = "degrade"
+)
+
+// RequestCost reports the GitHub API calls of a request
+type RequestCost struct {
+	// Budget is the max_github_calls of the request, 0 when there is none
+	Budget int `json:"budget"`
+	// Estimated bounds the calls before the search, cache hits lower the actual cost
+	Estimated CallRange `json:"estimated"`
+	Spent     int       `json:"spent"`
+	CacheHits int       `json:"cache_hits"`
+	// Degraded lists what was given up to stay within the budget
+	Degraded []string `json:"degraded,omitempty"`
+	DryRun   bool     `json:"dry_run,omitempty"`
+}
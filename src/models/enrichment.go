@@ -0,0 +1,109 @@
+package models
+
+import "time"
+
+// Enrichments are the names accepted by the include parameter
+const (
+	IncludeLanguages    = "languages"
+	IncludeTopics       = "topics"
+	IncludeReleases     = "releases"
+	IncludeIssues       = "issues"
+	IncludeCommunity    = "community"
+	IncludeActivity     = "activity"
+	IncludeContributors = "contributors"
+	IncludeSBOM         = "sbom"
+	IncludeManifests    = "manifests"
+)
+
+// IncludeNames are the enrichments a request can include, in the order they are listed in errors
+var IncludeNames = []string{
+	IncludeLanguages,
+	IncludeTopics,
+	IncludeReleases,
+	IncludeIssues,
+	IncludeCommunity,
+	IncludeActivity,
+	IncludeContributors,
+	IncludeSBOM,
+	IncludeManifests,
+}
+
+// DefaultIncludes are the enrichments of a request without include
+var DefaultIncludes = []string{IncludeLanguages}
+
+// Release is the latest published release of a repository
+type Release struct {
+	TagName     string    `json:"tag_name"`
+	Name        string    `json:"name"`
+	HTMLURL     string    `json:"html_url"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// IssueCounts are the open issues of a repository
+// Open comes from the open_issues_count of GitHub, which includes pull requests, good first issues exclude them.
+type IssueCounts struct {
+	Open            int `json:"open"`
+	GoodFirstIssues int `json:"good_first_issues"`
+}
+
+// CommunityProfile is the community health of a repository
+type CommunityProfile struct {
+	HealthPercentage int `json:"health_percentage"`
+	// Files are the community files found in the repository (readme, license, contributing...)
+	Files []string `json:"files"`
+}
+
+// Activity is the commit activity of a repository over the last year
+type Activity struct {
+	// WeeklyCommits are the commits of each of the last 52 weeks, oldest first
+	WeeklyCommits []WeeklyCommits `json:"weekly_commits"`
+	// Last12Weeks is the number of commits of the last 12 weeks, the figure the activity sort uses
+	Last12Weeks int `json:"last_12_weeks"`
+	// OwnerLast12Weeks are the commits of the owner among them
+	OwnerLast12Weeks int `json:"owner_last_12_weeks"`
+}
+
+// WeeklyCommits are the commits of a week, starting on Sunday
+type WeeklyCommits struct {
+	Week    time.Time `json:"week"`
+	Commits int       `json:"commits"`
+}
+
+// Participation are the commits of each of the last 52 weeks, oldest first, for everyone and for the owner
+type Participation struct {
+	All   []int `json:"all"`
+	Owner []int `json:"owner"`
+}
+
+// Contributors are the contributors of a repository, anonymous ones included
+type Contributors struct {
+	Count int `json:"count"`
+	// TooLarge is set when GitHub refuses to list the contributors of the repository, Count is then unknown
+	TooLarge bool `json:"too_large"`
+}
+
+// Dependency is a package a repository depends on
+type Dependency struct {
+	// Ecosystem is the package type of its purl: golang, npm, pypi, cargo, maven, githubactions...
+	Ecosystem string `json:"ecosystem"`
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	License   string `json:"license,omitempty"`
+	// Scope is set for dependencies declared apart in a manifest: dev, peer, optional, build or indirect
+	Scope string `json:"scope,omitempty"`
+}
+
+// Manifest is a dependency manifest found at the root of a repository
+type Manifest struct {
+	Path      string `json:"path"`
+	Ecosystem string `json:"ecosystem"`
+	// GoVersion is the go directive of a go.mod
+	GoVersion    string       `json:"go_version,omitempty"`
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+// EnrichmentError is an enrichment that failed for a repository, the repository is returned without its data
+type EnrichmentError struct {
+	Enrichment string `json:"enrichment"`
+	Error      string `json:"error"`
+}
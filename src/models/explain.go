@@ -0,0 +1,56 @@
+package models
+
+// QueryExplanation describes how a search would be run, without running it
+type QueryExplanation struct {
+	Query string `json:"query"`
+	// Terms are the free text words of the query
+	Terms      []string             `json:"terms"`
+	Qualifiers []ExplainedQualifier `json:"qualifiers"`
+	GitHub     ExplainedGitHubPart  `json:"github"`
+	// Local are the steps applied by the API to what GitHub returns
+	Local          []ExplainedStep `json:"local"`
+	EstimatedCalls CallEstimate    `json:"estimated_calls"`
+}
+
+// ExplainedQualifier is a qualifier of the query once parsed
+type ExplainedQualifier struct {
+	Qualifier string `json:"qualifier"`
+	Value     string `json:"value"`
+	// Operator is one of =, >, >=, <, <= or range
+	Operator   string `json:"operator"`
+	Normalized string `json:"normalized"`
+	// Local tells whether the qualifier is also evaluated by the API
+	Local bool `json:"local"`
+}
+
+// ExplainedGitHubPart is what is sent to the GitHub search API for the first page
+type ExplainedGitHubPart struct {
+	Query   string `json:"query"`
+	Sort    string `json:"sort,omitempty"`
+	Order   string `json:"order,omitempty"`
+	PerPage string `json:"per_page"`
+	Page    string `json:"page"`
+	URL     string `json:"url"`
+}
+
+// ExplainedStep is a step the API applies locally
+type ExplainedStep struct {
+	Name   string `json:"name"`
+	Detail string `json:"detail"`
+}
+
+// CallEstimate is the number of GitHub API calls a search costs
+// The exact number depends on the results, so bounds are given.
+type CallEstimate struct {
+	Search    CallRange `json:"search"`
+	Languages CallRange `json:"languages"`
+	// Enrichments are the calls of the included enrichments other than languages
+	Enrichments CallRange `json:"enrichments"`
+	Total       CallRange `json:"total"`
+}
+
+// CallRange bounds a number of calls
+type CallRange struct {
+	Min int `json:"min"`
+	Max int `json:"max"`
+}
@@ -0,0 +1,78 @@
+package models
+
+import "time"
+
+// ExportResult holds every repository of a query, collected beyond the 1000 results of GitHub search
+type ExportResult struct {
+	Query    string         `json:"query"`
+	Coverage ExportCoverage `json:"coverage"`
+	Items    []Repository   `json:"items"`
+}
+
+// ExportCoverage tells how much of the results of a query have been collected by an export
+type ExportCoverage struct {
+	// TotalCount is the sum of the total counts of the windows that were crawled
+	TotalCount int `json:"total_count"`
+	// Collected is the number of distinct repositories collected
+	Collected  int `json:"collected"`
+	Duplicates int `json:"duplicates"`
+	// WithoutLanguage is the number of collected repositories dropped because they do not use the requested language
+	WithoutLanguage int `json:"without_language"`
+	// Windows is the number of created date windows crawled once bisected
+	Windows int `json:"windows"`
+	// IncompleteWindows are the windows that could not be fully crawled: more than 1000 results
+	// on a single day, or results GitHub flagged as incomplete
+	IncompleteWindows []string `json:"incomplete_windows"`
+	Complete          bool     `json:"complete"`
+}
+
+const (
+	ExportStatusPending = "pending"
+	ExportStatusRunning = "running"
+	ExportStatusDone    = "done"
+	ExportStatusFailed  = "failed"
+	// ExportStatusInterrupted is a job stopped by a restart, it waits for a token to resume
+	ExportStatusInterrupted = "interrupted"
+)
+
+// ExportJob is an export running in background
+type ExportJob struct {
+	ID        string         `json:"id"`
+	Status    string         `json:"status"`
+	Query     string         `json:"query"`
+	Progress  ExportProgress `json:"progress"`
+	Error     string         `json:"error,omitempty"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	// Params and Checkpoint are persisted to resume the job after a restart, without the Authorization header.
+	// They are never rendered.
+	Params     *RepositorySearchParams `json:"-"`
+	Checkpoint *ExportCheckpoint       `json:"-"`
+}
+
+// ExportProgress tells how far an export job is
+type ExportProgress struct {
+	WindowsDone    int `json:"windows_done"`
+	WindowsPending int `json:"windows_pending"`
+	Collected      int `json:"collected"`
+	Items          int `json:"items"`
+}
+
+// ExportCheckpoint is the state of an export saved after each crawled window, so it can resume from there
+type ExportCheckpoint struct {
+	// PendingWindows are the created date windows left to crawl
+	PendingWindows []string     `json:"pending_windows"`
+	Result         ExportResult `json:"result"`
+	// Seen are the repositories already collected, including the ones without the requested language
+	Seen []string `json:"seen"`
+	// Offset is the size of the windows log of the job once the checkpoint was taken,
+	// the items and seen repositories are saved there rather than with the job
+	Offset int64 `json:"offset"`
+}
+
+// ExportWindow is what a crawled window added to an export, appended to the windows log of the job
+type ExportWindow struct {
+	Window string       `json:"window"`
+	Items  []Repository `json:"items"`
+	Seen   []string     `json:"seen"`
+}
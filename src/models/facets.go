@@ -0,0 +1,62 @@
+package models
+
+// Facets are the names accepted by the facets parameter
+const (
+	FacetLicense       = "license"
+	FacetOwner         = "owner"
+	FacetOwnerType     = "owner_type"
+	FacetTopic         = "topic"
+	FacetStars         = "stars"
+	FacetSize          = "size"
+	FacetCreatedYear   = "created_year"
+	FacetLanguageBytes = "language_bytes"
+
+	// FacetAll requests every facet
+	FacetAll = "all"
+	// MaxFacetPages bounds the pages a facet computation may read
+	MaxFacetPages = 10
+)
+
+// FacetNames are the facets in the order they are computed
+var FacetNames = []string{
+	FacetLicense,
+	FacetOwner,
+	FacetOwnerType,
+	FacetTopic,
+	FacetStars,
+	FacetSize,
+	FacetCreatedYear,
+	FacetLanguageBytes,
+}
+
+// Facets are aggregations computed over the enriched repositories of one or several pages
+// Only the requested facets are set.
+type Facets struct {
+	// Repositories is the number of repositories the facets are computed over
+	Repositories int `json:"repositories"`
+	Pages        int `json:"pages"`
+
+	License     []FacetCount      `json:"license,omitempty"`
+	Owner       []FacetCount      `json:"owner,omitempty"`
+	OwnerType   []FacetCount      `json:"owner_type,omitempty"`
+	Topic       []FacetCount      `json:"topic,omitempty"`
+	Stars       []HistogramBucket `json:"stars,omitempty"`
+	Size        []HistogramBucket `json:"size,omitempty"`
+	CreatedYear []FacetCount      `json:"created_year,omitempty"`
+	// LanguageBytes sums the bytes of each returned language, use languages=all for the full breakdown
+	LanguageBytes map[string]int `json:"language_bytes,omitempty"`
+}
+
+// FacetCount is the number of repositories sharing a value, counts are sorted from the largest
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// HistogramBucket counts the repositories whose value is between From and To, both included
+// To is nil for the last bucket, which has no upper bound.
+type HistogramBucket struct {
+	From  int  `json:"from"`
+	To    *int `json:"to"`
+	Count int  `json:"count"`
+}
@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// RepositoryFields are the JSON names of the fields of a Repository, the ones a sparse selection can keep
+var RepositoryFields = jsonFieldNames(reflect.TypeOf(Repository{}))
+
+// jsonFieldNames lists the JSON names of the exported fields of a struct, in declaration order
+func jsonFieldNames(t reflect.Type) []string {
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// SelectFields returns a copy of the repository encoded with the given JSON fields only
+// Field names must be among RepositoryFields, an empty selection keeps every field.
+func (r Repository) SelectFields(fields []string) Repository {
+	r.fields = fields
+	return r
+}
+
+// MarshalJSON encodes the repository, keeping only its selected fields when a selection was made
+func (r Repository) MarshalJSON() ([]byte, error) {
+	// plain has the fields of Repository without its methods, so encoding it does not recurse
+	type plain Repository
+	data, err := json.Marshal(plain(r))
+	if err != nil || len(r.fields) == 0 {
+		return data, err
+	}
+
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+
+	sparse := make(map[string]json.RawMessage, len(r.fields))
+	for _, field := range r.fields {
+		if value, ok := all[field]; ok {
+			sparse[field] = value
+		}
+	}
+	return json.Marshal(sparse)
+}
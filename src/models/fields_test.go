@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositoryFields(t *testing.T) {
+	assert.Contains(t, RepositoryFields, "full_name")
+	assert.Contains(t, RepositoryFields, "stargazers_count")
+	assert.NotContains(t, RepositoryFields, "fields")
+}
+
+func TestRepositorySelectFields(t *testing.T) {
+	repo := Repository{
+		FullName:        "scalingo/scalingo-test",
+		StargazersCount: 42,
+		Topics:          []string{"paas"},
+		Owner:           Owner{Login: "scalingo"},
+	}
+
+	tests := map[string]struct {
+		fields []string
+		want   string
+	}{
+		"sparse": {
+			fields: []string{"stargazers_count", "full_name", "owner"},
+			want:   `{"full_name":"scalingo/scalingo-test","owner":{"login":"scalingo","id":0,"node_id":"","avatar_url":"","type":""},"stargazers_count":42}`,
+		},
+		"omitted field": {
+			fields: []string{"full_name", "license"},
+			want:   `{"full_name":"scalingo/scalingo-test"}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(repo.SelectFields(tt.fields))
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(data))
+		})
+	}
+
+	t.Run("no selection", func(t *testing.T) {
+		data, err := json.Marshal(repo)
+		assert.NoError(t, err)
+
+		var decoded Repository
+		assert.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, repo, decoded)
+	})
+}
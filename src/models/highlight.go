@@ -0,0 +1,23 @@
+package models
+
+// TextMatch is a fragment of a repository field matching the search terms
+// https://docs.github.com/en/rest/search/search?apiVersion=2022-11-28#text-match-metadata
+type TextMatch struct {
+	// Property is the field the fragment comes from, name or description
+	Property string      `json:"property"`
+	Fragment string      `json:"fragment"`
+	Matches  []TermMatch `json:"matches"`
+}
+
+// TermMatch is a search term found in a fragment
+type TermMatch struct {
+	Text string `json:"text"`
+	// Indices are the start and end offsets of the term in the fragment, the end excluded
+	Indices []int `json:"indices"`
+}
+
+// HighlightedProperties are the text match properties returned to clients
+var HighlightedProperties = map[string]bool{
+	"name":        true,
+	"description": true,
+}
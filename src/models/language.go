@@ -0,0 +1,75 @@
+package models
+
+// MaxShareLanguages bounds the languages compared by a single share request
+const MaxShareLanguages = 10
+
+// LanguageShareParams are the parameters of a language market share request
+type LanguageShareParams struct {
+	// Languages are the compared languages, a search is made for each of them
+	Languages []string
+	// Query restricts the counted repositories, it cannot contain a language qualifier
+	Query  string
+	Header string
+}
+
+// LanguageShare compares the number of repositories of several languages
+type LanguageShare struct {
+	Query string `json:"query"`
+	// Total is the sum of the counts of the compared languages, the share of each language is relative to it
+	Total     int             `json:"total"`
+	Languages []LanguageCount `json:"languages"`
+	// IncompleteResults is true when GitHub timed out on one of the searches, counts may then be too low
+	IncompleteResults bool `json:"incomplete_results"`
+}
+
+// LanguageCount is the number of repositories of a language
+type LanguageCount struct {
+	Language   string  `json:"language"`
+	Count      int     `json:"count"`
+	Percentage float64 `json:"percentage"`
+}
+
+const (
+	// TimeseriesByCreated counts the repositories created each month
+	TimeseriesByCreated = "created"
+	// TimeseriesByPushed counts the repositories whose last push happened each month
+	TimeseriesByPushed = "pushed"
+
+	// MaxTimeseriesMonths bounds the months of a series, each of them costs a search
+	MaxTimeseriesMonths = 120
+)
+
+// TimeseriesParams are the parameters of a language time series request
+type TimeseriesParams struct {
+	Language string
+	// From and To are the first and last months of the series, both included, with the YYYY-MM format
+	From string
+	To   string
+	// By is either TimeseriesByCreated or TimeseriesByPushed
+	By string
+	// Query restricts the counted repositories, it cannot contain a language qualifier or the By one
+	Query  string
+	Header string
+}
+
+// LanguageTimeseries is the number of repositories of a language month by month
+type LanguageTimeseries struct {
+	Language string `json:"language"`
+	Query    string `json:"query,omitempty"`
+	By       string `json:"by"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+	// Points has a point for each month of the period, in chronological order
+	Points []TimeseriesPoint `json:"points"`
+	// Complete is false when the GitHub rate limit stopped the run, the months not counted have a null count
+	Complete bool   `json:"complete"`
+	Error    string `json:"error,omitempty"`
+}
+
+// TimeseriesPoint is the number of repositories of a month
+type TimeseriesPoint struct {
+	Month string `json:"month"`
+	Count *int   `json:"count"`
+	// Incomplete is true when GitHub timed out while counting, the count may then be too low
+	Incomplete bool `json:"incomplete,omitempty"`
+}
@@ -1,23 +1,92 @@
 package models
 
+import (
+	"context"
+	"time"
+)
+
 // RepositorySearchResponse is the response from the GitHub API for the search repositories endpoint
-// We do not use all fields from the response, only few ones, but adding them would be straightforward
+// We do not use all fields from the response, only few ones, but adding them would be straightforward.
+// TotalCount, IncompleteResults and Items come from GitHub, the other fields are computed locally:
+// GitHubPages is read from the Link header of GitHub, Cursors are only used to build the Links.
 type RepositorySearchResponse struct {
 	TotalCount        int          `json:"total_count"`
 	Count             int          `json:"count"`
-	PerPage           string       `json:"per_page"`
-	Page              string       `json:"page"`
+	PerPage           int          `json:"per_page"`
+	Page              int          `json:"page"`
 	IncompleteResults bool         `json:"incomplete_results"`
 	Items             []Repository `json:"items"`
+	// Query is the q string compiled from a structured search
+	Query string `json:"query,omitempty"`
+	// Cost reports the GitHub API calls the request spent
+	Cost *RequestCost `json:"cost,omitempty"`
+	// Facets are the aggregations requested with the facets parameter
+	Facets *Facets `json:"facets,omitempty"`
+	// NextCursor is set in fill mode, it must be sent back to get the following repositories
+	NextCursor string `json:"next_cursor,omitempty"`
+	// Links are the URLs to navigate through the results, also sent in the Link header
+	Links *PageLinks `json:"links,omitempty"`
+	// Cursors are the cursors behind each of the Links
+	Cursors *PageLinks `json:"-"`
+	// GitHubPages are the page numbers of the relations found in the Link header of GitHub
+	GitHubPages map[string]int `json:"-"`
+}
+
+// PageLinks are the relations used to navigate through paginated results
+type PageLinks struct {
+	First string `json:"first,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last,omitempty"`
 }
 
 // Repository is a single repository from the GitHub API response
 type Repository struct {
-	FullName    string    `json:"full_name"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	Languages   Languages `json:"languages"`
-	Owner       Owner     `json:"owner"`
+	FullName      string         `json:"full_name"`
+	Name          string         `json:"name"`
+	Description   string         `json:"description"`
+	Languages     Languages      `json:"languages"`
+	LanguageStats *LanguageStats `json:"language_stats,omitempty"`
+	Owner         Owner          `json:"owner"`
+
+	HTMLURL         string `json:"html_url"`
+	StargazersCount int    `json:"stargazers_count"`
+	ForksCount      int    `json:"forks_count"`
+	// OpenIssuesCount counts the open issues and pull requests, like GitHub does
+	OpenIssuesCount int       `json:"open_issues_count"`
+	Size            int       `json:"size"`
+	Topics          []string  `json:"topics,omitempty"`
+	License         *License  `json:"license,omitempty"`
+	Visibility      string    `json:"visibility"`
+	Archived        bool      `json:"archived"`
+	Fork            bool      `json:"fork"`
+	DefaultBranch   string    `json:"default_branch"`
+	CreatedAt       time.Time `json:"created_at"`
+	PushedAt        time.Time `json:"pushed_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+	// TextMatches are the fragments of the name and description matching the search terms, only set when highlighting
+	TextMatches []TextMatch `json:"text_matches,omitempty"`
+
+	// LatestRelease, Issues, Community, Activity, Contributors, Dependencies and Manifests are only set when their enrichment is included
+	LatestRelease *Release          `json:"latest_release,omitempty"`
+	Issues        *IssueCounts      `json:"issues,omitempty"`
+	Community     *CommunityProfile `json:"community,omitempty"`
+	Activity      *Activity         `json:"activity,omitempty"`
+	Contributors  *Contributors     `json:"contributors,omitempty"`
+	Dependencies  []Dependency      `json:"dependencies,omitempty"`
+	Manifests     []Manifest        `json:"manifests,omitempty"`
+	// EnrichmentErrors are the included enrichments that failed for this repository
+	EnrichmentErrors []EnrichmentError `json:"enrichment_errors,omitempty"`
+
+	// fields are the fields kept when the repository is encoded, all of them when empty
+	fields []string
+}
+
+// License is the license GitHub detected in a repository
+type License struct {
+	Key    string `json:"key"`
+	Name   string `json:"name"`
+	SPDXID string `json:"spdx_id"`
 }
 
 // Owner is the owner of a repository
@@ -26,11 +95,46 @@ type Owner struct {
 	ID        int    `json:"id"`
 	NodeID    string `json:"node_id"`
 	AvatarURL string `json:"avatar_url"`
+	// Type is either User or Organization
+	Type string `json:"type"`
 }
 
 // Languages is a map of languages to their usage in a repository
 type Languages map[string]int
 
+// Tree lists the files of a repository, recursively
+type Tree struct {
+	Entries []TreeEntry `json:"tree"`
+	// Truncated is set when the tree has more entries than GitHub returns at once
+	Truncated bool `json:"truncated"`
+}
+
+// TreeEntry is a file or a directory of a tree, only blobs have a size
+type TreeEntry struct {
+	Path string `json:"path"`
+	Type string `json:"type"`
+	Size int    `json:"size"`
+}
+
+// LanguageStats describes the language breakdown of a repository and how the requested language ranks in it
+type LanguageStats struct {
+	TotalBytes     int                `json:"total_bytes"`
+	RequestedBytes int                `json:"requested_bytes"`
+	RequestedShare float64            `json:"requested_share"`
+	IsPrimary      bool               `json:"is_primary"`
+	Rank           int                `json:"rank"`
+	Percentages    map[string]float64 `json:"percentages"`
+	// Inferred is set when GitHub had no languages and they were estimated from the files of the repository
+	Inferred bool `json:"inferred,omitempty"`
+}
+
+const (
+	// LanguagesModeRequested keeps only the requested language in the response
+	LanguagesModeRequested = "requested"
+	// LanguagesModeAll keeps the full language breakdown of each repository
+	LanguagesModeAll = "all"
+)
+
 // RepositorySearchParams are the parameters for functions used to search repositories
 type RepositorySearchParams struct {
 	Query    string
@@ -38,4 +142,82 @@ type RepositorySearchParams struct {
 	Page     string
 	Header   string
 	Language string
+	// LanguagesMode is either LanguagesModeRequested or LanguagesModeAll
+	LanguagesMode string
+	Sort          string
+	Order         string
+	// Fill keeps fetching GitHub pages until PerPage repositories with the requested language are found
+	Fill bool
+	// Cursor is the opaque position returned by a previous filled page, it replaces Page
+	Cursor string
+	// MaxGitHubCalls is the budget of GitHub API calls of the request, 0 means no budget
+	MaxGitHubCalls int
+	// OnBudget is either BudgetRefuse (the default) or BudgetDegrade, it tells what to do when the budget is too small
+	OnBudget string
+	// DryRun only estimates the cost of the request, GitHub is not called
+	DryRun bool
+	// Facets are the facets computed over the results, FacetAll is expanded by the controller
+	Facets []string
+	// FacetPages is the number of pages, from Page, the facets are computed over
+	FacetPages int
+	// Include are the enrichments of the repositories, DefaultIncludes when nil
+	Include []string
+	// Highlight asks GitHub for the fragments of the repositories matching the search terms
+	Highlight bool
+	// Context is the context of the request, it cancels the polling of statistics when the client is gone.
+	// It is never saved with an export.
+	Context context.Context `json:"-"`
+}
+
+// SearchResultsLimit is the number of results GitHub search can return for a query
+// https://docs.github.com/en/rest/search/search?apiVersion=2022-11-28#about-search
+const SearchResultsLimit = 1000
+
+// GitHubSorts are the sort keys handled by the GitHub search API
+// https://docs.github.com/en/rest/search/search?apiVersion=2022-11-28#search-repositories--parameters
+var GitHubSorts = map[string]bool{
+	"stars":              true,
+	"forks":              true,
+	"help-wanted-issues": true,
+	"updated":            true,
+}
+
+// LocalSorts are the sort keys GitHub cannot provide, they are applied by us once repositories are enriched
+var LocalSorts = map[string]bool{
+	SortLanguageBytes: true,
+	SortLanguageShare: true,
+	SortActivity:      true,
+}
+
+// LocalSortIncludes are the enrichments each local sort is computed from
+var LocalSortIncludes = map[string]string{
+	SortLanguageBytes: IncludeLanguages,
+	SortLanguageShare: IncludeLanguages,
+	SortActivity:      IncludeActivity,
+}
+
+const (
+	// SortLanguageBytes sorts repositories by the number of bytes of the requested language
+	SortLanguageBytes = "language_bytes"
+	// SortLanguageShare sorts repositories by the share of the requested language in their code
+	SortLanguageShare = "language_share"
+	// SortActivity sorts repositories by their commits of the last 12 weeks
+	SortActivity = "activity"
+
+	OrderAsc  = "asc"
+	OrderDesc = "desc"
+)
+
+// QualifierDependsOn keeps the repositories depending on a package, it is evaluated by the API on their SBOM
+const QualifierDependsOn = "depends_on"
+
+// RepositoryParams are the parameters used to look up a single repository
+type RepositoryParams struct {
+	Owner  string
+	Name   string
+	Header string
+	// Language is the language the stats are computed for, the primary language of the repository when empty
+	Language string
+	// LanguagesMode is either LanguagesModeRequested or LanguagesModeAll
+	LanguagesMode string
 }
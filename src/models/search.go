@@ -0,0 +1,53 @@
+package models
+
+// StructuredSearch is a search described as a JSON document instead of a q string
+// Filters are compiled into the same qualifiers a q string would contain.
+type StructuredSearch struct {
+	// Text is the free text searched in names, descriptions and topics
+	Text string `json:"text"`
+	// Language must contain a single language, the one repositories are enriched for
+	Language  []string      `json:"language"`
+	License   string        `json:"license"`
+	Stars     *NumberFilter `json:"stars"`
+	Forks     *NumberFilter `json:"forks"`
+	Size      *NumberFilter `json:"size"`
+	Followers *NumberFilter `json:"followers"`
+	Topics    *NumberFilter `json:"topics"`
+	Created   *DateFilter   `json:"created"`
+	Pushed    *DateFilter   `json:"pushed"`
+
+	PerPage   int    `json:"per_page"`
+	Page      int    `json:"page"`
+	Languages string `json:"languages"`
+	Sort      string `json:"sort"`
+	Order     string `json:"order"`
+	Fill      bool   `json:"fill"`
+
+	MaxGitHubCalls int    `json:"max_github_calls"`
+	OnBudget       string `json:"on_budget"`
+	DryRun         bool   `json:"dry_run"`
+
+	Facets     []string `json:"facets"`
+	FacetPages int      `json:"facet_pages"`
+	// Include are the enrichments of the repositories, only the languages when empty
+	Include []string `json:"include"`
+	// Fields are the fields kept in each repository, all of them when empty
+	Fields []string `json:"fields"`
+	// Highlight returns the fragments of names and descriptions matching the text
+	Highlight bool `json:"highlight"`
+}
+
+// NumberFilter bounds a numeric qualifier, Eq cannot be combined with the other bounds
+type NumberFilter struct {
+	Eq  *int `json:"eq"`
+	Gt  *int `json:"gt"`
+	Gte *int `json:"gte"`
+	Lt  *int `json:"lt"`
+	Lte *int `json:"lte"`
+}
+
+// DateFilter bounds a date qualifier, both dates are included and use the YYYY-MM-DD format
+type DateFilter struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
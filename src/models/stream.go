@@ -0,0 +1,31 @@
+package models
+
+const (
+	// StreamEventMetadata is the first event of a stream, sent once the search is done
+	StreamEventMetadata = "metadata"
+	// StreamEventRepository is sent for each repository as soon as its languages are fetched
+	StreamEventRepository = "repository"
+	// StreamEventSummary is the last event of a stream
+	StreamEventSummary = "summary"
+)
+
+// StreamMetadata describes the searched page before its repositories are streamed
+type StreamMetadata struct {
+	TotalCount        int  `json:"total_count"`
+	PerPage           int  `json:"per_page"`
+	Page              int  `json:"page"`
+	IncompleteResults bool `json:"incomplete_results"`
+	// Pending is the number of repositories whose languages are being fetched
+	Pending int        `json:"pending"`
+	Links   *PageLinks `json:"links,omitempty"`
+	// Cursors are the cursors behind each of the Links
+	Cursors *PageLinks `json:"-"`
+}
+
+// StreamSummary closes a stream, Errors lists the repositories whose languages could not be fetched
+type StreamSummary struct {
+	Count   int      `json:"count"`
+	Dropped int      `json:"dropped"`
+	Failed  int      `json:"failed"`
+	Errors  []string `json:"errors"`
+}
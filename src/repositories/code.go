@@ -0,0 +1,26 @@
+package repositories
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// SearchCode searches files in the default branch of repositories
+// https://docs.github.com/en/rest/search/search?apiVersion=2022-11-28#search-code
+func (gr *githubRepository) SearchCode(params *models.CodeSearchParams) (*models.CodeSearchResponse, error) {
+	endpoint := fmt.Sprintf("%s/search/code?q=%s&per_page=%s&page=%s",
+		gr.baseURL,
+		url.QueryEscape(params.Query),
+		url.QueryEscape(params.PerPage),
+		url.QueryEscape(params.Page),
+	)
+
+	var result models.CodeSearchResponse
+	if _, err := gr.doRequest(endpoint, params.Header, &result); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchCode(t *testing.T) {
+	tests := map[string]struct {
+		mockResponse   string
+		mockStatusCode int
+		wantError      assert.ErrorAssertionFunc
+		want           *models.CodeSearchResponse
+	}{
+		"nominal": {
+			mockResponse: `{"total_count": 2, "incomplete_results": false, "items": [
+				{"name": "main.go", "path": "cmd/main.go", "sha": "abc", "html_url": "https://github.com/scalingo/cli/blob/abc/cmd/main.go", "repository": {"full_name": "scalingo/cli", "name": "cli"}}
+			]}`,
+			mockStatusCode: http.StatusOK,
+			wantError:      assert.NoError,
+			want: &models.CodeSearchResponse{
+				TotalCount: 2,
+				Items: []models.CodeSearchItem{{
+					CodeFile:   models.CodeFile{Name: "main.go", Path: "cmd/main.go", SHA: "abc", HTMLURL: "https://github.com/scalingo/cli/blob/abc/cmd/main.go"},
+					Repository: models.Repository{FullName: "scalingo/cli", Name: "cli"},
+				}},
+			},
+		},
+		"validation failed, return error": {
+			mockResponse:   `{"message": "Validation Failed"}`,
+			mockStatusCode: http.StatusUnprocessableEntity,
+			wantError:      assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/search/code", r.URL.Path)
+				assert.Equal(t, "handler language:go", r.URL.Query().Get("q"))
+				assert.Equal(t, "10", r.URL.Query().Get("per_page"))
+				assert.Equal(t, "2", r.URL.Query().Get("page"))
+				w.WriteHeader(tt.mockStatusCode)
+				fmt.Fprintln(w, tt.mockResponse)
+			}))
+			defer server.Close()
+			gr := &githubRepository{baseURL: server.URL, httpClient: server.Client()}
+
+			result, err := gr.SearchCode(&models.CodeSearchParams{Query: "handler language:go", PerPage: "10", Page: "2"})
+			tt.wantError(t, err)
+			assert.Equal(t, tt.want, result)
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// fileContents is a file of the contents API
+type fileContents struct {
+	Type     string `json:"type"`
+	Encoding string `json:"encoding"`
+	Content  string `json:"content"`
+}
+
+// GetFileContents fetches a file of the default branch of a repository, ErrNotFound is returned when it does not exist
+// https://docs.github.com/en/rest/repos/contents?apiVersion=2022-11-28#get-repository-content
+func (gr *githubRepository) GetFileContents(repoFullName, path, header string) ([]byte, error) {
+	repoURL, err := gr.fullNameURL(repoFullName)
+	if err != nil {
+		return nil, err
+	}
+
+	var file fileContents
+	if _, err := gr.doRequest(repoURL+"/contents/"+escapePath(path), header, &file); err != nil {
+		return nil, err
+	}
+
+	// Symlinks and submodules have another type, files over 1 MB come without content
+	if file.Type != "file" {
+		return nil, fmt.Errorf("%s is not a file", path)
+	}
+	if file.Encoding != "base64" {
+		return nil, fmt.Errorf("%s is too large to be fetched", path)
+	}
+
+	content, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(file.Content, "\n", ""))
+	if err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", path, err)
+	}
+
+	return content, nil
+}
+
+// escapePath escapes each segment of a slash separated path, like file paths and branch names
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetFileContents(t *testing.T) {
+	tests := map[string]struct {
+		path           string
+		wantPath       string
+		mockResponse   string
+		mockStatusCode int
+		wantError      assert.ErrorAssertionFunc
+		wantContent    []byte
+	}{
+		"nominal": {
+			path:           "go.mod",
+			wantPath:       "/repos/scalingo/cli/contents/go.mod",
+			mockResponse:   `{"type": "file", "encoding": "base64", "content": "bW9kdWxlIGdp\ndGh1Yi5jb20v\nU2NhbGluZ28v\nY2xp\n"}`,
+			mockStatusCode: http.StatusOK,
+			wantError:      assert.NoError,
+			wantContent:    []byte("module github.com/Scalingo/cli"),
+		},
+		"nested path, segments escaped": {
+			path:           "web app/package.json",
+			wantPath:       "/repos/scalingo/cli/contents/web%20app/package.json",
+			mockResponse:   `{"type": "file", "encoding": "base64", "content": "e30="}`,
+			mockStatusCode: http.StatusOK,
+			wantError:      assert.NoError,
+			wantContent:    []byte("{}"),
+		},
+		"not found, return ErrNotFound": {
+			path:           "go.mod",
+			wantPath:       "/repos/scalingo/cli/contents/go.mod",
+			mockResponse:   `{"message": "Not Found"}`,
+			mockStatusCode: http.StatusNotFound,
+			wantError: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrNotFound)
+			},
+		},
+		"directory, return error": {
+			path:           "go.mod",
+			wantPath:       "/repos/scalingo/cli/contents/go.mod",
+			mockResponse:   `[{"type": "file", "name": "main.go"}]`,
+			mockStatusCode: http.StatusOK,
+			wantError:      assert.Error,
+		},
+		"symlink, return error": {
+			path:           "go.mod",
+			wantPath:       "/repos/scalingo/cli/contents/go.mod",
+			mockResponse:   `{"type": "symlink", "target": "src/go.mod"}`,
+			mockStatusCode: http.StatusOK,
+			wantError:      assert.Error,
+		},
+		"too large, return error": {
+			path:           "go.mod",
+			wantPath:       "/repos/scalingo/cli/contents/go.mod",
+			mockResponse:   `{"type": "file", "encoding": "none", "content": ""}`,
+			mockStatusCode: http.StatusOK,
+			wantError:      assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gr := enrichmentServer(t, tt.wantPath, "", tt.mockStatusCode, tt.mockResponse)
+			content, err := gr.GetFileContents("scalingo/cli", tt.path, "")
+			tt.wantError(t, err)
+			assert.Equal(t, tt.wantContent, content)
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// GetTopics fetches the topics of a repository
+// https://docs.github.com/en/rest/repos/repos?apiVersion=2022-11-28#get-all-repository-topics
+func (gr *githubRepository) GetTopics(repoFullName, header string) ([]string, error) {
+	repoURL, err := gr.fullNameURL(repoFullName)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Names []string `json:"names"`
+	}
+	if _, err := gr.doRequest(repoURL+"/topics", header, &result); err != nil {
+		return nil, err
+	}
+
+	if result.Names == nil {
+		return []string{}, nil
+	}
+	return result.Names, nil
+}
+
+// GetLatestRelease fetches the latest published release of a repository, nil when it has none
+// https://docs.github.com/en/rest/releases/releases?apiVersion=2022-11-28#get-the-latest-release
+func (gr *githubRepository) GetLatestRelease(repoFullName, header string) (*models.Release, error) {
+	repoURL, err := gr.fullNameURL(repoFullName)
+	if err != nil {
+		return nil, err
+	}
+
+	var release models.Release
+	if _, err := gr.doRequest(repoURL+"/releases/latest", header, &release); err != nil {
+		// GitHub answers 404 for repositories without release
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &release, nil
+}
+
+// CountIssues returns the number of issues and pull requests matching the query, without fetching them
+// https://docs.github.com/en/rest/search/search?apiVersion=2022-11-28#search-issues-and-pull-requests
+func (gr *githubRepository) CountIssues(query, header string) (int, error) {
+	endpoint := fmt.Sprintf("%s/search/issues?q=%s&per_page=1", gr.baseURL, url.QueryEscape(query))
+
+	var result struct {
+		TotalCount int `json:"total_count"`
+	}
+	if _, err := gr.doRequest(endpoint, header, &result); err != nil {
+		return 0, err
+	}
+
+	return result.TotalCount, nil
+}
+
+// GetCommunityProfile fetches the community health of a repository
+// https://docs.github.com/en/rest/metrics/community?apiVersion=2022-11-28#get-community-profile-metrics
+func (gr *githubRepository) GetCommunityProfile(repoFullName, header string) (*models.CommunityProfile, error) {
+	repoURL, err := gr.fullNameURL(repoFullName)
+	if err != nil {
+		return nil, err
+	}
+
+	// Missing community files are null
+	var result struct {
+		HealthPercentage int                    `json:"health_percentage"`
+		Files            map[string]interface{} `json:"files"`
+	}
+	if _, err := gr.doRequest(repoURL+"/community/profile", header, &result); err != nil {
+		return nil, err
+	}
+
+	files := make([]string, 0, len(result.Files))
+	for file, value := range result.Files {
+		if value != nil {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+
+	return &models.CommunityProfile{HealthPercentage: result.HealthPercentage, Files: files}, nil
+}
+
+// GetContributorCount counts the contributors of a repository, anonymous ones included
+// A single contributor is requested per page, so the last page of the Link header is the number of contributors.
+// https://docs.github.com/en/rest/repos/repos?apiVersion=2022-11-28#list-repository-contributors
+func (gr *githubRepository) GetContributorCount(repoFullName, header string) (int, error) {
+	repoURL, err := gr.fullNameURL(repoFullName)
+	if err != nil {
+		return 0, err
+	}
+
+	var contributors []struct{}
+	headers, err := gr.doRequest(repoURL+"/contributors?per_page=1&anon=1", header, &contributors)
+	if err != nil {
+		return 0, err
+	}
+
+	// Without Link header, everything fits in the page: zero or one contributor
+	if last, ok := parseLinkHeader(headers.Get("Link"))["last"]; ok {
+		return last, nil
+	}
+	return len(contributors), nil
+}
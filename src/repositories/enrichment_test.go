@@ -0,0 +1,194 @@
+package repositories
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+// enrichmentServer answers every request with the given status and body, after checking its path and query
+func enrichmentServer(t *testing.T, wantPath, wantQuery string, status int, body string) *githubRepository {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, wantPath, r.URL.EscapedPath())
+		assert.Equal(t, wantQuery, r.URL.RawQuery)
+		w.WriteHeader(status)
+		fmt.Fprintln(w, body)
+	}))
+	t.Cleanup(server.Close)
+
+	return &githubRepository{baseURL: server.URL, httpClient: server.Client()}
+}
+
+func TestGetTopics(t *testing.T) {
+	tests := map[string]struct {
+		mockResponse   string
+		mockStatusCode int
+		wantError      assert.ErrorAssertionFunc
+		wantTopics     []string
+	}{
+		"nominal": {
+			mockResponse:   `{"names": ["paas", "cli"]}`,
+			mockStatusCode: http.StatusOK,
+			wantError:      assert.NoError,
+			wantTopics:     []string{"paas", "cli"},
+		},
+		"no topics": {
+			mockResponse:   `{"names": null}`,
+			mockStatusCode: http.StatusOK,
+			wantError:      assert.NoError,
+			wantTopics:     []string{},
+		},
+		"api error": {
+			mockResponse:   `{"message": "Server Error"}`,
+			mockStatusCode: http.StatusInternalServerError,
+			wantError:      assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gr := enrichmentServer(t, "/repos/scalingo/cli/topics", "", tt.mockStatusCode, tt.mockResponse)
+			topics, err := gr.GetTopics("scalingo/cli", "")
+			tt.wantError(t, err)
+			assert.Equal(t, tt.wantTopics, topics)
+		})
+	}
+}
+
+func TestGetLatestRelease(t *testing.T) {
+	tests := map[string]struct {
+		mockResponse   string
+		mockStatusCode int
+		wantError      assert.ErrorAssertionFunc
+		wantRelease    *models.Release
+	}{
+		"nominal": {
+			mockResponse:   `{"tag_name": "v1.0.0", "name": "First", "html_url": "https://github.com/scalingo/cli/releases/v1.0.0", "published_at": "2024-01-02T03:04:05Z"}`,
+			mockStatusCode: http.StatusOK,
+			wantError:      assert.NoError,
+			wantRelease: &models.Release{
+				TagName:     "v1.0.0",
+				Name:        "First",
+				HTMLURL:     "https://github.com/scalingo/cli/releases/v1.0.0",
+				PublishedAt: time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+			},
+		},
+		"no release": {
+			mockResponse:   `{"message": "Not Found"}`,
+			mockStatusCode: http.StatusNotFound,
+			wantError:      assert.NoError,
+		},
+		"api error": {
+			mockResponse:   `{"message": "Server Error"}`,
+			mockStatusCode: http.StatusInternalServerError,
+			wantError:      assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gr := enrichmentServer(t, "/repos/scalingo/cli/releases/latest", "", tt.mockStatusCode, tt.mockResponse)
+			release, err := gr.GetLatestRelease("scalingo/cli", "")
+			tt.wantError(t, err)
+			assert.Equal(t, tt.wantRelease, release)
+		})
+	}
+}
+
+func TestCountIssues(t *testing.T) {
+	gr := enrichmentServer(t, "/search/issues", "q=repo%3Ascalingo%2Fcli+is%3Aissue&per_page=1", http.StatusOK, `{"total_count": 42, "items": [{}]}`)
+	count, err := gr.CountIssues("repo:scalingo/cli is:issue", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 42, count)
+}
+
+func TestGetCommunityProfile(t *testing.T) {
+	tests := map[string]struct {
+		fullName       string
+		mockResponse   string
+		mockStatusCode int
+		wantError      assert.ErrorAssertionFunc
+		wantProfile    *models.CommunityProfile
+	}{
+		"nominal": {
+			fullName:       "scalingo/cli",
+			mockResponse:   `{"health_percentage": 71, "files": {"readme": {"url": "x"}, "license": {"key": "mit"}, "contributing": null, "code_of_conduct": null}}`,
+			mockStatusCode: http.StatusOK,
+			wantError:      assert.NoError,
+			wantProfile:    &models.CommunityProfile{HealthPercentage: 71, Files: []string{"license", "readme"}},
+		},
+		"invalid full name": {
+			fullName:  "scalingo",
+			wantError: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gr := enrichmentServer(t, "/repos/scalingo/cli/community/profile", "", tt.mockStatusCode, tt.mockResponse)
+			profile, err := gr.GetCommunityProfile(tt.fullName, "")
+			tt.wantError(t, err)
+			assert.Equal(t, tt.wantProfile, profile)
+		})
+	}
+}
+
+func TestGetContributorCount(t *testing.T) {
+	tests := map[string]struct {
+		mockLink       string
+		mockResponse   string
+		mockStatusCode int
+		wantError      assert.ErrorAssertionFunc
+		wantCount      int
+	}{
+		"last page of the Link header": {
+			mockLink:       `<https://api.github.com/repositories/1/contributors?per_page=1&anon=1&page=2>; rel="next", <https://api.github.com/repositories/1/contributors?per_page=1&anon=1&page=347>; rel="last"`,
+			mockResponse:   `[{"login": "a"}]`,
+			mockStatusCode: http.StatusOK,
+			wantError:      assert.NoError,
+			wantCount:      347,
+		},
+		"single contributor": {
+			mockResponse:   `[{"login": "a"}]`,
+			mockStatusCode: http.StatusOK,
+			wantError:      assert.NoError,
+			wantCount:      1,
+		},
+		"empty repository": {
+			mockStatusCode: http.StatusNoContent,
+			wantError:      assert.NoError,
+		},
+		"too large to list": {
+			mockResponse:   `{"message": "The history or contributor list is too large to list contributors for this repository via the API."}`,
+			mockStatusCode: http.StatusForbidden,
+			wantError: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrTooLarge)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/repos/scalingo/cli/contributors", r.URL.Path)
+				assert.Equal(t, "per_page=1&anon=1", r.URL.RawQuery)
+				if tt.mockLink != "" {
+					w.Header().Set("Link", tt.mockLink)
+				}
+				w.WriteHeader(tt.mockStatusCode)
+				fmt.Fprint(w, tt.mockResponse)
+			}))
+			defer server.Close()
+
+			gr := &githubRepository{baseURL: server.URL, httpClient: server.Client()}
+			count, err := gr.GetContributorCount("scalingo/cli", "")
+			tt.wantError(t, err)
+			assert.Equal(t, tt.wantCount, count)
+		})
+	}
+}
@@ -0,0 +1,286 @@
+package repositories
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// ErrExportNotFound is returned when no export is stored with the given id
+var ErrExportNotFound = errors.New("export not found")
+
+// ExportStore persists export jobs and their results so they survive a restart
+type ExportStore interface {
+	SaveJob(job *models.ExportJob) error
+	ListJobs() ([]*models.ExportJob, error)
+	AppendWindow(id string, offset int64, window *models.ExportWindow) (int64, error)
+	SaveResult(id string, result *models.ExportResult) error
+	LoadResult(id string) (*models.ExportResult, error)
+}
+
+type fileExportStore struct {
+	dir string
+}
+
+// NewExportStore creates a store keeping each job and each result in a JSON file of dir
+func NewExportStore(dir string) (ExportStore, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("error creating exports directory: %w", err)
+	}
+
+	return &fileExportStore{
+		dir: dir,
+	}, nil
+}
+
+// exportRecord is the content of a job file, the fields hidden from clients are needed to resume the job
+type exportRecord struct {
+	Job        *models.ExportJob              `json:"job"`
+	Params     *models.RepositorySearchParams `json:"params"`
+	Checkpoint *checkpointRecord              `json:"checkpoint,omitempty"`
+}
+
+// checkpointRecord is the checkpoint saved with the job, its items and seen repositories are in the windows log
+// so that saving the job after each window does not rewrite everything collected so far
+type checkpointRecord struct {
+	PendingWindows []string              `json:"pending_windows"`
+	Query          string                `json:"query"`
+	Coverage       models.ExportCoverage `json:"coverage"`
+	Offset         int64                 `json:"offset"`
+}
+
+const (
+	jobSuffix     = ".job.json"
+	windowsSuffix = ".windows.ndjson"
+	resultSuffix  = ".result.json"
+)
+
+// path returns the file of an export, ids are generated by us but are checked to never leave the directory
+func (fs *fileExportStore) path(id, suffix string) (string, error) {
+	if id == "" || filepath.Base(id) != id || strings.HasPrefix(id, ".") {
+		return "", ErrExportNotFound
+	}
+	return filepath.Join(fs.dir, id+suffix), nil
+}
+
+// writeFile writes the whole file or nothing, a crash never leaves a truncated job behind
+func (fs *fileExportStore) writeFile(path string, v interface{}) error {
+	tmp, err := os.CreateTemp(fs.dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("error creating export file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := json.NewEncoder(tmp).Encode(v); err != nil {
+		tmp.Close()
+		return fmt.Errorf("error writing export file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("error writing export file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("error writing export file: %w", err)
+	}
+
+	return nil
+}
+
+func (fs *fileExportStore) SaveJob(job *models.ExportJob) error {
+	path, err := fs.path(job.ID, jobSuffix)
+	if err != nil {
+		return err
+	}
+
+	// The token is never written to disk, a resumed job gets a new one
+	var params *models.RepositorySearchParams
+	if job.Params != nil {
+		p := *job.Params
+		p.Header = ""
+		params = &p
+	}
+
+	record := exportRecord{
+		Job:    job,
+		Params: params,
+	}
+	if cp := job.Checkpoint; cp != nil {
+		record.Checkpoint = &checkpointRecord{
+			PendingWindows: cp.PendingWindows,
+			Query:          cp.Result.Query,
+			Coverage:       cp.Result.Coverage,
+			Offset:         cp.Offset,
+		}
+	}
+
+	return fs.writeFile(path, record)
+}
+
+func (fs *fileExportStore) ListJobs() ([]*models.ExportJob, error) {
+	paths, err := filepath.Glob(filepath.Join(fs.dir, "*"+jobSuffix))
+	if err != nil {
+		return nil, fmt.Errorf("error listing exports: %w", err)
+	}
+
+	jobs := make([]*models.ExportJob, 0, len(paths))
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading export %s: %w", path, err)
+		}
+
+		var record exportRecord
+		if err := json.Unmarshal(content, &record); err != nil {
+			return nil, fmt.Errorf("error decoding export %s: %w", path, err)
+		}
+		if record.Job == nil {
+			return nil, fmt.Errorf("error decoding export %s: no job", path)
+		}
+
+		record.Job.Params = record.Params
+		if record.Checkpoint != nil {
+			checkpoint, err := fs.loadCheckpoint(record.Job.ID, record.Checkpoint)
+			if err != nil {
+				return nil, err
+			}
+			record.Job.Checkpoint = checkpoint
+		}
+		jobs = append(jobs, record.Job)
+	}
+
+	return jobs, nil
+}
+
+// loadCheckpoint rebuilds a checkpoint from its record and the windows logged before it was taken
+// Windows appended after the checkpoint, by a run stopped before saving its job, are ignored.
+func (fs *fileExportStore) loadCheckpoint(id string, record *checkpointRecord) (*models.ExportCheckpoint, error) {
+	checkpoint := &models.ExportCheckpoint{
+		PendingWindows: record.PendingWindows,
+		Result: models.ExportResult{
+			Query:    record.Query,
+			Coverage: record.Coverage,
+			Items:    []models.Repository{},
+		},
+		Seen:   []string{},
+		Offset: record.Offset,
+	}
+	if record.Offset == 0 {
+		return checkpoint, nil
+	}
+
+	path, err := fs.path(id, windowsSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading export windows %s: %w", path, err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(io.LimitReader(file, record.Offset))
+	for {
+		var window models.ExportWindow
+		err := decoder.Decode(&window)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error decoding export windows %s: %w", path, err)
+		}
+		checkpoint.Result.Items = append(checkpoint.Result.Items, window.Items...)
+		checkpoint.Seen = append(checkpoint.Seen, window.Seen...)
+	}
+
+	return checkpoint, nil
+}
+
+// AppendWindow adds a window to the log of the job after offset, the end of the log when the job was last saved,
+// and returns the new end of the log. Anything written after offset by an interrupted run is dropped first.
+func (fs *fileExportStore) AppendWindow(id string, offset int64, window *models.ExportWindow) (int64, error) {
+	path, err := fs.path(id, windowsSuffix)
+	if err != nil {
+		return 0, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("error opening export windows: %w", err)
+	}
+	defer file.Close()
+
+	if err := file.Truncate(offset); err != nil {
+		return 0, fmt.Errorf("error truncating export windows: %w", err)
+	}
+	if _, err := file.Seek(offset, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("error seeking export windows: %w", err)
+	}
+
+	content, err := json.Marshal(window)
+	if err != nil {
+		return 0, fmt.Errorf("error encoding export window: %w", err)
+	}
+	content = append(content, '\n')
+
+	if _, err := file.Write(content); err != nil {
+		return 0, fmt.Errorf("error writing export window: %w", err)
+	}
+	// The job saved next points after this window, it must be on disk first
+	if err := file.Sync(); err != nil {
+		return 0, fmt.Errorf("error writing export window: %w", err)
+	}
+
+	return offset + int64(len(content)), nil
+}
+
+// SaveResult writes the result of a finished job, its windows log is no longer needed
+func (fs *fileExportStore) SaveResult(id string, result *models.ExportResult) error {
+	path, err := fs.path(id, resultSuffix)
+	if err != nil {
+		return err
+	}
+
+	if err := fs.writeFile(path, result); err != nil {
+		return err
+	}
+
+	windows, err := fs.path(id, windowsSuffix)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(windows); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("error removing export windows: %w", err)
+	}
+
+	return nil
+}
+
+func (fs *fileExportStore) LoadResult(id string) (*models.ExportResult, error) {
+	path, err := fs.path(id, resultSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrExportNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading export result: %w", err)
+	}
+
+	var result models.ExportResult
+	if err := json.Unmarshal(content, &result); err != nil {
+		return nil, fmt.Errorf("error decoding export result: %w", err)
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,159 @@
+package repositories
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewExportStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "exports")
+
+	store, err := NewExportStore(dir)
+	assert.NoError(t, err)
+	assert.NotNil(t, store)
+	assert.DirExists(t, dir)
+}
+
+func TestExportStoreJobs(t *testing.T) {
+	store, err := NewExportStore(t.TempDir())
+	assert.NoError(t, err)
+
+	job := &models.ExportJob{
+		ID:     "abc123",
+		Status: models.ExportStatusPending,
+		Query:  "language:go",
+		Params: &models.RepositorySearchParams{Query: "language:go", Header: "Bearer token"},
+	}
+	assert.NoError(t, store.SaveJob(job))
+
+	job.Status = models.ExportStatusRunning
+	assert.NoError(t, store.SaveJob(job))
+
+	jobs, err := store.ListJobs()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, models.ExportStatusRunning, jobs[0].Status)
+	// The token is never written to disk
+	assert.Equal(t, &models.RepositorySearchParams{Query: "language:go"}, jobs[0].Params)
+	assert.Equal(t, "Bearer token", job.Params.Header)
+	assert.Nil(t, jobs[0].Checkpoint)
+}
+
+func TestExportStoreWindows(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewExportStore(dir)
+	assert.NoError(t, err)
+
+	checkpoint := &models.ExportCheckpoint{
+		PendingWindows: []string{"2024-01-03"},
+		Result: models.ExportResult{
+			Query:    "language:go",
+			Coverage: models.ExportCoverage{Windows: 2, IncompleteWindows: []string{}},
+		},
+	}
+	job := &models.ExportJob{ID: "abc123", Status: models.ExportStatusRunning, Checkpoint: checkpoint}
+
+	offset, err := store.AppendWindow("abc123", 0, &models.ExportWindow{
+		Window: "2024-01-01",
+		Items:  []models.Repository{{FullName: "scalingo/a"}},
+		Seen:   []string{"scalingo/a", "scalingo/b"},
+	})
+	assert.NoError(t, err)
+	offset, err = store.AppendWindow("abc123", offset, &models.ExportWindow{
+		Window: "2024-01-02",
+		Items:  []models.Repository{{FullName: "scalingo/c"}},
+		Seen:   []string{"scalingo/c"},
+	})
+	assert.NoError(t, err)
+	checkpoint.Offset = offset
+	assert.NoError(t, store.SaveJob(job))
+
+	// The job file only keeps the windows left and the offset
+	content, err := os.ReadFile(filepath.Join(dir, "abc123"+jobSuffix))
+	assert.NoError(t, err)
+	assert.NotContains(t, string(content), "scalingo/a")
+
+	// A window appended by a run stopped before saving its job is not part of the checkpoint
+	_, err = store.AppendWindow("abc123", offset, &models.ExportWindow{
+		Window: "2024-01-03",
+		Items:  []models.Repository{{FullName: "scalingo/d"}},
+		Seen:   []string{"scalingo/d"},
+	})
+	assert.NoError(t, err)
+
+	jobs, err := store.ListJobs()
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, &models.ExportCheckpoint{
+		PendingWindows: []string{"2024-01-03"},
+		Result: models.ExportResult{
+			Query:    "language:go",
+			Coverage: models.ExportCoverage{Windows: 2, IncompleteWindows: []string{}},
+			Items:    []models.Repository{{FullName: "scalingo/a"}, {FullName: "scalingo/c"}},
+		},
+		Seen:   []string{"scalingo/a", "scalingo/b", "scalingo/c"},
+		Offset: offset,
+	}, jobs[0].Checkpoint)
+
+	// The resumed run writes over the window appended after the checkpoint
+	resumed, err := store.AppendWindow("abc123", offset, &models.ExportWindow{Window: "2024-01-03", Items: []models.Repository{}, Seen: []string{}})
+	assert.NoError(t, err)
+	info, err := os.Stat(filepath.Join(dir, "abc123"+windowsSuffix))
+	assert.NoError(t, err)
+	assert.Equal(t, resumed, info.Size())
+
+	// The log is removed with the result
+	assert.NoError(t, store.SaveResult("abc123", &models.ExportResult{Query: "language:go"}))
+	assert.NoFileExists(t, filepath.Join(dir, "abc123"+windowsSuffix))
+}
+
+func TestExportStoreListJobsInvalidFile(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewExportStore(dir)
+	assert.NoError(t, err)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "broken"+jobSuffix), []byte("{invalid json}"), 0o600))
+
+	_, err = store.ListJobs()
+	assert.Error(t, err)
+}
+
+func TestExportStoreResults(t *testing.T) {
+	store, err := NewExportStore(t.TempDir())
+	assert.NoError(t, err)
+
+	result := &models.ExportResult{
+		Query: "language:go",
+		Items: []models.Repository{{FullName: "scalingo/scalingo-test"}},
+		Coverage: models.ExportCoverage{
+			Collected: 1,
+			Complete:  true,
+		},
+	}
+	assert.NoError(t, store.SaveResult("abc123", result))
+
+	loaded, err := store.LoadResult("abc123")
+	assert.NoError(t, err)
+	assert.Equal(t, result.Items, loaded.Items)
+	assert.Equal(t, result.Coverage.Collected, loaded.Coverage.Collected)
+
+	_, err = store.LoadResult("unknown")
+	assert.ErrorIs(t, err, ErrExportNotFound)
+}
+
+func TestExportStorePath(t *testing.T) {
+	fs := &fileExportStore{dir: "/exports"}
+
+	path, err := fs.path("abc123", resultSuffix)
+	assert.NoError(t, err)
+	assert.Equal(t, "/exports/abc123.result.json", path)
+
+	for _, id := range []string{"", "../abc", "a/b", ".hidden"} {
+		_, err := fs.path(id, jobSuffix)
+		assert.ErrorIs(t, err, ErrExportNotFound, id)
+	}
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import "github.com/Scalingo/sclng-backend-test-v1/src/models"
+
+// highlights keeps the text matches of the highlighted properties, and their matches whose offsets fit in the fragment
+// The other properties, like topics, match the search terms too but cannot be highlighted in a name or description.
+func highlights(textMatches []models.TextMatch) []models.TextMatch {
+	if textMatches == nil {
+		return nil
+	}
+
+	kept := make([]models.TextMatch, 0, len(textMatches))
+	for _, textMatch := range textMatches {
+		if !models.HighlightedProperties[textMatch.Property] {
+			continue
+		}
+
+		matches := make([]models.TermMatch, 0, len(textMatch.Matches))
+		for _, match := range textMatch.Matches {
+			if len(match.Indices) == 2 && 0 <= match.Indices[0] && match.Indices[0] < match.Indices[1] && match.Indices[1] <= len(textMatch.Fragment) {
+				matches = append(matches, match)
+			}
+		}
+		if len(matches) > 0 {
+			textMatch.Matches = matches
+			kept = append(kept, textMatch)
+		}
+	}
+
+	return kept
+}
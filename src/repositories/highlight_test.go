@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchRepositoriesHighlight(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/vnd.github.text-match+json", r.Header.Get("Accept"))
+		fmt.Fprintln(w, `{"total_count": 1, "items": [{"full_name": "scalingo/cli", "text_matches": [
+			{"object_type": "Repository", "property": "description", "fragment": "Scalingo command line", "matches": [{"text": "Scalingo", "indices": [0, 8]}]},
+			{"object_type": "Repository", "property": "topics", "fragment": "paas", "matches": [{"text": "paas", "indices": [0, 4]}]}
+		]}]}`)
+	}))
+	defer server.Close()
+	gr := &githubRepository{baseURL: server.URL, httpClient: server.Client()}
+
+	result, err := gr.SearchRepositories(&models.RepositorySearchParams{Query: "scalingo", Highlight: true})
+	assert.NoError(t, err)
+	assert.Equal(t, []models.TextMatch{{
+		Property: "description",
+		Fragment: "Scalingo command line",
+		Matches:  []models.TermMatch{{Text: "Scalingo", Indices: []int{0, 8}}},
+	}}, result.Items[0].TextMatches)
+}
+
+func TestHighlights(t *testing.T) {
+	tests := map[string]struct {
+		textMatches []models.TextMatch
+		want        []models.TextMatch
+	}{
+		"not highlighted": {},
+		"name and description kept": {
+			textMatches: []models.TextMatch{
+				{Property: "name", Fragment: "cli", Matches: []models.TermMatch{{Text: "cli", Indices: []int{0, 3}}}},
+				{Property: "description", Fragment: "the cli", Matches: []models.TermMatch{{Text: "cli", Indices: []int{4, 7}}}},
+				{Property: "topics", Fragment: "cli", Matches: []models.TermMatch{{Text: "cli", Indices: []int{0, 3}}}},
+			},
+			want: []models.TextMatch{
+				{Property: "name", Fragment: "cli", Matches: []models.TermMatch{{Text: "cli", Indices: []int{0, 3}}}},
+				{Property: "description", Fragment: "the cli", Matches: []models.TermMatch{{Text: "cli", Indices: []int{4, 7}}}},
+			},
+		},
+		"invalid offsets dropped": {
+			textMatches: []models.TextMatch{
+				{Property: "description", Fragment: "the cli", Matches: []models.TermMatch{
+					{Text: "cli", Indices: []int{4, 7}},
+					{Text: "cli", Indices: []int{4, 12}},
+					{Text: "cli", Indices: []int{4}},
+				}},
+				{Property: "name", Fragment: "cli", Matches: []models.TermMatch{{Text: "cli", Indices: []int{3, 0}}}},
+			},
+			want: []models.TextMatch{
+				{Property: "description", Fragment: "the cli", Matches: []models.TermMatch{{Text: "cli", Indices: []int{4, 7}}}},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, highlights(tt.textMatches))
+		})
+	}
+}
@@ -2,18 +2,42 @@ package repositories
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/Scalingo/sclng-backend-test-v1/src/models"
 )
 
 type GitHubRepository interface {
 	SearchRepositories(rsp *models.RepositorySearchParams) (*models.RepositorySearchResponse, error)
+	SearchURL(rsp *models.RepositorySearchParams) string
 	GetLanguages(repoFullName, header string) (models.Languages, error)
+	GetRepository(owner, name, header string) (*models.Repository, error)
+	GetTopics(repoFullName, header string) ([]string, error)
+	GetLatestRelease(repoFullName, header string) (*models.Release, error)
+	CountIssues(query, header string) (int, error)
+	GetCommunityProfile(repoFullName, header string) (*models.CommunityProfile, error)
+	GetCommitActivity(repoFullName, header string) ([]models.WeeklyCommits, error)
+	GetParticipation(repoFullName, header string) (*models.Participation, error)
+	GetContributorCount(repoFullName, header string) (int, error)
+	GetSBOM(repoFullName, header string) ([]models.Dependency, error)
+	GetFileContents(repoFullName, path, header string) ([]byte, error)
+	GetTree(repoFullName, ref, header string) (*models.Tree, error)
+	SearchCode(params *models.CodeSearchParams) (*models.CodeSearchResponse, error)
 }
 
+const (
+	// mediaTypeJSON is the media type recommended by GitHub
+	mediaTypeJSON = "application/vnd.github+json"
+	// mediaTypeTextMatch adds the text_matches of the search terms to search results
+	// https://docs.github.com/en/rest/search/search?apiVersion=2022-11-28#text-match-metadata
+	mediaTypeTextMatch = "application/vnd.github.text-match+json"
+)
+
 type githubRepository struct {
 	baseURL    string
 	httpClient *http.Client
@@ -30,41 +54,89 @@ type GitHubErrorResponse struct {
 	Message string `json:"message"`
 }
 
+// ErrNotFound is returned when GitHub does not know the requested resource, or hides it from the token
+var ErrNotFound = errors.New("not found on GitHub")
+
+// ErrRateLimited is returned when GitHub refuses a request because the token exhausted its rate limit
+var ErrRateLimited = errors.New("GitHub rate limit exceeded")
+
+// ErrTooLarge is returned when GitHub refuses to list a resource too large for its API, like the contributors of huge repositories
+var ErrTooLarge = errors.New("too large to be listed by GitHub")
+
+// isRateLimited tells whether a failed response comes from the primary or a secondary rate limit
+// https://docs.github.com/en/rest/using-the-rest-api/rate-limits-for-the-rest-api#exceeding-the-rate-limit
+func isRateLimited(resp *http.Response, message string) bool {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		return true
+	case http.StatusForbidden:
+		return resp.Header.Get("X-RateLimit-Remaining") == "0" ||
+			resp.Header.Get("Retry-After") != "" ||
+			strings.Contains(strings.ToLower(message), "rate limit")
+	default:
+		return false
+	}
+}
+
 // doRequest is a helper function that handles HTTP request
-func (gr *githubRepository) doRequest(endpoint string, header string, result interface{}) error {
+// It returns the headers of the response, which carry the pagination links of GitHub
+func (gr *githubRepository) doRequest(endpoint string, header string, result interface{}) (http.Header, error) {
+	return gr.doRequestAccept(endpoint, header, mediaTypeJSON, result)
+}
+
+// doRequestAccept is doRequest with another media type, some of them add fields to the responses
+func (gr *githubRepository) doRequestAccept(endpoint, header, accept string, result interface{}) (http.Header, error) {
 	req, err := http.NewRequest("GET", endpoint, nil)
 	if err != nil {
-		return fmt.Errorf("error creating request: %w", err)
+		return nil, fmt.Errorf("error creating request: %w", err)
 	}
 
 	// Settings recommended by github
 	// https://docs.github.com/en/rest/search/search?apiVersion=2022-11-28#search-repositories--parameters
-	req.Header.Add("Accept", "application/vnd.github+json")
+	req.Header.Add("Accept", accept)
 	req.Header.Add("X-GitHub-Api-Version", "2022-11-28")
 	req.Header.Add("Authorization", header)
 
 	resp, err := gr.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("error executing request: %w", err)
+		return nil, fmt.Errorf("error executing request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	// Statistics answer 202 while GitHub computes them, and 204 for empty repositories
+	switch resp.StatusCode {
+	case http.StatusAccepted:
+		return nil, ErrStatsComputing
+	case http.StatusNoContent:
+		return resp.Header, nil
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		var errResp GitHubErrorResponse
 		if err := json.NewDecoder(resp.Body).Decode(&errResp); err != nil {
-			return fmt.Errorf("error with request (status %d), failed to decode error: %w", resp.StatusCode, err)
+			return nil, fmt.Errorf("error with request (status %d), failed to decode error: %w", resp.StatusCode, err)
+		}
+		if isRateLimited(resp, errResp.Message) {
+			return nil, fmt.Errorf("%w (status %d): %s", ErrRateLimited, resp.StatusCode, errResp.Message)
 		}
-		return fmt.Errorf("GitHub API error (status %d): %s. 422 status code is caused by a bad equality filter (language, or license)", resp.StatusCode, errResp.Message)
+		if resp.StatusCode == http.StatusForbidden && strings.Contains(errResp.Message, "too large") {
+			return nil, fmt.Errorf("%w (status %d): %s", ErrTooLarge, resp.StatusCode, errResp.Message)
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, fmt.Errorf("%w (status %d): %s", ErrNotFound, resp.StatusCode, errResp.Message)
+		}
+		return nil, fmt.Errorf("GitHub API error (status %d): %s. 422 status code is caused by a bad equality filter (language, or license)", resp.StatusCode, errResp.Message)
 	}
 
 	if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
-		return fmt.Errorf("error decoding response: %w", err)
+		return nil, fmt.Errorf("error decoding response: %w", err)
 	}
 
-	return nil
+	return resp.Header, nil
 }
 
-func (gr *githubRepository) SearchRepositories(rsp *models.RepositorySearchParams) (*models.RepositorySearchResponse, error) {
+// SearchURL builds the URL of the GitHub search API for the given parameters
+func (gr *githubRepository) SearchURL(rsp *models.RepositorySearchParams) string {
 	endpoint := fmt.Sprintf("%s/search/repositories?q=%s&per_page=%s&page=%s",
 		gr.baseURL,
 		url.QueryEscape(rsp.Query),
@@ -72,21 +144,107 @@ func (gr *githubRepository) SearchRepositories(rsp *models.RepositorySearchParam
 		url.QueryEscape(rsp.Page),
 	)
 
+	// Without sort, GitHub returns the best matches first and ignores the order
+	if rsp.Sort != "" {
+		endpoint += fmt.Sprintf("&sort=%s&order=%s", url.QueryEscape(rsp.Sort), url.QueryEscape(rsp.Order))
+	}
+
+	return endpoint
+}
+
+func (gr *githubRepository) SearchRepositories(rsp *models.RepositorySearchParams) (*models.RepositorySearchResponse, error) {
+	endpoint := gr.SearchURL(rsp)
+
+	accept := mediaTypeJSON
+	if rsp.Highlight {
+		accept = mediaTypeTextMatch
+	}
+
 	var result models.RepositorySearchResponse
-	if err := gr.doRequest(endpoint, rsp.Header, &result); err != nil {
+	headers, err := gr.doRequestAccept(endpoint, rsp.Header, accept, &result)
+	if err != nil {
 		return nil, err
 	}
+	result.GitHubPages = parseLinkHeader(headers.Get("Link"))
+
+	for i := range result.Items {
+		result.Items[i].TextMatches = highlights(result.Items[i].TextMatches)
+	}
 
 	return &result, nil
 }
 
 func (gr *githubRepository) GetLanguages(repoFullName, header string) (models.Languages, error) {
-	endpoint := fmt.Sprintf("%s/repos/%s/languages", gr.baseURL, repoFullName)
+	repoURL, err := gr.fullNameURL(repoFullName)
+	if err != nil {
+		return nil, err
+	}
+	endpoint := fmt.Sprintf("%s/languages", repoURL)
 
 	languages := make(models.Languages)
-	if err := gr.doRequest(endpoint, header, &languages); err != nil {
+	if _, err := gr.doRequest(endpoint, header, &languages); err != nil {
 		return nil, err
 	}
 
 	return languages, nil
 }
+
+// GetRepository fetches a single repository
+// https://docs.github.com/en/rest/repos/repos?apiVersion=2022-11-28#get-a-repository
+func (gr *githubRepository) GetRepository(owner, name, header string) (*models.Repository, error) {
+	var repo models.Repository
+	if _, err := gr.doRequest(gr.repositoryURL(owner, name), header, &repo); err != nil {
+		return nil, err
+	}
+
+	return &repo, nil
+}
+
+// repositoryURL is the URL of a repository in the GitHub API
+// Both segments are escaped, so a name cannot reach another endpoint.
+func (gr *githubRepository) repositoryURL(owner, name string) string {
+	return fmt.Sprintf("%s/repos/%s/%s", gr.baseURL, url.PathEscape(owner), url.PathEscape(name))
+}
+
+// fullNameURL is the URL of a repository given as owner/name in the GitHub API
+func (gr *githubRepository) fullNameURL(repoFullName string) (string, error) {
+	owner, name, found := strings.Cut(repoFullName, "/")
+	if !found {
+		return "", fmt.Errorf("invalid repository full name %q, must be owner/name", repoFullName)
+	}
+	return gr.repositoryURL(owner, name), nil
+}
+
+// parseLinkHeader extracts the page number of each relation of a GitHub Link header
+// <https://api.github.com/search/repositories?q=go&page=2>; rel="next", <...&page=34>; rel="last"
+// https://docs.github.com/en/rest/using-the-rest-api/using-pagination-in-the-rest-api
+func parseLinkHeader(header string) map[string]int {
+	pages := make(map[string]int)
+
+	for _, link := range strings.Split(header, ",") {
+		target, params, found := strings.Cut(link, ";")
+		if !found {
+			continue
+		}
+
+		target = strings.Trim(strings.TrimSpace(target), "<>")
+		u, err := url.Parse(target)
+		if err != nil {
+			continue
+		}
+
+		page, err := strconv.Atoi(u.Query().Get("page"))
+		if err != nil {
+			continue
+		}
+
+		for _, param := range strings.Split(params, ";") {
+			key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if key == "rel" {
+				pages[strings.Trim(value, `"`)] = page
+			}
+		}
+	}
+
+	return pages
+}
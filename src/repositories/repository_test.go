@@ -72,8 +72,21 @@ func TestSearchRepositories(t *testing.T) {
 					"description": "",
 					"html_url": "https://github.com/octocat/Hello-World",
 					"owner": {
-						"login": "octocat"
-					}
+						"login": "octocat",
+						"type": "User"
+					},
+					"stargazers_count": 80,
+					"forks_count": 9,
+					"size": 108,
+					"topics": ["octocat", "api"],
+					"license": {"key": "mit", "name": "MIT License", "spdx_id": "MIT"},
+					"visibility": "public",
+					"archived": false,
+					"fork": true,
+					"default_branch": "main",
+					"created_at": "2011-01-26T19:01:12Z",
+					"pushed_at": "2011-01-26T19:06:43Z",
+					"updated_at": "2011-01-26T19:14:43Z"
 				}]
 			}`,
 			mockStatusCode: http.StatusOK,
@@ -87,6 +100,39 @@ func TestSearchRepositories(t *testing.T) {
 			},
 			wantError: assert.NoError,
 		},
+		"sorted search": {
+			endpoint: "/search/repositories",
+			rsp: &models.RepositorySearchParams{
+				Query: "golang",
+				Sort:  "stars",
+				Order: "asc",
+			},
+			mockResponse:   `{"total_count": 0, "items": []}`,
+			mockStatusCode: http.StatusOK,
+			mockServerFunc: func(t *testing.T, tc testCase, w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tc.endpoint, r.URL.Path)
+				assert.Equal(t, "stars", r.URL.Query().Get("sort"))
+				assert.Equal(t, "asc", r.URL.Query().Get("order"))
+				w.WriteHeader(tc.mockStatusCode)
+				fmt.Fprintln(w, tc.mockResponse)
+			},
+			wantError: assert.NoError,
+		},
+		"pagination links": {
+			endpoint: "/search/repositories",
+			rsp: &models.RepositorySearchParams{
+				Query: "golang",
+				Page:  "2",
+			},
+			mockResponse:   `{"total_count": 0, "items": []}`,
+			mockStatusCode: http.StatusOK,
+			mockServerFunc: func(t *testing.T, tc testCase, w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Link", `<https://api.github.com/search/repositories?q=golang&page=3>; rel="next", <https://api.github.com/search/repositories?q=golang&page=34>; rel="last"`)
+				w.WriteHeader(tc.mockStatusCode)
+				fmt.Fprintln(w, tc.mockResponse)
+			},
+			wantError: assert.NoError,
+		},
 		"api error": {
 			endpoint: "/search/repositories",
 			rsp: &models.RepositorySearchParams{
@@ -96,6 +142,31 @@ func TestSearchRepositories(t *testing.T) {
 			mockStatusCode: http.StatusForbidden,
 			wantError:      assert.Error,
 		},
+		"not found": {
+			endpoint: "/search/repositories",
+			rsp: &models.RepositorySearchParams{
+				Query: "golang",
+			},
+			mockResponse:   `{"message": "Not Found"}`,
+			mockStatusCode: http.StatusNotFound,
+			wantError:      assert.Error,
+		},
+		"rate limited": {
+			endpoint: "/search/repositories",
+			rsp: &models.RepositorySearchParams{
+				Query: "golang",
+			},
+			mockResponse:   `{"message": "API rate limit exceeded"}`,
+			mockStatusCode: http.StatusForbidden,
+			mockServerFunc: func(t *testing.T, tc testCase, w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.WriteHeader(tc.mockStatusCode)
+				fmt.Fprintln(w, tc.mockResponse)
+			},
+			wantError: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrRateLimited)
+			},
+		},
 		"empty query": {
 			endpoint: "/search/repositories",
 			rsp: &models.RepositorySearchParams{
@@ -167,7 +238,12 @@ func TestSearchRepositories(t *testing.T) {
 				assert.Equal(t, expected.IncompleteResults, result.IncompleteResults)
 				assert.Equal(t, len(expected.Items), len(result.Items))
 
+				if name == "pagination links" {
+					assert.Equal(t, map[string]int{"next": 3, "last": 34}, result.GitHubPages)
+				}
+
 				if len(result.Items) > 0 {
+					assert.Equal(t, expected.Items, result.Items)
 					assert.Equal(t, expected.Items[0].FullName, result.Items[0].FullName)
 					assert.Equal(t, expected.Items[0].Description, result.Items[0].Description)
 					assert.Equal(t, expected.Items[0].Owner.Login, result.Items[0].Owner.Login)
@@ -177,6 +253,46 @@ func TestSearchRepositories(t *testing.T) {
 	}
 }
 
+func TestSearchRepositoriesFields(t *testing.T) {
+	server, repo := setupTestServer(t, testCase{
+		endpoint: "/search/repositories",
+		mockResponse: `{
+			"total_count": 1,
+			"items": [{
+				"full_name": "octocat/Hello-World",
+				"html_url": "https://github.com/octocat/Hello-World",
+				"owner": {"login": "octocat", "type": "User"},
+				"stargazers_count": 80,
+				"forks_count": 9,
+				"topics": ["octocat", "api"],
+				"license": {"key": "mit", "name": "MIT License", "spdx_id": "MIT"},
+				"visibility": "public",
+				"fork": true,
+				"default_branch": "main",
+				"pushed_at": "2011-01-26T19:06:43Z"
+			}]
+		}`,
+		mockStatusCode: http.StatusOK,
+	})
+	defer server.Close()
+
+	result, err := repo.SearchRepositories(&models.RepositorySearchParams{Query: "golang"})
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 1)
+
+	item := result.Items[0]
+	assert.Equal(t, "https://github.com/octocat/Hello-World", item.HTMLURL)
+	assert.Equal(t, 80, item.StargazersCount)
+	assert.Equal(t, 9, item.ForksCount)
+	assert.Equal(t, []string{"octocat", "api"}, item.Topics)
+	assert.Equal(t, &models.License{Key: "mit", Name: "MIT License", SPDXID: "MIT"}, item.License)
+	assert.Equal(t, "public", item.Visibility)
+	assert.True(t, item.Fork)
+	assert.Equal(t, "main", item.DefaultBranch)
+	assert.Equal(t, "User", item.Owner.Type)
+	assert.Equal(t, 2011, item.PushedAt.Year())
+}
+
 func TestGetLanguages(t *testing.T) {
 	tests := map[string]testCase{
 		"nominal": {
@@ -210,6 +326,52 @@ func TestGetLanguages(t *testing.T) {
 			mockStatusCode: http.StatusForbidden,
 			wantError:      assert.Error,
 		},
+		"server error": {
+			endpoint: "/repos/scalingo/scalingo-test/languages",
+			rsp: &models.RepositorySearchParams{
+				Query: "scalingo/scalingo-test",
+			},
+			mockResponse:   `{"message": "Server Error"}`,
+			mockStatusCode: http.StatusInternalServerError,
+			wantError:      assert.Error,
+		},
+		"rate limited": {
+			endpoint: "/repos/scalingo/scalingo-test/languages",
+			rsp: &models.RepositorySearchParams{
+				Query: "scalingo/scalingo-test",
+			},
+			mockResponse:   `{"message": "API rate limit exceeded"}`,
+			mockStatusCode: http.StatusForbidden,
+			mockServerFunc: func(t *testing.T, tc testCase, w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("X-RateLimit-Remaining", "0")
+				w.WriteHeader(tc.mockStatusCode)
+				fmt.Fprintln(w, tc.mockResponse)
+			},
+			wantError: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrRateLimited)
+			},
+		},
+		"escaped name": {
+			endpoint: "/repos/scalingo/../../user/languages",
+			rsp: &models.RepositorySearchParams{
+				Query: "scalingo/../../user",
+			},
+			mockResponse:   `{"Go": 1}`,
+			mockStatusCode: http.StatusOK,
+			mockServerFunc: func(t *testing.T, tc testCase, w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/repos/scalingo/..%2F..%2Fuser/languages", r.URL.EscapedPath())
+				w.WriteHeader(tc.mockStatusCode)
+				fmt.Fprintln(w, tc.mockResponse)
+			},
+			wantError: assert.NoError,
+		},
+		"invalid full name": {
+			endpoint: "/repos/scalingo/languages",
+			rsp: &models.RepositorySearchParams{
+				Query: "scalingo",
+			},
+			wantError: assert.Error,
+		},
 		"invalid json response": {
 			endpoint: "/repos/scalingo/scalingo-test/languages",
 			rsp: &models.RepositorySearchParams{
@@ -273,3 +435,167 @@ func TestGetLanguages(t *testing.T) {
 		})
 	}
 }
+
+func TestGetRepository(t *testing.T) {
+	tests := map[string]struct {
+		owner          string
+		name           string
+		wantPath       string
+		mockResponse   string
+		mockStatusCode int
+		wantError      assert.ErrorAssertionFunc
+		wantRepository *models.Repository
+	}{
+		"nominal": {
+			owner:          "scalingo",
+			name:           "scalingo-test",
+			wantPath:       "/repos/scalingo/scalingo-test",
+			mockResponse:   `{"full_name": "scalingo/scalingo-test", "stargazers_count": 3, "owner": {"login": "scalingo"}}`,
+			mockStatusCode: http.StatusOK,
+			wantError:      assert.NoError,
+			wantRepository: &models.Repository{FullName: "scalingo/scalingo-test", StargazersCount: 3, Owner: models.Owner{Login: "scalingo"}},
+		},
+		"escaped segments": {
+			owner:          "scalingo",
+			name:           "a b?c",
+			wantPath:       "/repos/scalingo/a%20b%3Fc",
+			mockResponse:   `{"full_name": "scalingo/a b?c"}`,
+			mockStatusCode: http.StatusOK,
+			wantError:      assert.NoError,
+			wantRepository: &models.Repository{FullName: "scalingo/a b?c"},
+		},
+		"not found": {
+			owner:          "scalingo",
+			name:           "not-exists",
+			wantPath:       "/repos/scalingo/not-exists",
+			mockResponse:   `{"message": "Not Found"}`,
+			mockStatusCode: http.StatusNotFound,
+			wantError: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrNotFound)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, tt.wantPath, r.URL.EscapedPath())
+				w.WriteHeader(tt.mockStatusCode)
+				fmt.Fprintln(w, tt.mockResponse)
+			}))
+			defer server.Close()
+
+			gr := &githubRepository{baseURL: server.URL, httpClient: server.Client()}
+			repo, err := gr.GetRepository(tt.owner, tt.name, "")
+			tt.wantError(t, err)
+			assert.Equal(t, tt.wantRepository, repo)
+		})
+	}
+}
+
+func TestSearchURL(t *testing.T) {
+	gr := &githubRepository{baseURL: "https://api.github.com"}
+
+	tests := map[string]struct {
+		rsp  *models.RepositorySearchParams
+		want string
+	}{
+		"best match": {
+			rsp:  &models.RepositorySearchParams{Query: "tetris language:go stars:>10", PerPage: "10", Page: "2"},
+			want: "https://api.github.com/search/repositories?q=tetris+language%3Ago+stars%3A%3E10&per_page=10&page=2",
+		},
+		"sorted": {
+			rsp:  &models.RepositorySearchParams{Query: "language:go", PerPage: "10", Page: "1", Sort: "stars", Order: "asc"},
+			want: "https://api.github.com/search/repositories?q=language%3Ago&per_page=10&page=1&sort=stars&order=asc",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, gr.SearchURL(tt.rsp))
+		})
+	}
+}
+
+func TestParseLinkHeader(t *testing.T) {
+	tests := map[string]struct {
+		header   string
+		expected map[string]int
+	}{
+		"all relations": {
+			header: `<https://api.github.com/search/repositories?q=go&page=1>; rel="prev", ` +
+				`<https://api.github.com/search/repositories?q=go&page=3>; rel="next", ` +
+				`<https://api.github.com/search/repositories?q=go&page=34>; rel="last", ` +
+				`<https://api.github.com/search/repositories?q=go&page=1>; rel="first"`,
+			expected: map[string]int{"prev": 1, "next": 3, "last": 34, "first": 1},
+		},
+		"empty header": {
+			header:   "",
+			expected: map[string]int{},
+		},
+		"link without page is ignored": {
+			header:   `<https://api.github.com/search/repositories?q=go>; rel="next", <https://api.github.com/search/repositories?q=go&page=2>; rel="last"`,
+			expected: map[string]int{"last": 2},
+		},
+		"malformed link is ignored": {
+			header:   `https://api.github.com/search/repositories?q=go&page=2`,
+			expected: map[string]int{},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseLinkHeader(tt.header))
+		})
+	}
+}
+
+func TestIsRateLimited(t *testing.T) {
+	tests := map[string]struct {
+		status  int
+		headers map[string]string
+		message string
+		want    bool
+	}{
+		"too many requests": {
+			status: http.StatusTooManyRequests,
+			want:   true,
+		},
+		"primary rate limit": {
+			status:  http.StatusForbidden,
+			headers: map[string]string{"X-RateLimit-Remaining": "0"},
+			want:    true,
+		},
+		"secondary rate limit": {
+			status:  http.StatusForbidden,
+			headers: map[string]string{"Retry-After": "60"},
+			want:    true,
+		},
+		"rate limit message": {
+			status:  http.StatusForbidden,
+			message: "You have exceeded a secondary rate limit",
+			want:    true,
+		},
+		"forbidden": {
+			status:  http.StatusForbidden,
+			headers: map[string]string{"X-RateLimit-Remaining": "12"},
+			message: "Resource not accessible by integration",
+			want:    false,
+		},
+		"validation failed": {
+			status:  http.StatusUnprocessableEntity,
+			message: "Validation Failed",
+			want:    false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			for key, value := range tt.headers {
+				resp.Header.Set(key, value)
+			}
+			assert.Equal(t, tt.want, isRateLimited(resp, tt.message))
+		})
+	}
+}
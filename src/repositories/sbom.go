@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// spdxDocument is the part of the SPDX JSON SBOM of GitHub we read
+// https://spdx.github.io/spdx-spec/v2.3/
+type spdxDocument struct {
+	SBOM struct {
+		// DocumentDescribes are the SPDX ids of the repository itself, it is not one of its dependencies
+		DocumentDescribes []string      `json:"documentDescribes"`
+		Packages          []spdxPackage `json:"packages"`
+	} `json:"sbom"`
+}
+
+type spdxPackage struct {
+	SPDXID           string `json:"SPDXID"`
+	Name             string `json:"name"`
+	VersionInfo      string `json:"versionInfo"`
+	LicenseConcluded string `json:"licenseConcluded"`
+	LicenseDeclared  string `json:"licenseDeclared"`
+	ExternalRefs     []struct {
+		ReferenceType    string `json:"referenceType"`
+		ReferenceLocator string `json:"referenceLocator"`
+	} `json:"externalRefs"`
+}
+
+// GetSBOM fetches the dependencies listed in the SBOM of a repository, sorted by ecosystem, name and version
+// https://docs.github.com/en/rest/dependency-graph/sboms?apiVersion=2022-11-28#export-a-software-bill-of-materials-sbom-for-a-repository
+func (gr *githubRepository) GetSBOM(repoFullName, header string) ([]models.Dependency, error) {
+	repoURL, err := gr.fullNameURL(repoFullName)
+	if err != nil {
+		return nil, err
+	}
+
+	var document spdxDocument
+	if _, err := gr.doRequest(repoURL+"/dependency-graph/sbom", header, &document); err != nil {
+		return nil, err
+	}
+
+	return normalizeSBOM(&document), nil
+}
+
+// normalizeSBOM turns the packages of an SBOM into dependencies
+func normalizeSBOM(document *spdxDocument) []models.Dependency {
+	described := make(map[string]bool, len(document.SBOM.DocumentDescribes))
+	for _, id := range document.SBOM.DocumentDescribes {
+		described[id] = true
+	}
+
+	dependencies := make([]models.Dependency, 0, len(document.SBOM.Packages))
+	for _, pkg := range document.SBOM.Packages {
+		if described[pkg.SPDXID] {
+			continue
+		}
+		dependencies = append(dependencies, normalizePackage(pkg))
+	}
+
+	sort.Slice(dependencies, func(i, j int) bool {
+		a, b := dependencies[i], dependencies[j]
+		if a.Ecosystem != b.Ecosystem {
+			return a.Ecosystem < b.Ecosystem
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Version < b.Version
+	})
+
+	return dependencies
+}
+
+// normalizePackage reads a package from its purl, or from its ecosystem:name SPDX name without purl
+func normalizePackage(pkg spdxPackage) models.Dependency {
+	dependency := models.Dependency{Version: pkg.VersionInfo, License: spdxLicense(pkg.LicenseConcluded)}
+	if dependency.License == "" {
+		dependency.License = spdxLicense(pkg.LicenseDeclared)
+	}
+
+	for _, ref := range pkg.ExternalRefs {
+		if ref.ReferenceType != "purl" {
+			continue
+		}
+		if ecosystem, name, version, ok := parsePurl(ref.ReferenceLocator); ok {
+			dependency.Ecosystem, dependency.Name = ecosystem, name
+			if version != "" {
+				dependency.Version = version
+			}
+			return dependency
+		}
+	}
+
+	if ecosystem, name, found := strings.Cut(pkg.Name, ":"); found {
+		dependency.Ecosystem, dependency.Name = ecosystem, name
+	} else {
+		dependency.Name = pkg.Name
+	}
+	return dependency
+}
+
+// parsePurl reads the type, the namespace and name, and the version of a package URL
+// pkg:npm/%40babel/core@7.0.0?arch=x64#lib gives npm, @babel/core and 7.0.0
+// https://github.com/package-url/purl-spec
+func parsePurl(purl string) (ecosystem, name, version string, ok bool) {
+	rest, found := strings.CutPrefix(purl, "pkg:")
+	if !found {
+		return "", "", "", false
+	}
+	rest, _, _ = strings.Cut(rest, "#")
+	rest, _, _ = strings.Cut(rest, "?")
+
+	ecosystem, path, found := strings.Cut(rest, "/")
+	if !found || ecosystem == "" || path == "" {
+		return "", "", "", false
+	}
+
+	if at := strings.LastIndex(path, "@"); at > 0 {
+		path, version = path[:at], path[at+1:]
+		if v, err := url.PathUnescape(version); err == nil {
+			version = v
+		}
+	}
+
+	name, err := url.PathUnescape(path)
+	if err != nil {
+		return "", "", "", false
+	}
+
+	return strings.ToLower(ecosystem), name, version, true
+}
+
+// spdxLicense drops the values SPDX uses when no license is known
+func spdxLicense(license string) string {
+	if license == "NOASSERTION" || license == "NONE" {
+		return ""
+	}
+	return license
+}
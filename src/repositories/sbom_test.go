@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSBOM(t *testing.T) {
+	gr := enrichmentServer(t, "/repos/scalingo/cli/dependency-graph/sbom", "", http.StatusOK, `{"sbom": {
+		"spdxVersion": "SPDX-2.3",
+		"documentDescribes": ["SPDXRef-com.github.scalingo-cli"],
+		"packages": [
+			{"SPDXID": "SPDXRef-com.github.scalingo-cli", "name": "com.github.scalingo/cli", "versionInfo": "", "licenseConcluded": "MIT"},
+			{"SPDXID": "SPDXRef-go-errors", "name": "go:github.com/pkg/errors", "versionInfo": "0.9.1", "licenseConcluded": "BSD-2-Clause",
+				"externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:golang/github.com/pkg/errors@0.9.1"}]},
+			{"SPDXID": "SPDXRef-npm-babel", "name": "npm:@babel/core", "versionInfo": "7.0.0", "licenseConcluded": "NOASSERTION", "licenseDeclared": "MIT",
+				"externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:npm/%40babel/core@7.0.0"}]},
+			{"SPDXID": "SPDXRef-actions-checkout", "name": "actions:actions/checkout", "versionInfo": "4", "licenseConcluded": "NOASSERTION"}
+		]
+	}}`)
+
+	dependencies, err := gr.GetSBOM("scalingo/cli", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Dependency{
+		{Ecosystem: "actions", Name: "actions/checkout", Version: "4"},
+		{Ecosystem: "golang", Name: "github.com/pkg/errors", Version: "0.9.1", License: "BSD-2-Clause"},
+		{Ecosystem: "npm", Name: "@babel/core", Version: "7.0.0", License: "MIT"},
+	}, dependencies)
+}
+
+func TestParsePurl(t *testing.T) {
+	tests := map[string]struct {
+		purl          string
+		wantEcosystem string
+		wantName      string
+		wantVersion   string
+		wantOK        bool
+	}{
+		"go module": {
+			purl:          "pkg:golang/github.com/pkg/errors@v0.9.1",
+			wantEcosystem: "golang",
+			wantName:      "github.com/pkg/errors",
+			wantVersion:   "v0.9.1",
+			wantOK:        true,
+		},
+		"scoped npm package with qualifiers and subpath": {
+			purl:          "pkg:npm/%40babel/core@7.0.0?arch=x64#lib",
+			wantEcosystem: "npm",
+			wantName:      "@babel/core",
+			wantVersion:   "7.0.0",
+			wantOK:        true,
+		},
+		"without version": {
+			purl:          "pkg:PyPI/requests",
+			wantEcosystem: "pypi",
+			wantName:      "requests",
+			wantOK:        true,
+		},
+		"not a purl": {
+			purl: "https://github.com/pkg/errors",
+		},
+		"without name": {
+			purl: "pkg:npm",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ecosystem, pkg, version, ok := parsePurl(tt.purl)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantEcosystem, ecosystem)
+			assert.Equal(t, tt.wantName, pkg)
+			assert.Equal(t, tt.wantVersion, version)
+		})
+	}
+}
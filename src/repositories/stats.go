@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"errors"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// ErrStatsComputing is returned when GitHub is still computing the statistics of a repository, callers poll again
+// https://docs.github.com/en/rest/metrics/statistics?apiVersion=2022-11-28#a-word-about-caching
+var ErrStatsComputing = errors.New("GitHub is computing the statistics, try again later")
+
+// GetCommitActivity fetches the commits of each of the last 52 weeks of a repository, oldest first
+// https://docs.github.com/en/rest/metrics/statistics?apiVersion=2022-11-28#get-the-last-year-of-commit-activity
+func (gr *githubRepository) GetCommitActivity(repoFullName, header string) ([]models.WeeklyCommits, error) {
+	repoURL, err := gr.fullNameURL(repoFullName)
+	if err != nil {
+		return nil, err
+	}
+
+	var weeks []struct {
+		Week  int64 `json:"week"`
+		Total int   `json:"total"`
+	}
+	if _, err := gr.doRequest(repoURL+"/stats/commit_activity", header, &weeks); err != nil {
+		return nil, err
+	}
+
+	activity := make([]models.WeeklyCommits, 0, len(weeks))
+	for _, week := range weeks {
+		activity = append(activity, models.WeeklyCommits{Week: time.Unix(week.Week, 0).UTC(), Commits: week.Total})
+	}
+
+	return activity, nil
+}
+
+// GetParticipation fetches the weekly commits of a repository, for everyone and for its owner
+// https://docs.github.com/en/rest/metrics/statistics?apiVersion=2022-11-28#get-the-weekly-commit-count
+func (gr *githubRepository) GetParticipation(repoFullName, header string) (*models.Participation, error) {
+	repoURL, err := gr.fullNameURL(repoFullName)
+	if err != nil {
+		return nil, err
+	}
+
+	participation := &models.Participation{All: []int{}, Owner: []int{}}
+	if _, err := gr.doRequest(repoURL+"/stats/participation", header, participation); err != nil {
+		return nil, err
+	}
+
+	return participation, nil
+}
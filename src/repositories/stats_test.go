@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCommitActivity(t *testing.T) {
+	gr := enrichmentServer(t, "/repos/scalingo/cli/stats/commit_activity", "", http.StatusOK,
+		`[{"days": [0, 1, 2, 0, 0, 0, 0], "total": 3, "week": 1704585600}, {"days": [0, 0, 0, 0, 0, 0, 0], "total": 0, "week": 1705190400}]`)
+
+	weeks, err := gr.GetCommitActivity("scalingo/cli", "")
+	assert.NoError(t, err)
+	assert.Equal(t, []models.WeeklyCommits{
+		{Week: time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC), Commits: 3},
+		{Week: time.Date(2024, 1, 14, 0, 0, 0, 0, time.UTC), Commits: 0},
+	}, weeks)
+}
+
+func TestGetParticipation(t *testing.T) {
+	tests := map[string]struct {
+		mockStatusCode    int
+		mockResponse      string
+		wantError         assert.ErrorAssertionFunc
+		wantParticipation *models.Participation
+	}{
+		"nominal": {
+			mockStatusCode:    http.StatusOK,
+			mockResponse:      `{"all": [1, 2], "owner": [0, 1]}`,
+			wantError:         assert.NoError,
+			wantParticipation: &models.Participation{All: []int{1, 2}, Owner: []int{0, 1}},
+		},
+		"computing, return ErrStatsComputing": {
+			mockStatusCode: http.StatusAccepted,
+			mockResponse:   `{}`,
+			wantError: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrStatsComputing)
+			},
+		},
+		"empty repository": {
+			mockStatusCode:    http.StatusNoContent,
+			wantError:         assert.NoError,
+			wantParticipation: &models.Participation{All: []int{}, Owner: []int{}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gr := enrichmentServer(t, "/repos/scalingo/cli/stats/participation", "", tt.mockStatusCode, tt.mockResponse)
+			participation, err := gr.GetParticipation("scalingo/cli", "")
+			tt.wantError(t, err)
+			if tt.wantParticipation != nil {
+				assert.Equal(t, tt.wantParticipation, participation)
+			}
+		})
+	}
+}
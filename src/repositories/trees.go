@@ -0,0 +1,19 @@
+package repositories
+
+import "github.com/Scalingo/sclng-backend-test-v1/src/models"
+
+// GetTree lists the files of a repository at a branch, tag or commit
+// https://docs.github.com/en/rest/git/trees?apiVersion=2022-11-28#get-a-tree
+func (gr *githubRepository) GetTree(repoFullName, ref, header string) (*models.Tree, error) {
+	repoURL, err := gr.fullNameURL(repoFullName)
+	if err != nil {
+		return nil, err
+	}
+
+	var tree models.Tree
+	if _, err := gr.doRequest(repoURL+"/git/trees/"+escapePath(ref)+"?recursive=1", header, &tree); err != nil {
+		return nil, err
+	}
+
+	return &tree, nil
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTree(t *testing.T) {
+	tests := map[string]struct {
+		ref            string
+		wantPath       string
+		mockResponse   string
+		mockStatusCode int
+		wantError      assert.ErrorAssertionFunc
+		wantTree       *models.Tree
+	}{
+		"nominal": {
+			ref:            "main",
+			wantPath:       "/repos/scalingo/cli/git/trees/main",
+			mockResponse:   `{"sha": "abc", "tree": [{"path": "cmd", "type": "tree"}, {"path": "cmd/main.go", "type": "blob", "size": 120}], "truncated": true}`,
+			mockStatusCode: http.StatusOK,
+			wantError:      assert.NoError,
+			wantTree: &models.Tree{
+				Entries:   []models.TreeEntry{{Path: "cmd", Type: "tree"}, {Path: "cmd/main.go", Type: "blob", Size: 120}},
+				Truncated: true,
+			},
+		},
+		"branch with a slash": {
+			ref:            "release/v1 beta",
+			wantPath:       "/repos/scalingo/cli/git/trees/release/v1%20beta",
+			mockResponse:   `{"sha": "abc", "tree": [], "truncated": false}`,
+			mockStatusCode: http.StatusOK,
+			wantError:      assert.NoError,
+			wantTree:       &models.Tree{Entries: []models.TreeEntry{}},
+		},
+		"empty repository, return error": {
+			ref:            "HEAD",
+			wantPath:       "/repos/scalingo/cli/git/trees/HEAD",
+			mockResponse:   `{"message": "Git Repository is empty."}`,
+			mockStatusCode: http.StatusConflict,
+			wantError:      assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gr := enrichmentServer(t, tt.wantPath, "recursive=1", tt.mockStatusCode, tt.mockResponse)
+			tree, err := gr.GetTree("scalingo/cli", tt.ref, "")
+			tt.wantError(t, err)
+			assert.Equal(t, tt.wantTree, tree)
+		})
+	}
+}
@@ -0,0 +1,119 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/repositories"
+)
+
+const (
+	// activityCacheTTL is how long the activity of a repository is reused, GitHub caches its statistics too
+	activityCacheTTL = time.Hour
+	// activityCacheSize bounds the number of repositories kept in the cache
+	activityCacheSize = 10000
+	// activityWeeks is the number of recent weeks the activity figure covers
+	activityWeeks = 12
+)
+
+var (
+	// statsWait bounds the time a repository waits for GitHub to compute its statistics, both of them
+	statsWait = 10 * time.Second
+	// statsBackoff is the first delay between two polls, it doubles after each of them
+	statsBackoff = 500 * time.Millisecond
+)
+
+// getActivity returns the commit activity of a repository, from the cache when possible
+// Both statistics share a single deadline, and stop being polled when the request is cancelled.
+func (ru *repositoryUseCase) getActivity(ctx context.Context, repoFullName, header string) (*models.Activity, error) {
+	if activity, ok := ru.activities.get(repoFullName, header); ok {
+		if ru.counter != nil {
+			ru.counter.hit()
+		}
+		return activity, nil
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, statsWait)
+	defer cancel()
+
+	var weeks []models.WeeklyCommits
+	err := pollStats(ctx, func() (err error) {
+		weeks, err = ru.gr.GetCommitActivity(repoFullName, header)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var participation *models.Participation
+	err = pollStats(ctx, func() (err error) {
+		participation, err = ru.gr.GetParticipation(repoFullName, header)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	activity := &models.Activity{
+		WeeklyCommits:    weeks,
+		Last12Weeks:      sumLastWeeks(participation.All, activityWeeks),
+		OwnerLast12Weeks: sumLastWeeks(participation.Owner, activityWeeks),
+	}
+	ru.activities.set(repoFullName, header, activity)
+
+	return activity, nil
+}
+
+// pollStats calls fetch until GitHub has computed the statistics, the context is done or its deadline is too close
+// The delay between two polls doubles each time. Each poll goes through the repository of the use case,
+// so it is counted in the budget of the request.
+func pollStats(ctx context.Context, fetch func() error) error {
+	backoff := statsBackoff
+
+	for {
+		err := fetch()
+		if !errors.Is(err, repositories.ErrStatsComputing) {
+			return err
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(backoff).After(deadline) {
+			return err
+		}
+
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// statsPolls is the number of calls pollStats makes at most for a statistic, when GitHub computes it until statsWait is over
+func statsPolls() int {
+	polls := 1
+	for backoff, elapsed := statsBackoff, statsBackoff; elapsed <= statsWait; backoff, elapsed = backoff*2, elapsed+backoff*2 {
+		polls++
+	}
+	return polls
+}
+
+// sumLastWeeks sums the last weeks of weekly counts given oldest first
+func sumLastWeeks(counts []int, weeks int) int {
+	if len(counts) > weeks {
+		counts = counts[len(counts)-weeks:]
+	}
+
+	total := 0
+	for _, count := range counts {
+		total += count
+	}
+	return total
+}
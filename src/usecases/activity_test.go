@@ -0,0 +1,166 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+// fastStats shortens the polling of statistics for the test
+func fastStats(t *testing.T, wait time.Duration) {
+	statsWait, statsBackoff = wait, time.Millisecond
+	t.Cleanup(func() {
+		statsWait, statsBackoff = 10*time.Second, 500*time.Millisecond
+	})
+}
+
+func TestGetActivity(t *testing.T) {
+	fastStats(t, 5*time.Millisecond)
+	weeks := []models.WeeklyCommits{{Week: time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC), Commits: 5}}
+	all := make([]int, 52)
+	owner := make([]int, 52)
+	for i := range all {
+		all[i] = 1
+		owner[i] = i % 2
+	}
+
+	mockRepo := new(mockGitHubRepository)
+	mockRepo.On("GetCommitActivity", "scalingo/cli", "token").Return(weeks, nil).Once()
+	mockRepo.On("GetParticipation", "scalingo/cli", "token").Return(&models.Participation{All: all, Owner: owner}, nil).Once()
+	mockRepo.On("GetCommitActivity", "scalingo/computing", "token").Return([]models.WeeklyCommits(nil), repositories.ErrStatsComputing)
+
+	ru := NewRepositoryUseCase(mockRepo, testCursorSecret).(*repositoryUseCase)
+	want := &models.Activity{WeeklyCommits: weeks, Last12Weeks: 12, OwnerLast12Weeks: 6}
+
+	activity, err := ru.getActivity(context.Background(), "scalingo/cli", "token")
+	assert.NoError(t, err)
+	assert.Equal(t, want, activity)
+
+	activity, err = ru.getActivity(context.Background(), "scalingo/cli", "token")
+	assert.NoError(t, err)
+	assert.Equal(t, want, activity, "the second call is served by the cache")
+
+	_, err = ru.getActivity(context.Background(), "scalingo/computing", "token")
+	assert.True(t, errors.Is(err, repositories.ErrStatsComputing))
+	_, ok := ru.activities.get("scalingo/computing", "token")
+	assert.False(t, ok, "errors are not cached")
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestGetActivityPollsCounted(t *testing.T) {
+	fastStats(t, time.Second)
+
+	mockRepo := new(mockGitHubRepository)
+	mockRepo.On("GetCommitActivity", "scalingo/cli", "token").Return([]models.WeeklyCommits(nil), repositories.ErrStatsComputing).Twice()
+	mockRepo.On("GetCommitActivity", "scalingo/cli", "token").Return([]models.WeeklyCommits{}, nil).Once()
+	mockRepo.On("GetParticipation", "scalingo/cli", "token").Return((*models.Participation)(nil), repositories.ErrStatsComputing)
+
+	ru := NewRepositoryUseCase(mockRepo, testCursorSecret).(*repositoryUseCase)
+	counter := &countingRepository{GitHubRepository: mockRepo, limit: 4}
+	ru.gr = counter
+
+	// 3 polls of the commit activity, then the budget stops the polls of the participation
+	_, err := ru.getActivity(context.Background(), "scalingo/cli", "token")
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+	assert.Equal(t, int64(4), counter.calls)
+	mockRepo.AssertNumberOfCalls(t, "GetCommitActivity", 3)
+	mockRepo.AssertNumberOfCalls(t, "GetParticipation", 1)
+}
+
+func TestPollStats(t *testing.T) {
+	tests := map[string]struct {
+		computing int
+		ctx       func() (context.Context, context.CancelFunc)
+		wantErr   error
+		wantCalls int
+	}{
+		"computed after polling": {
+			computing: 2,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), time.Second)
+			},
+			wantCalls: 3,
+		},
+		"still computing at the deadline, return error": {
+			computing: 100,
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 5*time.Millisecond)
+			},
+			wantErr: repositories.ErrStatsComputing,
+		},
+		"request cancelled, stop polling": {
+			computing: 100,
+			ctx: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			wantErr:   repositories.ErrStatsComputing,
+			wantCalls: 1,
+		},
+		"other error, not polled again": {
+			ctx: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), time.Second)
+			},
+			wantErr:   ErrBudgetExceeded,
+			wantCalls: 1,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			fastStats(t, time.Second)
+			ctx, cancel := tt.ctx()
+			defer cancel()
+
+			calls := 0
+			err := pollStats(ctx, func() error {
+				calls++
+				if calls <= tt.computing {
+					return repositories.ErrStatsComputing
+				}
+				return tt.wantErr
+			})
+
+			if tt.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tt.wantErr)
+			}
+			// The number of polls before a deadline depends on the speed of the machine
+			if tt.wantCalls > 0 {
+				assert.Equal(t, tt.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestSumLastWeeks(t *testing.T) {
+	assert.Equal(t, 0, sumLastWeeks(nil, 12))
+	assert.Equal(t, 6, sumLastWeeks([]int{1, 2, 3}, 12))
+	assert.Equal(t, 5, sumLastWeeks([]int{100, 2, 3}, 2))
+}
+
+func TestStatsPolls(t *testing.T) {
+	assert.Equal(t, 5, statsPolls(), "polls at 0, 0.5, 1.5, 3.5 and 7.5 seconds")
+
+	fastStats(t, 5*time.Millisecond)
+	assert.Equal(t, 3, statsPolls())
+
+	// A statistic computed until the deadline never takes more polls
+	ctx, cancel := context.WithTimeout(context.Background(), statsWait)
+	defer cancel()
+	calls := 0
+	err := pollStats(ctx, func() error {
+		calls++
+		return repositories.ErrStatsComputing
+	})
+	assert.ErrorIs(t, err, repositories.ErrStatsComputing)
+	assert.LessOrEqual(t, calls, statsPolls())
+}
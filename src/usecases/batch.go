@@ -0,0 +1,41 @@
+package usecases
+
+import (
+	"sync"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// maxConcurrentSearches bounds the searches of a batch running at the same time
+const maxConcurrentSearches = 4
+
+// BatchResult is the outcome of a search of a batch
+type BatchResult struct {
+	Response *models.RepositorySearchResponse
+	Err      error
+}
+
+// SearchBatch runs several searches concurrently, results are in the order of rsps
+// The searches share the language cache, so a repository found by several of them is fetched once.
+func (ru *repositoryUseCase) SearchBatch(rsps []*models.RepositorySearchParams) []BatchResult {
+	results := make([]BatchResult, len(rsps))
+	slots := make(chan struct{}, maxConcurrentSearches)
+	var wg sync.WaitGroup
+
+	for i, rsp := range rsps {
+		wg.Add(1)
+		i, rsp := i, rsp
+
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			resp, err := ru.SearchRepositories(rsp)
+			results[i] = BatchResult{Response: resp, Err: err}
+		}()
+	}
+
+	wg.Wait()
+	return results
+}
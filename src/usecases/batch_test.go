@@ -0,0 +1,55 @@
+package usecases
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchBatch(t *testing.T) {
+	goParams := &models.RepositorySearchParams{Query: "language:go", Language: "go", PerPage: "2", Page: "1"}
+	shellParams := &models.RepositorySearchParams{Query: "language:shell", Language: "shell", PerPage: "2", Page: "1"}
+	failingParams := &models.RepositorySearchParams{Query: "language:rust", Language: "rust", PerPage: "2", Page: "1"}
+
+	mockRepo := new(mockGitHubRepository)
+	mockRepo.On("SearchRepositories", goParams).Return(&models.RepositorySearchResponse{
+		TotalCount: 2,
+		Items:      []models.Repository{{FullName: "shared"}, {FullName: "go-only"}},
+	}, nil)
+	mockRepo.On("SearchRepositories", shellParams).Return(&models.RepositorySearchResponse{
+		TotalCount: 2,
+		Items:      []models.Repository{{FullName: "shared"}, {FullName: "shell-only"}},
+	}, nil)
+	mockRepo.On("SearchRepositories", failingParams).Return(&models.RepositorySearchResponse{}, errors.New("rate limit exceeded"))
+
+	// The repository found by both searches is fetched once
+	mockRepo.On("GetLanguages", "shared", "").Return(models.Languages{"Go": 1, "Shell": 1}, nil).Once()
+	mockRepo.On("GetLanguages", "go-only", "").Return(models.Languages{"Go": 1}, nil).Once()
+	mockRepo.On("GetLanguages", "shell-only", "").Return(models.Languages{"Shell": 1}, nil).Once()
+
+	results := NewRepositoryUseCase(mockRepo, testCursorSecret).SearchBatch([]*models.RepositorySearchParams{goParams, shellParams, failingParams})
+
+	assert.Len(t, results, 3)
+	assert.NoError(t, results[0].Err)
+	assert.Equal(t, []string{"shared", "go-only"}, repositoryNames(results[0].Response.Items))
+	assert.NoError(t, results[1].Err)
+	assert.Equal(t, []string{"shared", "shell-only"}, repositoryNames(results[1].Response.Items))
+	assert.Error(t, results[2].Err)
+	assert.Nil(t, results[2].Response)
+
+	spent := results[0].Response.Cost.Spent + results[1].Response.Cost.Spent
+	hits := results[0].Response.Cost.CacheHits + results[1].Response.Cost.CacheHits
+	assert.Equal(t, 5, spent, "2 searches and 3 languages")
+	assert.Equal(t, 1, hits)
+	mockRepo.AssertExpectations(t)
+}
+
+func repositoryNames(repos []models.Repository) []string {
+	names := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		names = append(names, repo.FullName)
+	}
+	return names
+}
@@ -0,0 +1,227 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/repositories"
+)
+
+// ErrBudgetExceeded is returned when a request would spend more GitHub calls than its budget
+var ErrBudgetExceeded = errors.New("github calls budget exceeded")
+
+// countingRepository counts the calls made to GitHub during a request, and refuses the ones beyond its limit
+type countingRepository struct {
+	repositories.GitHubRepository
+	// limit is the number of calls allowed, 0 means no limit
+	limit int64
+	calls int64
+	hits  int64
+}
+
+func (cr *countingRepository) spend() error {
+	if calls := atomic.AddInt64(&cr.calls, 1); cr.limit > 0 && calls > cr.limit {
+		atomic.AddInt64(&cr.calls, -1)
+		return ErrBudgetExceeded
+	}
+	return nil
+}
+
+func (cr *countingRepository) SearchRepositories(rsp *models.RepositorySearchParams) (*models.RepositorySearchResponse, error) {
+	if err := cr.spend(); err != nil {
+		return nil, err
+	}
+	return cr.GitHubRepository.SearchRepositories(rsp)
+}
+
+func (cr *countingRepository) SearchCode(params *models.CodeSearchParams) (*models.CodeSearchResponse, error) {
+	if err := cr.spend(); err != nil {
+		return nil, err
+	}
+	return cr.GitHubRepository.SearchCode(params)
+}
+
+func (cr *countingRepository) GetLanguages(repoFullName, header string) (models.Languages, error) {
+	if err := cr.spend(); err != nil {
+		return nil, err
+	}
+	return cr.GitHubRepository.GetLanguages(repoFullName, header)
+}
+
+func (cr *countingRepository) GetTopics(repoFullName, header string) ([]string, error) {
+	if err := cr.spend(); err != nil {
+		return nil, err
+	}
+	return cr.GitHubRepository.GetTopics(repoFullName, header)
+}
+
+func (cr *countingRepository) GetLatestRelease(repoFullName, header string) (*models.Release, error) {
+	if err := cr.spend(); err != nil {
+		return nil, err
+	}
+	return cr.GitHubRepository.GetLatestRelease(repoFullName, header)
+}
+
+func (cr *countingRepository) CountIssues(query, header string) (int, error) {
+	if err := cr.spend(); err != nil {
+		return 0, err
+	}
+	return cr.GitHubRepository.CountIssues(query, header)
+}
+
+func (cr *countingRepository) GetCommunityProfile(repoFullName, header string) (*models.CommunityProfile, error) {
+	if err := cr.spend(); err != nil {
+		return nil, err
+	}
+	return cr.GitHubRepository.GetCommunityProfile(repoFullName, header)
+}
+
+func (cr *countingRepository) GetCommitActivity(repoFullName, header string) ([]models.WeeklyCommits, error) {
+	if err := cr.spend(); err != nil {
+		return nil, err
+	}
+	return cr.GitHubRepository.GetCommitActivity(repoFullName, header)
+}
+
+func (cr *countingRepository) GetParticipation(repoFullName, header string) (*models.Participation, error) {
+	if err := cr.spend(); err != nil {
+		return nil, err
+	}
+	return cr.GitHubRepository.GetParticipation(repoFullName, header)
+}
+
+func (cr *countingRepository) GetContributorCount(repoFullName, header string) (int, error) {
+	if err := cr.spend(); err != nil {
+		return 0, err
+	}
+	return cr.GitHubRepository.GetContributorCount(repoFullName, header)
+}
+
+func (cr *countingRepository) GetSBOM(repoFullName, header string) ([]models.Dependency, error) {
+	if err := cr.spend(); err != nil {
+		return nil, err
+	}
+	return cr.GitHubRepository.GetSBOM(repoFullName, header)
+}
+
+func (cr *countingRepository) GetFileContents(repoFullName, path, header string) ([]byte, error) {
+	if err := cr.spend(); err != nil {
+		return nil, err
+	}
+	return cr.GitHubRepository.GetFileContents(repoFullName, path, header)
+}
+
+func (cr *countingRepository) GetTree(repoFullName, ref, header string) (*models.Tree, error) {
+	if err := cr.spend(); err != nil {
+		return nil, err
+	}
+	return cr.GitHubRepository.GetTree(repoFullName, ref, header)
+}
+
+// hit records a call saved by the cache
+func (cr *countingRepository) hit() {
+	atomic.AddInt64(&cr.hits, 1)
+}
+
+func (cr *countingRepository) cost(cost *models.RequestCost) {
+	cost.Spent = int(atomic.LoadInt64(&cr.calls))
+	cost.CacheHits = int(atomic.LoadInt64(&cr.hits))
+}
+
+// budgetPlan is a search adapted to its budget
+type budgetPlan struct {
+	params         *models.RepositorySearchParams
+	cost           *models.RequestCost
+	skipEnrichment bool
+}
+
+// planBudget adapts the search to its budget before any call is made
+// The exact cost of the enrichment is only known once the search returns, since cached languages are free:
+// refused requests are refused then, unless they fill pages whose cost cannot be bounded beforehand.
+// Degraded requests give up the following facet pages, fill, then repositories of the page, then the enrichment itself.
+func planBudget(rsp *models.RepositorySearchParams) (*budgetPlan, error) {
+	planned := *rsp
+	cost := &models.RequestCost{
+		Budget:    rsp.MaxGitHubCalls,
+		Estimated: estimateCalls(&planned).Total,
+		DryRun:    rsp.DryRun,
+	}
+	plan := &budgetPlan{params: &planned, cost: cost}
+
+	budget := rsp.MaxGitHubCalls
+	if budget == 0 || cost.Estimated.Max <= budget {
+		return plan, nil
+	}
+
+	if rsp.OnBudget != models.BudgetDegrade {
+		if planned.Fill {
+			return nil, fmt.Errorf("%w: filling the page may cost up to %d calls, max_github_calls is %d", ErrBudgetExceeded, cost.Estimated.Max, budget)
+		}
+		return plan, nil
+	}
+
+	if planned.FacetPages > 1 {
+		planned.FacetPages = 1
+		cost.Degraded = append(cost.Degraded, "facet_pages reduced to 1")
+		cost.Estimated = estimateCalls(&planned).Total
+	}
+
+	if cost.Estimated.Max > budget && planned.Fill {
+		planned.Fill = false
+		planned.Cursor = ""
+		cost.Degraded = append(cost.Degraded, "fill disabled")
+		cost.Estimated = estimateCalls(&planned).Total
+	}
+
+	// Each repository costs its languages and the calls of the other included enrichments
+	perRepository := enrichmentCalls(planned.Include)
+	if includesLanguages(planned.Include) {
+		perRepository += languagesEnricher{}.Calls()
+	}
+	if cost.Estimated.Max > budget && perRepository > 0 && (budget-1)/perRepository > 0 {
+		planned.PerPage = strconv.Itoa((budget - 1) / perRepository)
+		cost.Degraded = append(cost.Degraded, "per_page reduced to "+planned.PerPage)
+		cost.Estimated = estimateCalls(&planned).Total
+	}
+
+	// Only the search fits in the budget
+	if cost.Estimated.Max > budget {
+		plan.skipEnrichment = true
+		cost.Degraded = append(cost.Degraded, "enrichment skipped")
+		cost.Estimated = models.CallRange{Min: 1, Max: 1}
+	}
+
+	return plan, nil
+}
+
+// checkEnrichmentBudget refuses to enrich repositories when the calls of their enrichment exceed the budget
+// Languages missing from the cache cost up to two calls each, when they have to be inferred from the files of the repository.
+// Activities missing from the cache cost their polls, the other enrichments are never cached.
+func (ru *repositoryUseCase) checkEnrichmentBudget(items []models.Repository, rsp *models.RepositorySearchParams) error {
+	if ru.counter == nil || ru.counter.limit == 0 {
+		return nil
+	}
+
+	misses := 0
+	if includesLanguages(rsp.Include) {
+		misses = ru.languages.misses(items, rsp.Header) * languagesEnricher{}.Calls()
+	}
+	needed := misses + len(items)*enrichmentCalls(rsp.Include)
+	if includes(includedEnrichers(rsp.Include), models.IncludeActivity) {
+		hits := len(items) - ru.activities.misses(items, rsp.Header)
+		needed -= hits * activityEnricher{}.Calls()
+	}
+
+	left := ru.counter.limit - atomic.LoadInt64(&ru.counter.calls)
+	switch {
+	case int64(needed) <= left:
+		return nil
+	case needed == misses:
+		return fmt.Errorf("%w: the languages of %d repositories are needed, %d calls are left", ErrBudgetExceeded, needed, left)
+	default:
+		return fmt.Errorf("%w: the enrichment of %d repositories needs %d calls, %d calls are left", ErrBudgetExceeded, len(items), needed, left)
+	}
+}
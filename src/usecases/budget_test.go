@@ -0,0 +1,243 @@
+package usecases
+
+import (
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlanBudget(t *testing.T) {
+	tests := map[string]struct {
+		rsp                *models.RepositorySearchParams
+		wantErr            assert.ErrorAssertionFunc
+		wantPerPage        string
+		wantFill           bool
+		wantSkipEnrichment bool
+		wantCost           *models.RequestCost
+	}{
+		"no budget": {
+			rsp:         &models.RepositorySearchParams{PerPage: "100", Page: "1"},
+			wantErr:     assert.NoError,
+			wantPerPage: "100",
+			wantCost:    &models.RequestCost{Estimated: models.CallRange{Min: 1, Max: 201}},
+		},
+		"within budget": {
+			rsp:         &models.RepositorySearchParams{PerPage: "10", Page: "1", MaxGitHubCalls: 21},
+			wantErr:     assert.NoError,
+			wantPerPage: "10",
+			wantCost:    &models.RequestCost{Budget: 21, Estimated: models.CallRange{Min: 1, Max: 21}},
+		},
+		"beyond budget, refused once the search returns": {
+			rsp:         &models.RepositorySearchParams{PerPage: "10", Page: "1", MaxGitHubCalls: 5},
+			wantErr:     assert.NoError,
+			wantPerPage: "10",
+			wantCost:    &models.RequestCost{Budget: 5, Estimated: models.CallRange{Min: 1, Max: 21}},
+		},
+		"fill beyond budget, return error": {
+			rsp:     &models.RepositorySearchParams{PerPage: "10", Page: "1", Fill: true, MaxGitHubCalls: 50},
+			wantErr: assert.Error,
+		},
+		"degrade fill and per_page": {
+			rsp:         &models.RepositorySearchParams{PerPage: "10", Page: "1", Fill: true, Cursor: "c", MaxGitHubCalls: 5, OnBudget: models.BudgetDegrade},
+			wantErr:     assert.NoError,
+			wantPerPage: "2",
+			wantCost: &models.RequestCost{
+				Budget:    5,
+				Estimated: models.CallRange{Min: 1, Max: 5},
+				Degraded:  []string{"fill disabled", "per_page reduced to 2"},
+			},
+		},
+		"degrade facet pages": {
+			rsp:         &models.RepositorySearchParams{PerPage: "10", Page: "1", FacetPages: 5, MaxGitHubCalls: 21, OnBudget: models.BudgetDegrade},
+			wantErr:     assert.NoError,
+			wantPerPage: "10",
+			wantCost: &models.RequestCost{
+				Budget:    21,
+				Estimated: models.CallRange{Min: 1, Max: 21},
+				Degraded:  []string{"facet_pages reduced to 1"},
+			},
+		},
+		"degrade per_page with included enrichments": {
+			rsp:         &models.RepositorySearchParams{PerPage: "10", Page: "1", Include: []string{models.IncludeLanguages, models.IncludeIssues}, MaxGitHubCalls: 10, OnBudget: models.BudgetDegrade},
+			wantErr:     assert.NoError,
+			wantPerPage: "3",
+			wantCost: &models.RequestCost{
+				Budget:    10,
+				Estimated: models.CallRange{Min: 1, Max: 10},
+				Degraded:  []string{"per_page reduced to 3"},
+			},
+		},
+		"degrade enrichment": {
+			rsp:                &models.RepositorySearchParams{PerPage: "10", Page: "1", MaxGitHubCalls: 1, OnBudget: models.BudgetDegrade, DryRun: true},
+			wantErr:            assert.NoError,
+			wantPerPage:        "10",
+			wantSkipEnrichment: true,
+			wantCost: &models.RequestCost{
+				Budget:    1,
+				Estimated: models.CallRange{Min: 1, Max: 1},
+				Degraded:  []string{"enrichment skipped"},
+				DryRun:    true,
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			plan, err := planBudget(tt.rsp)
+			tt.wantErr(t, err)
+			if err != nil {
+				return
+			}
+
+			assert.Equal(t, tt.wantPerPage, plan.params.PerPage)
+			assert.Equal(t, tt.wantFill, plan.params.Fill)
+			assert.Equal(t, tt.wantSkipEnrichment, plan.skipEnrichment)
+			assert.Equal(t, tt.wantCost, plan.cost)
+		})
+	}
+}
+
+func TestSearchRepositoriesBudget(t *testing.T) {
+	searchParams := func(perPage string) *models.RepositorySearchParams {
+		return &models.RepositorySearchParams{Query: "language:go", Language: "go", PerPage: perPage, Page: "1"}
+	}
+	page := &models.RepositorySearchResponse{
+		TotalCount: 3,
+		Items:      []models.Repository{{FullName: "a"}, {FullName: "b"}, {FullName: "c"}},
+	}
+
+	tests := map[string]struct {
+		rsp              *models.RepositorySearchParams
+		cached           []string
+		cachedActivities []string
+		mockCall         func(*mockGitHubRepository)
+		wantErr          assert.ErrorAssertionFunc
+		wantCount        int
+		wantCost         *models.RequestCost
+	}{
+		"cache hits fit the budget": {
+			rsp:    &models.RepositorySearchParams{Query: "language:go", Language: "go", PerPage: "3", Page: "1", MaxGitHubCalls: 3},
+			cached: []string{"a", "b"},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", searchParams("3")).Return(page, nil)
+				m.On("GetLanguages", "c", "").Return(models.Languages{"Go": 1}, nil)
+			},
+			wantErr:   assert.NoError,
+			wantCount: 3,
+			wantCost:  &models.RequestCost{Budget: 3, Estimated: models.CallRange{Min: 1, Max: 7}, Spent: 2, CacheHits: 2},
+		},
+		"empty languages inferred within the budget": {
+			rsp:    &models.RepositorySearchParams{Query: "language:go", Language: "go", PerPage: "3", Page: "1", MaxGitHubCalls: 3},
+			cached: []string{"a", "b"},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", searchParams("3")).Return(page, nil)
+				m.On("GetLanguages", "c", "").Return(models.Languages{}, nil)
+				m.On("GetTree", "c", "HEAD", "").Return(&models.Tree{Entries: []models.TreeEntry{{Path: "main.go", Type: "blob", Size: 10}}}, nil)
+			},
+			wantErr:   assert.NoError,
+			wantCount: 3,
+			wantCost:  &models.RequestCost{Budget: 3, Estimated: models.CallRange{Min: 1, Max: 7}, Spent: 3, CacheHits: 2},
+		},
+		"empty languages may need the tree beyond the budget, return error": {
+			rsp:    &models.RepositorySearchParams{Query: "language:go", Language: "go", PerPage: "3", Page: "1", MaxGitHubCalls: 2},
+			cached: []string{"a", "b"},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", searchParams("3")).Return(page, nil)
+			},
+			wantErr: assert.Error,
+		},
+		"misses beyond the budget, return error": {
+			rsp: &models.RepositorySearchParams{Query: "language:go", Language: "go", PerPage: "3", Page: "1", MaxGitHubCalls: 2},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", searchParams("3")).Return(page, nil)
+			},
+			wantErr: assert.Error,
+		},
+		"cached activities fit the budget": {
+			rsp:              &models.RepositorySearchParams{Query: "language:go", Language: "go", PerPage: "3", Page: "1", Include: []string{models.IncludeLanguages, models.IncludeActivity}, MaxGitHubCalls: 1},
+			cached:           []string{"a", "b", "c"},
+			cachedActivities: []string{"a", "b", "c"},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", searchParams("3")).Return(page, nil)
+			},
+			wantErr:   assert.NoError,
+			wantCount: 3,
+			wantCost:  &models.RequestCost{Budget: 1, Estimated: models.CallRange{Min: 1, Max: 37}, Spent: 1, CacheHits: 6},
+		},
+		"uncached activity beyond the budget, return error": {
+			rsp:              &models.RepositorySearchParams{Query: "language:go", Language: "go", PerPage: "3", Page: "1", Include: []string{models.IncludeLanguages, models.IncludeActivity}, MaxGitHubCalls: 10},
+			cached:           []string{"a", "b", "c"},
+			cachedActivities: []string{"a", "b"},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", searchParams("3")).Return(page, nil)
+			},
+			wantErr: assert.Error,
+		},
+		"enrichment skipped": {
+			rsp: &models.RepositorySearchParams{Query: "language:go", Language: "go", PerPage: "3", Page: "1", MaxGitHubCalls: 1, OnBudget: models.BudgetDegrade},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", searchParams("3")).Return(page, nil)
+			},
+			wantErr:   assert.NoError,
+			wantCount: 3,
+			wantCost: &models.RequestCost{
+				Budget:    1,
+				Estimated: models.CallRange{Min: 1, Max: 1},
+				Spent:     1,
+				Degraded:  []string{"enrichment skipped"},
+			},
+		},
+		"dry run": {
+			rsp:       &models.RepositorySearchParams{Query: "language:go", Language: "go", PerPage: "3", Page: "1", DryRun: true},
+			mockCall:  func(m *mockGitHubRepository) {},
+			wantErr:   assert.NoError,
+			wantCount: 0,
+			wantCost:  &models.RequestCost{Estimated: models.CallRange{Min: 1, Max: 7}, DryRun: true},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(mockGitHubRepository)
+			tt.mockCall(mockRepo)
+
+			ru := NewRepositoryUseCase(mockRepo, testCursorSecret).(*repositoryUseCase)
+			for _, name := range tt.cached {
+				ru.languages.set(name, "", cachedLanguages{languages: models.Languages{"Go": 1}})
+			}
+			for _, name := range tt.cachedActivities {
+				ru.activities.set(name, "", &models.Activity{})
+			}
+
+			resp, err := ru.SearchRepositories(tt.rsp)
+			tt.wantErr(t, err)
+			if err == nil {
+				assert.Len(t, resp.Items, tt.wantCount)
+				assert.Equal(t, tt.wantCost, resp.Cost)
+			} else {
+				assert.ErrorIs(t, err, ErrBudgetExceeded)
+			}
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestCountingRepository(t *testing.T) {
+	mockRepo := new(mockGitHubRepository)
+	mockRepo.On("GetLanguages", "a", "").Return(models.Languages{"Go": 1}, nil).Once()
+
+	counter := &countingRepository{GitHubRepository: mockRepo, limit: 1}
+
+	_, err := counter.GetLanguages("a", "")
+	assert.NoError(t, err)
+
+	_, err = counter.GetLanguages("a", "")
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+
+	counter.hit()
+	cost := &models.RequestCost{}
+	counter.cost(cost)
+	assert.Equal(t, &models.RequestCost{Spent: 1, CacheHits: 1}, cost)
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,158 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+const (
+	// languagesCacheTTL is how long the languages of a repository are reused
+	languagesCacheTTL = 10 * time.Minute
+	// languagesCacheSize bounds the number of repositories kept in the cache
+	languagesCacheSize = 10000
+)
+
+type cachedEntry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// ttlCache keeps values for a while, by name (a repository or a query) and token
+// The token is part of the key since private repositories are only visible to some tokens.
+type ttlCache[V any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	size    int
+	entries map[string]cachedEntry[V]
+	// inflight are the fetches in progress, concurrent callers wait for them instead of fetching again
+	inflight map[string]*cacheFetch[V]
+}
+
+// cacheFetch is a fetch of a value shared by its concurrent callers
+type cacheFetch[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+func newTTLCache[V any](ttl time.Duration, size int) *ttlCache[V] {
+	return &ttlCache[V]{
+		ttl:      ttl,
+		size:     size,
+		entries:  make(map[string]cachedEntry[V]),
+		inflight: make(map[string]*cacheFetch[V]),
+	}
+}
+
+func cacheKey(name, header string) string {
+	return name + "\x00" + header
+}
+
+// get returns the cached value, if it has not expired
+func (c *ttlCache[V]) get(name, header string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lookup(cacheKey(name, header))
+}
+
+func (c *ttlCache[V]) lookup(key string) (V, bool) {
+	entry, ok := c.entries[key]
+	if !ok || now().After(entry.expires) {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+// load returns the value from the cache, from a fetch in progress, or from fetch
+// shared is false only when fetch was called, errors are not cached. A fetch in progress runs under the budget
+// and the context of the request that started it, callers fetch again when it failed because of them.
+func (c *ttlCache[V]) load(name, header string, fetch func() (V, error)) (value V, shared bool, err error) {
+	key := cacheKey(name, header)
+
+	c.mu.Lock()
+	for {
+		if value, ok := c.lookup(key); ok {
+			c.mu.Unlock()
+			return value, true, nil
+		}
+
+		f, ok := c.inflight[key]
+		if !ok {
+			break
+		}
+		c.mu.Unlock()
+		<-f.done
+		if !isRequestError(f.err) {
+			return f.value, true, f.err
+		}
+		c.mu.Lock()
+	}
+
+	f := &cacheFetch[V]{done: make(chan struct{})}
+	c.inflight[key] = f
+	c.mu.Unlock()
+
+	f.value, f.err = fetch()
+
+	c.mu.Lock()
+	delete(c.inflight, key)
+	if f.err == nil {
+		c.store(key, f.value)
+	}
+	c.mu.Unlock()
+	close(f.done)
+
+	return f.value, false, f.err
+}
+
+// set caches a value
+func (c *ttlCache[V]) set(name, header string, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.store(cacheKey(name, header), value)
+}
+
+// store caches a value, the lock must be held
+// When the cache is full, expired entries are dropped first, then arbitrary ones.
+func (c *ttlCache[V]) store(key string, value V) {
+	if len(c.entries) >= c.size {
+		current := now()
+		for k, entry := range c.entries {
+			if current.After(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < c.size {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+
+	c.entries[key] = cachedEntry[V]{
+		value:   value,
+		expires: now().Add(c.ttl),
+	}
+}
+
+// isRequestError tells whether an error comes from the request that fetched a value, rather than from GitHub
+func isRequestError(err error) bool {
+	return errors.Is(err, ErrBudgetExceeded) || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// misses counts the repositories with no cached value
+func (c *ttlCache[V]) misses(items []models.Repository, header string) int {
+	misses := 0
+	for _, repo := range items {
+		if _, ok := c.get(repo.FullName, header); !ok {
+			misses++
+		}
+	}
+	return misses
+}
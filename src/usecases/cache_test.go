@@ -0,0 +1,178 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTTLCache(t *testing.T) {
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	lc := newTTLCache[models.Languages](time.Minute, 2)
+	lc.set("a", "token", models.Languages{"Go": 1})
+
+	languages, ok := lc.get("a", "token")
+	assert.True(t, ok)
+	assert.Equal(t, models.Languages{"Go": 1}, languages)
+
+	_, ok = lc.get("a", "other token")
+	assert.False(t, ok, "entries are cached by token")
+
+	assert.Equal(t, 1, lc.misses([]models.Repository{{FullName: "a"}, {FullName: "b"}}, "token"))
+
+	current = current.Add(2 * time.Minute)
+	_, ok = lc.get("a", "token")
+	assert.False(t, ok, "entries expire")
+
+	lc.set("b", "token", models.Languages{"Go": 1})
+	lc.set("c", "token", models.Languages{"Go": 1})
+	assert.Len(t, lc.entries, 2, "expired entries are dropped when the cache is full")
+
+	lc.set("d", "token", models.Languages{"Go": 1})
+	assert.Len(t, lc.entries, 2, "the cache never grows beyond its size")
+	_, ok = lc.get("d", "token")
+	assert.True(t, ok)
+}
+
+func TestTTLCacheLoad(t *testing.T) {
+	lc := newTTLCache[models.Languages](time.Minute, 10)
+
+	release := make(chan struct{})
+	var fetches int32
+	fetch := func() (models.Languages, error) {
+		atomic.AddInt32(&fetches, 1)
+		<-release
+		return models.Languages{"Go": 1}, nil
+	}
+
+	var wg sync.WaitGroup
+	shared := make([]bool, 5)
+	for i := range shared {
+		wg.Add(1)
+		i := i
+		go func() {
+			defer wg.Done()
+			languages, s, err := lc.load("a", "token", fetch)
+			assert.NoError(t, err)
+			assert.Equal(t, models.Languages{"Go": 1}, languages)
+			shared[i] = s
+		}()
+	}
+
+	// Wait for the first fetch to be in progress before releasing it
+	assert.Eventually(t, func() bool { return atomic.LoadInt32(&fetches) == 1 }, time.Second, time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&fetches))
+	assert.ElementsMatch(t, []bool{false, true, true, true, true}, shared)
+
+	_, s, err := lc.load("a", "token", fetch)
+	assert.NoError(t, err)
+	assert.True(t, s, "later loads are served by the cache")
+
+	_, _, err = lc.load("b", "token", func() (models.Languages, error) { return nil, errors.New("not found") })
+	assert.Error(t, err)
+	_, ok := lc.get("b", "token")
+	assert.False(t, ok, "errors are not cached")
+}
+
+func TestCountCache(t *testing.T) {
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	cc := newTTLCache[searchCount](time.Minute, 1)
+	cc.set("language:go", "token", searchCount{count: 3})
+
+	count, ok := cc.get("language:go", "token")
+	assert.True(t, ok)
+	assert.Equal(t, searchCount{count: 3}, count)
+
+	_, ok = cc.get("language:go", "other token")
+	assert.False(t, ok, "entries are cached by token")
+
+	cc.set("language:rust", "token", searchCount{count: 1})
+	assert.Len(t, cc.entries, 1, "the cache never grows beyond its size")
+
+	current = current.Add(2 * time.Minute)
+	_, ok = cc.get("language:rust", "token")
+	assert.False(t, ok, "entries expire")
+}
+
+func TestActivityCache(t *testing.T) {
+	current := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	ac := newTTLCache[*models.Activity](time.Minute, 1)
+	ac.set("a", "token", &models.Activity{Last12Weeks: 3})
+
+	activity, ok := ac.get("a", "token")
+	assert.True(t, ok)
+	assert.Equal(t, &models.Activity{Last12Weeks: 3}, activity)
+
+	_, ok = ac.get("a", "other token")
+	assert.False(t, ok, "entries are cached by token")
+
+	ac.set("b", "token", &models.Activity{})
+	assert.Len(t, ac.entries, 1, "the cache never grows beyond its size")
+
+	current = current.Add(2 * time.Minute)
+	_, ok = ac.get("b", "token")
+	assert.False(t, ok, "entries expire")
+}
+
+func TestTTLCacheLoadRequestErrors(t *testing.T) {
+	for name, fetchErr := range map[string]error{
+		"budget exceeded":   ErrBudgetExceeded,
+		"request cancelled": context.Canceled,
+	} {
+		t.Run(name, func(t *testing.T) {
+			lc := newTTLCache[models.Languages](time.Minute, 10)
+
+			started := make(chan struct{})
+			release := make(chan struct{})
+			done := make(chan error)
+			go func() {
+				_, _, err := lc.load("a", "token", func() (models.Languages, error) {
+					close(started)
+					<-release
+					return nil, fmt.Errorf("error fetching languages: %w", fetchErr)
+				})
+				done <- err
+			}()
+			<-started
+
+			waited := make(chan struct{})
+			go func() {
+				defer close(waited)
+				languages, shared, err := lc.load("a", "token", func() (models.Languages, error) {
+					return models.Languages{"Go": 1}, nil
+				})
+				assert.NoError(t, err, "the error of another request is not shared")
+				assert.False(t, shared)
+				assert.Equal(t, models.Languages{"Go": 1}, languages)
+			}()
+
+			// Let the second caller wait for the first fetch before releasing it
+			time.Sleep(10 * time.Millisecond)
+			close(release)
+			assert.ErrorIs(t, <-done, fetchErr)
+			<-waited
+
+			_, ok := lc.get("a", "token")
+			assert.True(t, ok)
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package usecases
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// codeQualifiers are the qualifiers accepted in a code search
+// https://docs.github.com/en/search-github/searching-on-github/searching-code
+var codeQualifiers = map[string]ValidatorFunc{
+	"path":      validateCodePath,
+	"filename":  validateFilename,
+	"extension": validateExtension,
+	"language":  validateEqualOperator,
+	"repo":      validateRepoQualifier,
+	"org":       validateOrgQualifier,
+}
+
+// SearchCode searches files and groups them by repository, each repository is enriched with its languages
+// Stats are computed for the language of the query, or for the primary language of each repository.
+func (ru *repositoryUseCase) SearchCode(params *models.CodeSearchParams) (*models.CodeSearchResult, error) {
+	language, err := validateCodeQuery(params.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := ru.gr.SearchCode(params)
+	if err != nil {
+		log.Print("error searching code: ", err)
+		return nil, fmt.Errorf("error searching code: %w", err)
+	}
+
+	repos := groupByRepository(resp.Items)
+
+	err = fanOut(len(repos), func(i int) error {
+		return ru.codeRepositoryLanguages(&repos[i].Repository, language, params)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CodeSearchResult{
+		TotalCount:        resp.TotalCount,
+		IncompleteResults: resp.IncompleteResults,
+		PerPage:           params.PerPage,
+		Page:              params.Page,
+		Repositories:      repos,
+	}, nil
+}
+
+// codeRepositoryLanguages sets the languages of a repository found by a code search, they come from the cache when possible
+func (ru *repositoryUseCase) codeRepositoryLanguages(repo *models.Repository, language string, params *models.CodeSearchParams) error {
+	languages, inferred, err := ru.getLanguages(repo, params.Header)
+	if err != nil {
+		log.Print("error fetching languages for ", repo.FullName, ": ", err)
+		return fmt.Errorf("error fetching languages for %s: %w", repo.FullName, err)
+	}
+
+	setLanguageStats(repo, languages, inferred, language, params.LanguagesMode)
+	return nil
+}
+
+// groupByRepository groups the files of a code search by repository, in the order GitHub ranked them
+func groupByRepository(items []models.CodeSearchItem) []models.CodeRepository {
+	repos := make([]models.CodeRepository, 0)
+	index := make(map[string]int)
+
+	for _, item := range items {
+		i, ok := index[item.Repository.FullName]
+		if !ok {
+			i = len(repos)
+			index[item.Repository.FullName] = i
+			repos = append(repos, models.CodeRepository{Repository: item.Repository, Files: []models.CodeFile{}})
+		}
+		repos[i].Files = append(repos[i].Files, item.CodeFile)
+	}
+
+	return repos
+}
+
+// validateCodeQuery verifies a code search and returns its language, if any
+// GitHub refuses code searches made of qualifiers only, a search term is required.
+func validateCodeQuery(q string) (language string, err error) {
+	if err := verifyQueryLength(q); err != nil {
+		return "", err
+	}
+
+	hasTerm := false
+	for _, part := range queryFields(q) {
+		// Quoted phrases are searched as they are, colons included
+		if strings.HasPrefix(part, `"`) {
+			hasTerm = true
+			continue
+		}
+
+		// Qualifiers are excluded with a leading -
+		qualifier, value, found := strings.Cut(part, ":")
+		negated := strings.HasPrefix(qualifier, "-")
+		qualifier = strings.TrimPrefix(qualifier, "-")
+
+		// Terms may contain colons too, like std::vector, http:// or TODO:fix
+		validate, exists := codeQualifiers[qualifier]
+		if !found || !exists || strings.HasPrefix(value, ":") || strings.HasPrefix(value, "//") {
+			hasTerm = true
+			continue
+		}
+
+		if err := validate(qualifier, value); err != nil {
+			return "", err
+		}
+
+		if qualifier == "language" && !negated {
+			language = value
+		}
+	}
+
+	if !hasTerm {
+		return "", fmt.Errorf("code search needs at least one search term besides qualifiers")
+	}
+
+	return language, nil
+}
+
+// queryFields splits a query around spaces, keeping quoted phrases whole
+func queryFields(q string) []string {
+	var fields []string
+	var field strings.Builder
+	quoted := false
+	for _, c := range q {
+		switch {
+		case c == '"':
+			quoted = !quoted
+			field.WriteRune(c)
+		case unicode.IsSpace(c) && !quoted:
+			if field.Len() > 0 {
+				fields = append(fields, field.String())
+				field.Reset()
+			}
+		default:
+			field.WriteRune(c)
+		}
+	}
+	if field.Len() > 0 {
+		fields = append(fields, field.String())
+	}
+	return fields
+}
+
+// validateCodePath verifies a path qualifier, a directory of the repositories
+func validateCodePath(qualifier, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("%s cannot be empty", qualifier)
+	}
+	return nil
+}
+
+// validateFilename verifies a filename qualifier, a name without directory
+func validateFilename(qualifier, value string) error {
+	if strings.TrimSpace(value) == "" {
+		return fmt.Errorf("%s cannot be empty", qualifier)
+	}
+	if strings.Contains(value, "/") {
+		return fmt.Errorf("%s cannot contain a directory, use path", qualifier)
+	}
+	return nil
+}
+
+// validateExtension verifies an extension qualifier, given without its dot
+func validateExtension(qualifier, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s cannot be empty", qualifier)
+	}
+	for _, r := range value {
+		if !isAlphanumeric(r) && r != '-' && r != '_' {
+			return fmt.Errorf("%s must be an extension without its dot, got '%s'", qualifier, value)
+		}
+	}
+	return nil
+}
+
+// validateRepoQualifier verifies a repo qualifier, an owner/name full name
+func validateRepoQualifier(qualifier, value string) error {
+	owner, name, found := strings.Cut(value, "/")
+	if !found {
+		return fmt.Errorf("%s must be an owner/name full name, got '%s'", qualifier, value)
+	}
+	if err := validateRepositoryName(owner, name); err != nil {
+		return fmt.Errorf("%s: %w", qualifier, err)
+	}
+	return nil
+}
+
+// validateOrgQualifier verifies an org qualifier, the login of an organization
+func validateOrgQualifier(qualifier, value string) error {
+	if err := validateOwner(value); err != nil {
+		return fmt.Errorf("%s: %w", qualifier, err)
+	}
+	return nil
+}
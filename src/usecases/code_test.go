@@ -0,0 +1,207 @@
+package usecases
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSearchCode(t *testing.T) {
+	cli := models.Repository{FullName: "scalingo/cli"}
+	api := models.Repository{FullName: "scalingo/api"}
+	items := []models.CodeSearchItem{
+		{CodeFile: models.CodeFile{Name: "main.go", Path: "main.go"}, Repository: cli},
+		{CodeFile: models.CodeFile{Name: "api.go", Path: "api.go"}, Repository: api},
+		{CodeFile: models.CodeFile{Name: "run.go", Path: "cmd/run.go"}, Repository: cli},
+	}
+
+	tests := map[string]struct {
+		query    string
+		mockCall func(*mockGitHubRepository)
+		wantErr  assert.ErrorAssertionFunc
+		want     *models.CodeSearchResult
+	}{
+		"grouped by repository, stats for the requested language": {
+			query: "handler language:shell",
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchCode", mock.Anything).Return(&models.CodeSearchResponse{TotalCount: 3, Items: items}, nil)
+				m.On("GetLanguages", "scalingo/cli", "token").Return(models.Languages{"Go": 300, "Shell": 100}, nil)
+				m.On("GetLanguages", "scalingo/api", "token").Return(models.Languages{"Go": 100}, nil)
+			},
+			wantErr: assert.NoError,
+			want: &models.CodeSearchResult{
+				TotalCount: 3,
+				PerPage:    "100",
+				Page:       "1",
+				Repositories: []models.CodeRepository{
+					{
+						Repository: models.Repository{
+							FullName:      "scalingo/cli",
+							Languages:     models.Languages{"Go": 300, "Shell": 100},
+							LanguageStats: &models.LanguageStats{TotalBytes: 400, RequestedBytes: 100, RequestedShare: 25, Rank: 2, Percentages: map[string]float64{"Go": 75, "Shell": 25}},
+						},
+						Files: []models.CodeFile{{Name: "main.go", Path: "main.go"}, {Name: "run.go", Path: "cmd/run.go"}},
+					},
+					{
+						Repository: models.Repository{FullName: "scalingo/api", Languages: models.Languages{}},
+						Files:      []models.CodeFile{{Name: "api.go", Path: "api.go"}},
+					},
+				},
+			},
+		},
+		"stats for the primary language without language qualifier": {
+			query: "handler",
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchCode", mock.Anything).Return(&models.CodeSearchResponse{TotalCount: 1, Items: items[1:2]}, nil)
+				m.On("GetLanguages", "scalingo/api", "token").Return(models.Languages{"Go": 100}, nil)
+			},
+			wantErr: assert.NoError,
+			want: &models.CodeSearchResult{
+				TotalCount: 1,
+				PerPage:    "100",
+				Page:       "1",
+				Repositories: []models.CodeRepository{{
+					Repository: models.Repository{
+						FullName:      "scalingo/api",
+						Languages:     models.Languages{"Go": 100},
+						LanguageStats: &models.LanguageStats{TotalBytes: 100, RequestedBytes: 100, RequestedShare: 100, IsPrimary: true, Rank: 1, Percentages: map[string]float64{"Go": 100}},
+					},
+					Files: []models.CodeFile{{Name: "api.go", Path: "api.go"}},
+				}},
+			},
+		},
+		"no result": {
+			query: "handler",
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchCode", mock.Anything).Return(&models.CodeSearchResponse{Items: []models.CodeSearchItem{}}, nil)
+			},
+			wantErr: assert.NoError,
+			want:    &models.CodeSearchResult{PerPage: "100", Page: "1", Repositories: []models.CodeRepository{}},
+		},
+		"languages fail, return error": {
+			query: "handler",
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchCode", mock.Anything).Return(&models.CodeSearchResponse{TotalCount: 1, Items: items[1:2]}, nil)
+				m.On("GetLanguages", "scalingo/api", "token").Return(models.Languages(nil), errors.New("api error"))
+			},
+			wantErr: assert.Error,
+		},
+		"search fails, return error": {
+			query: "handler",
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchCode", mock.Anything).Return((*models.CodeSearchResponse)(nil), errors.New("api error"))
+			},
+			wantErr: assert.Error,
+		},
+		"invalid query, return error": {
+			query:    "language:go",
+			mockCall: func(m *mockGitHubRepository) {},
+			wantErr:  assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := new(mockGitHubRepository)
+			tt.mockCall(m)
+
+			params := &models.CodeSearchParams{Query: tt.query, PerPage: "100", Page: "1", Header: "token", LanguagesMode: models.LanguagesModeAll}
+			result, err := NewRepositoryUseCase(m, testCursorSecret).(*repositoryUseCase).SearchCode(params)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, result)
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestValidateCodeQuery(t *testing.T) {
+	tests := map[string]struct {
+		query        string
+		wantLanguage string
+		wantErr      assert.ErrorAssertionFunc
+	}{
+		"terms only": {
+			query:   "http handler",
+			wantErr: assert.NoError,
+		},
+		"every qualifier": {
+			query:        "handler path:src/api filename:server.go extension:go language:go repo:scalingo/cli org:Scalingo",
+			wantLanguage: "go",
+			wantErr:      assert.NoError,
+		},
+		"negated qualifiers": {
+			query:   "handler -path:vendor -language:javascript",
+			wantErr: assert.NoError,
+		},
+		"term with double colons": {
+			query:        "std::vector language:cpp",
+			wantLanguage: "cpp",
+			wantErr:      assert.NoError,
+		},
+		"term with a scheme": {
+			query:   "http:// path:src",
+			wantErr: assert.NoError,
+		},
+		"term with a non alphabetic name": {
+			query:   "utf8:decode",
+			wantErr: assert.NoError,
+		},
+		"quoted phrase with a colon": {
+			query:        `"a:b" language:go`,
+			wantLanguage: "go",
+			wantErr:      assert.NoError,
+		},
+		"quoted phrase with spaces": {
+			query:   `"type Foo: struct" repo:scalingo/cli`,
+			wantErr: assert.NoError,
+		},
+		"qualifiers only, return error": {
+			query:   "language:go org:scalingo",
+			wantErr: assert.Error,
+		},
+		"empty, return error": {
+			query:   "",
+			wantErr: assert.Error,
+		},
+		"repository qualifier, searched as a term": {
+			query:   "handler stars:>10",
+			wantErr: assert.NoError,
+		},
+		"unknown word before a colon, searched as a term": {
+			query:        "TODO:fix language:go",
+			wantLanguage: "go",
+			wantErr:      assert.NoError,
+		},
+		"repo without owner, return error": {
+			query:   "handler repo:cli",
+			wantErr: assert.Error,
+		},
+		"invalid org, return error": {
+			query:   "handler org:-scalingo",
+			wantErr: assert.Error,
+		},
+		"extension with its dot, return error": {
+			query:   "handler extension:.go",
+			wantErr: assert.Error,
+		},
+		"filename with a directory, return error": {
+			query:   "handler filename:cmd/main.go",
+			wantErr: assert.Error,
+		},
+		"empty path, return error": {
+			query:   "handler path:",
+			wantErr: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			language, err := validateCodeQuery(tt.query)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.wantLanguage, language)
+		})
+	}
+}
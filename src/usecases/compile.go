@@ -0,0 +1,161 @@
+package usecases
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// CompileQuery turns a structured search into a q string
+// The result still has to go through ValidateQuery, like any q string.
+func (ru *repositoryUseCase) CompileQuery(search *models.StructuredSearch) (string, error) {
+	if strings.Contains(search.Text, ":") {
+		return "", fmt.Errorf("text cannot contain qualifiers, use the dedicated fields")
+	}
+
+	// Repositories are enriched and filtered for a single requested language
+	if len(search.Language) != 1 {
+		return "", fmt.Errorf("language must contain exactly one language")
+	}
+
+	if err := validateQualifierValue("language", search.Language[0]); err != nil {
+		return "", err
+	}
+
+	parts := strings.Fields(search.Text)
+	parts = append(parts, "language:"+search.Language[0])
+
+	if search.License != "" {
+		if err := validateQualifierValue("license", search.License); err != nil {
+			return "", err
+		}
+		parts = append(parts, "license:"+search.License)
+	}
+
+	numbers := []struct {
+		qualifier string
+		filter    *models.NumberFilter
+	}{
+		{qualifier: "stars", filter: search.Stars},
+		{qualifier: "forks", filter: search.Forks},
+		{qualifier: "size", filter: search.Size},
+		{qualifier: "followers", filter: search.Followers},
+		{qualifier: "topics", filter: search.Topics},
+	}
+
+	for _, number := range numbers {
+		if number.filter == nil {
+			continue
+		}
+
+		value, err := compileNumberFilter(number.qualifier, number.filter)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, number.qualifier+":"+value)
+	}
+
+	dates := []struct {
+		qualifier string
+		filter    *models.DateFilter
+	}{
+		{qualifier: "created", filter: search.Created},
+		{qualifier: "pushed", filter: search.Pushed},
+	}
+
+	for _, date := range dates {
+		if date.filter == nil {
+			continue
+		}
+
+		value, err := compileDateFilter(date.qualifier, date.filter)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, date.qualifier+":"+value)
+	}
+
+	return strings.Join(parts, " "), nil
+}
+
+// validateQualifierValue verifies a value is compiled into a single qualifier
+// A space, a colon or a leading - would add or negate other qualifiers.
+func validateQualifierValue(qualifier, value string) error {
+	if value == "" {
+		return fmt.Errorf("%s cannot be empty", qualifier)
+	}
+	if strings.HasPrefix(value, "-") || strings.Contains(value, ":") || strings.IndexFunc(value, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("%s cannot contain spaces or colons, nor start with -, got '%s'", qualifier, value)
+	}
+	return nil
+}
+
+// compileNumberFilter returns the value of a numeric qualifier
+// Values are integers, so exclusive bounds are turned into inclusive ones to be combined in a range.
+func compileNumberFilter(qualifier string, filter *models.NumberFilter) (string, error) {
+	hasBounds := filter.Gt != nil || filter.Gte != nil || filter.Lt != nil || filter.Lte != nil
+
+	if filter.Eq != nil {
+		if hasBounds {
+			return "", fmt.Errorf("%s: eq cannot be combined with other bounds", qualifier)
+		}
+		return strconv.Itoa(*filter.Eq), nil
+	}
+
+	if !hasBounds {
+		return "", fmt.Errorf("%s: at least one bound is required", qualifier)
+	}
+
+	if filter.Gt != nil && filter.Gte != nil || filter.Lt != nil && filter.Lte != nil {
+		return "", fmt.Errorf("%s: gt and gte, or lt and lte, cannot be combined", qualifier)
+	}
+
+	var lower, upper *int
+	switch {
+	case filter.Gte != nil:
+		lower = filter.Gte
+	case filter.Gt != nil:
+		v := *filter.Gt + 1
+		lower = &v
+	}
+
+	switch {
+	case filter.Lte != nil:
+		upper = filter.Lte
+	case filter.Lt != nil:
+		v := *filter.Lt - 1
+		upper = &v
+	}
+
+	switch {
+	case lower != nil && upper != nil:
+		if *lower > *upper {
+			return "", fmt.Errorf("%s: no value is within the bounds", qualifier)
+		}
+		if *lower == *upper {
+			return strconv.Itoa(*lower), nil
+		}
+		return fmt.Sprintf("%d..%d", *lower, *upper), nil
+	case lower != nil:
+		return ">=" + strconv.Itoa(*lower), nil
+	default:
+		return "<=" + strconv.Itoa(*upper), nil
+	}
+}
+
+// compileDateFilter returns the value of a date qualifier
+func compileDateFilter(qualifier string, filter *models.DateFilter) (string, error) {
+	switch {
+	case filter.From != "" && filter.To != "":
+		return filter.From + ".." + filter.To, nil
+	case filter.From != "":
+		return ">=" + filter.From, nil
+	case filter.To != "":
+		return "<=" + filter.To, nil
+	default:
+		return "", fmt.Errorf("%s: from or to is required", qualifier)
+	}
+}
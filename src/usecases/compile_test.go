@@ -0,0 +1,114 @@
+package usecases
+
+import (
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestCompileQuery(t *testing.T) {
+	tests := map[string]struct {
+		search    *models.StructuredSearch
+		wantQuery string
+		wantErr   assert.ErrorAssertionFunc
+	}{
+		"nominal": {
+			search: &models.StructuredSearch{
+				Text:     "tetris game",
+				Language: []string{"go"},
+				Stars:    &models.NumberFilter{Gte: intPtr(10)},
+				Created:  &models.DateFilter{From: "2023-01-01"},
+			},
+			wantQuery: "tetris game language:go stars:>=10 created:>=2023-01-01",
+			wantErr:   assert.NoError,
+		},
+		"every filter": {
+			search: &models.StructuredSearch{
+				Language:  []string{"rust"},
+				License:   "mit",
+				Stars:     &models.NumberFilter{Eq: intPtr(5)},
+				Forks:     &models.NumberFilter{Gt: intPtr(1), Lt: intPtr(10)},
+				Size:      &models.NumberFilter{Lte: intPtr(100)},
+				Followers: &models.NumberFilter{Gt: intPtr(3), Lte: intPtr(4)},
+				Topics:    &models.NumberFilter{Gte: intPtr(2), Lte: intPtr(2)},
+				Created:   &models.DateFilter{From: "2023-01-01", To: "2023-12-31"},
+				Pushed:    &models.DateFilter{To: "2024-01-01"},
+			},
+			wantQuery: "language:rust license:mit stars:5 forks:2..9 size:<=100 followers:4 topics:2 created:2023-01-01..2023-12-31 pushed:<=2024-01-01",
+			wantErr:   assert.NoError,
+		},
+		"qualifier in text, return error": {
+			search:  &models.StructuredSearch{Text: "stars:10", Language: []string{"go"}},
+			wantErr: assert.Error,
+		},
+		"qualifier injected in language, return error": {
+			search:  &models.StructuredSearch{Language: []string{"go stars:>1000"}},
+			wantErr: assert.Error,
+		},
+		"negated language, return error": {
+			search:  &models.StructuredSearch{Language: []string{"-go"}},
+			wantErr: assert.Error,
+		},
+		"empty language, return error": {
+			search:  &models.StructuredSearch{Language: []string{""}},
+			wantErr: assert.Error,
+		},
+		"qualifier injected in license, return error": {
+			search:  &models.StructuredSearch{Language: []string{"go"}, License: "mit\tuser:scalingo"},
+			wantErr: assert.Error,
+		},
+		"colon in license, return error": {
+			search:  &models.StructuredSearch{Language: []string{"go"}, License: "mit:archived"},
+			wantErr: assert.Error,
+		},
+		"no language, return error": {
+			search:  &models.StructuredSearch{Text: "tetris"},
+			wantErr: assert.Error,
+		},
+		"several languages, return error": {
+			search:  &models.StructuredSearch{Language: []string{"go", "rust"}},
+			wantErr: assert.Error,
+		},
+		"eq with bounds, return error": {
+			search:  &models.StructuredSearch{Language: []string{"go"}, Stars: &models.NumberFilter{Eq: intPtr(1), Gt: intPtr(0)}},
+			wantErr: assert.Error,
+		},
+		"gt and gte, return error": {
+			search:  &models.StructuredSearch{Language: []string{"go"}, Stars: &models.NumberFilter{Gt: intPtr(1), Gte: intPtr(1)}},
+			wantErr: assert.Error,
+		},
+		"empty bounds, return error": {
+			search:  &models.StructuredSearch{Language: []string{"go"}, Stars: &models.NumberFilter{Lt: intPtr(5), Gt: intPtr(4)}},
+			wantErr: assert.Error,
+		},
+		"no bound, return error": {
+			search:  &models.StructuredSearch{Language: []string{"go"}, Size: &models.NumberFilter{}},
+			wantErr: assert.Error,
+		},
+		"no date, return error": {
+			search:  &models.StructuredSearch{Language: []string{"go"}, Pushed: &models.DateFilter{}},
+			wantErr: assert.Error,
+		},
+	}
+
+	ru := NewRepositoryUseCase(nil, testCursorSecret)
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			query, err := ru.CompileQuery(tt.search)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.wantQuery, query)
+
+			// A compiled query is always accepted by ValidateQuery
+			if err == nil {
+				_, err = ru.ValidateQuery(query)
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
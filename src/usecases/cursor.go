@@ -0,0 +1,135 @@
+package usecases
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// pageCursor records a whole search: the normalized query with its options, the GitHub page
+// and, for filled pages, the index of the next repository in this page
+type pageCursor struct {
+	Query         string   `json:"q"`
+	Sort          string   `json:"s,omitempty"`
+	Order         string   `json:"or,omitempty"`
+	LanguagesMode string   `json:"l,omitempty"`
+	Fill          bool     `json:"f,omitempty"`
+	Include       []string `json:"i,omitempty"`
+	Highlight     bool     `json:"h,omitempty"`
+	PerPage       int      `json:"pp"`
+	Page          int      `json:"p"`
+	Offset        int      `json:"o,omitempty"`
+}
+
+// errInvalidCursor is returned for any cursor we did not issue, the reason is not disclosed
+var errInvalidCursor = fmt.Errorf("invalid cursor")
+
+// cursorCodec signs cursors so clients cannot forge them to reach other queries or pages
+type cursorCodec struct {
+	secret []byte
+}
+
+// newCursorCodec creates a codec, a random secret is used when none is configured
+func newCursorCodec(secret []byte) *cursorCodec {
+	if len(secret) == 0 {
+		log.Print("no cursor secret configured, cursors will not survive a restart")
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			log.Fatal("fail to generate cursor secret: ", err)
+		}
+	}
+	return &cursorCodec{secret: secret}
+}
+
+func (cc *cursorCodec) sign(payload string) string {
+	mac := hmac.New(sha256.New, cc.secret)
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// encode makes the cursor opaque for clients: base64 payload and its signature separated by a dot
+func (cc *cursorCodec) encode(c pageCursor) string {
+	// Marshalling a struct of strings, ints and bools cannot fail
+	raw, _ := json.Marshal(c)
+	payload := base64.RawURLEncoding.EncodeToString(raw)
+	return payload + "." + cc.sign(payload)
+}
+
+// decode verifies and reads a cursor sent back by a client
+func (cc *cursorCodec) decode(s string) (pageCursor, error) {
+	payload, signature, found := strings.Cut(s, ".")
+	if !found || !hmac.Equal([]byte(signature), []byte(cc.sign(payload))) {
+		return pageCursor{}, errInvalidCursor
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return pageCursor{}, errInvalidCursor
+	}
+
+	var c pageCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return pageCursor{}, errInvalidCursor
+	}
+
+	if c.Page < 1 || c.Offset < 0 || c.PerPage < 1 {
+		return pageCursor{}, errInvalidCursor
+	}
+
+	return c, nil
+}
+
+// newPageCursor builds the cursor of the given search, pointing to its first repository
+func newPageCursor(rsp *models.RepositorySearchParams) pageCursor {
+	perPage, _ := strconv.Atoi(rsp.PerPage)
+	page, _ := strconv.Atoi(rsp.Page)
+
+	return pageCursor{
+		Query:         normalizeQuery(rsp.Query),
+		Sort:          rsp.Sort,
+		Order:         rsp.Order,
+		LanguagesMode: rsp.LanguagesMode,
+		Fill:          rsp.Fill,
+		Include:       rsp.Include,
+		Highlight:     rsp.Highlight,
+		PerPage:       perPage,
+		Page:          page,
+	}
+}
+
+// at returns a copy of the cursor moved to another position
+func (c pageCursor) at(page, offset int) pageCursor {
+	c.Page = page
+	c.Offset = offset
+	return c
+}
+
+// within tells if the cursor still points to a result GitHub can return
+func (c pageCursor) within(totalCount int) bool {
+	position := (c.Page-1)*c.PerPage + c.Offset
+	return position < totalCount && position < models.SearchResultsLimit
+}
+
+// lastPage is the last page GitHub can return for this search
+func lastPage(perPage, totalCount int) int {
+	if totalCount > models.SearchResultsLimit {
+		totalCount = models.SearchResultsLimit
+	}
+	if perPage < 1 || totalCount == 0 {
+		return 1
+	}
+	return (totalCount + perPage - 1) / perPage
+}
+
+// normalizeQuery removes the extra whitespaces of a query so equivalent queries share cursors
+func normalizeQuery(q string) string {
+	return strings.Join(strings.Fields(q), " ")
+}
@@ -0,0 +1,159 @@
+package usecases
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCursorCodec(t *testing.T) {
+	assert.Equal(t, []byte("secret"), newCursorCodec([]byte("secret")).secret)
+	assert.Len(t, newCursorCodec(nil).secret, 32)
+}
+
+func TestEncodeDecodeCursor(t *testing.T) {
+	codec := newCursorCodec([]byte("secret"))
+	cursor := pageCursor{Query: "tetris language:go", Sort: "stars", Order: "asc", PerPage: 50, Page: 3, Offset: 42}
+
+	decoded, err := codec.decode(codec.encode(cursor))
+	assert.NoError(t, err)
+	assert.Equal(t, cursor, decoded)
+}
+
+func TestDecodeCursor(t *testing.T) {
+	codec := newCursorCodec([]byte("secret"))
+	valid := codec.encode(pageCursor{Query: "language:go", PerPage: 10, Page: 1})
+	payload, signature, _ := strings.Cut(valid, ".")
+	otherPayload, _, _ := strings.Cut(codec.encode(pageCursor{Query: "language:rust", PerPage: 10, Page: 1}), ".")
+
+	tests := map[string]struct {
+		cursor    string
+		wantError assert.ErrorAssertionFunc
+	}{
+		"valid cursor": {
+			cursor:    valid,
+			wantError: assert.NoError,
+		},
+		"unsigned cursor, return error": {
+			cursor:    payload,
+			wantError: assert.Error,
+		},
+		"signed with another secret, return error": {
+			cursor:    newCursorCodec([]byte("other")).encode(pageCursor{Query: "language:go", PerPage: 10, Page: 1}),
+			wantError: assert.Error,
+		},
+		"tampered payload, return error": {
+			cursor:    otherPayload + "." + signature,
+			wantError: assert.Error,
+		},
+		"not base64, return error": {
+			cursor:    "not a cursor!." + codec.sign("not a cursor!"),
+			wantError: assert.Error,
+		},
+		"not json, return error": {
+			cursor:    "bm90IGpzb24." + codec.sign("bm90IGpzb24"),
+			wantError: assert.Error,
+		},
+		"invalid page, return error": {
+			cursor:    codec.encode(pageCursor{PerPage: 10, Page: 0}),
+			wantError: assert.Error,
+		},
+		"negative offset, return error": {
+			cursor:    codec.encode(pageCursor{PerPage: 10, Page: 1, Offset: -1}),
+			wantError: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := codec.decode(tt.cursor)
+			tt.wantError(t, err)
+		})
+	}
+}
+
+func TestNewPageCursor(t *testing.T) {
+	cursor := newPageCursor(&models.RepositorySearchParams{
+		Query:         "  tetris   language:go ",
+		PerPage:       "20",
+		Page:          "4",
+		Sort:          "stars",
+		Order:         "desc",
+		LanguagesMode: models.LanguagesModeAll,
+		Include:       []string{models.IncludeActivity},
+		Highlight:     true,
+	})
+
+	assert.Equal(t, pageCursor{
+		Query:         "tetris language:go",
+		Sort:          "stars",
+		Order:         "desc",
+		LanguagesMode: models.LanguagesModeAll,
+		Include:       []string{models.IncludeActivity},
+		Highlight:     true,
+		PerPage:       20,
+		Page:          4,
+	}, cursor)
+}
+
+func TestCursorWithin(t *testing.T) {
+	tests := map[string]struct {
+		cursor     pageCursor
+		totalCount int
+		expected   bool
+	}{
+		"inside results": {
+			cursor:     pageCursor{PerPage: 10, Page: 2, Offset: 3},
+			totalCount: 50,
+			expected:   true,
+		},
+		"after last result": {
+			cursor:     pageCursor{PerPage: 10, Page: 6},
+			totalCount: 50,
+			expected:   false,
+		},
+		"after search window": {
+			cursor:     pageCursor{PerPage: 100, Page: 11},
+			totalCount: 5000,
+			expected:   false,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.cursor.within(tt.totalCount))
+		})
+	}
+}
+
+func TestLastPage(t *testing.T) {
+	tests := map[string]struct {
+		perPage    int
+		totalCount int
+		expected   int
+	}{
+		"partial last page": {
+			perPage:    30,
+			totalCount: 95,
+			expected:   4,
+		},
+		"capped by search window": {
+			perPage:    30,
+			totalCount: 5000,
+			expected:   34,
+		},
+		"no results": {
+			perPage:    30,
+			totalCount: 0,
+			expected:   1,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, lastPage(tt.perPage, tt.totalCount))
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package usecases
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// githubCreation is the first day a repository can have been created on GitHub
+var githubCreation = time.Date(2008, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// now is replaced in tests to get stable date windows
+var now = time.Now
+
+// dateWindow is a range of days, both ends included
+type dateWindow struct {
+	from time.Time
+	to   time.Time
+}
+
+// String returns the window with the range syntax of GitHub search
+func (w dateWindow) String() string {
+	if w.from.Equal(w.to) {
+		return w.from.Format(dateLayout)
+	}
+	return w.from.Format(dateLayout) + ".." + w.to.Format(dateLayout)
+}
+
+// days is the number of days between both ends of the window
+func (w dateWindow) days() int {
+	return int(w.to.Sub(w.from).Hours() / 24)
+}
+
+// bisect splits the window in two halves, it must span more than a single day
+func (w dateWindow) bisect() (dateWindow, dateWindow) {
+	mid := w.from.AddDate(0, 0, w.days()/2)
+	return dateWindow{from: w.from, to: mid}, dateWindow{from: mid.AddDate(0, 0, 1), to: w.to}
+}
+
+// today is the current day, without time
+func today() time.Time {
+	y, m, d := now().UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// parseDateBounds converts a date filter value (2024-01-01, >=2024-01-01, 2024-01-01..2024-12-31, 2024-01-01..*)
+// into the window of days it matches
+func parseDateBounds(value string) (dateWindow, error) {
+	w := dateWindow{from: githubCreation, to: today()}
+
+	if start, end, found := strings.Cut(value, ".."); found {
+		if start == "*" && end == "*" {
+			return w, fmt.Errorf("date range cannot be open on both ends, got '%s'", value)
+		}
+
+		var err error
+		if start != "*" {
+			if w.from, err = time.Parse(dateLayout, start); err != nil {
+				return w, fmt.Errorf("date range must contain valid dates in YYYY-MM-DD format, got '%s'", value)
+			}
+		}
+		if end != "*" {
+			if w.to, err = time.Parse(dateLayout, end); err != nil {
+				return w, fmt.Errorf("date range must contain valid dates in YYYY-MM-DD format, got '%s'", value)
+			}
+		}
+
+		if w.to.Before(w.from) {
+			return w, fmt.Errorf("date range start must be before end, got '%s'", value)
+		}
+		return w, nil
+	}
+
+	date, err := time.Parse(dateLayout, extractValue(value))
+	if err != nil {
+		return w, fmt.Errorf("date must be in YYYY-MM-DD format, got '%s'", value)
+	}
+
+	switch {
+	case strings.HasPrefix(value, ">="):
+		w.from = date
+	case strings.HasPrefix(value, ">"):
+		w.from = date.AddDate(0, 0, 1)
+	case strings.HasPrefix(value, "<="):
+		w.to = date
+	case strings.HasPrefix(value, "<"):
+		w.to = date.AddDate(0, 0, -1)
+	default:
+		w.from, w.to = date, date
+	}
+
+	return w, nil
+}
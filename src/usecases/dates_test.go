@@ -0,0 +1,105 @@
+package usecases
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func day(s string) time.Time {
+	d, _ := time.Parse(dateLayout, s)
+	return d
+}
+
+func TestDateWindow(t *testing.T) {
+	w := dateWindow{from: day("2024-01-01"), to: day("2024-01-04")}
+	assert.Equal(t, "2024-01-01..2024-01-04", w.String())
+	assert.Equal(t, 3, w.days())
+
+	left, right := w.bisect()
+	assert.Equal(t, dateWindow{from: day("2024-01-01"), to: day("2024-01-02")}, left)
+	assert.Equal(t, dateWindow{from: day("2024-01-03"), to: day("2024-01-04")}, right)
+
+	single := dateWindow{from: day("2024-01-01"), to: day("2024-01-01")}
+	assert.Equal(t, "2024-01-01", single.String())
+	assert.Equal(t, 0, single.days())
+}
+
+func TestParseDateBounds(t *testing.T) {
+	now = func() time.Time { return time.Date(2024, 6, 15, 13, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	tests := map[string]struct {
+		value     string
+		want      dateWindow
+		wantError assert.ErrorAssertionFunc
+	}{
+		"single day": {
+			value:     "2024-01-01",
+			want:      dateWindow{from: day("2024-01-01"), to: day("2024-01-01")},
+			wantError: assert.NoError,
+		},
+		"range": {
+			value:     "2023-01-01..2023-12-31",
+			want:      dateWindow{from: day("2023-01-01"), to: day("2023-12-31")},
+			wantError: assert.NoError,
+		},
+		"range open on end": {
+			value:     "2024-01-01..*",
+			want:      dateWindow{from: day("2024-01-01"), to: day("2024-06-15")},
+			wantError: assert.NoError,
+		},
+		"range open on start": {
+			value:     "*..2010-01-01",
+			want:      dateWindow{from: githubCreation, to: day("2010-01-01")},
+			wantError: assert.NoError,
+		},
+		"greater than": {
+			value:     ">2024-01-01",
+			want:      dateWindow{from: day("2024-01-02"), to: day("2024-06-15")},
+			wantError: assert.NoError,
+		},
+		"greater or equal": {
+			value:     ">=2024-01-01",
+			want:      dateWindow{from: day("2024-01-01"), to: day("2024-06-15")},
+			wantError: assert.NoError,
+		},
+		"less than": {
+			value:     "<2024-01-01",
+			want:      dateWindow{from: githubCreation, to: day("2023-12-31")},
+			wantError: assert.NoError,
+		},
+		"less or equal": {
+			value:     "<=2024-01-01",
+			want:      dateWindow{from: githubCreation, to: day("2024-01-01")},
+			wantError: assert.NoError,
+		},
+		"open on both ends, return error": {
+			value:     "*..*",
+			wantError: assert.Error,
+		},
+		"invalid range date, return error": {
+			value:     "2024-01-01..2024-13-01",
+			wantError: assert.Error,
+		},
+		"reversed range, return error": {
+			value:     "2024-02-01..2024-01-01",
+			wantError: assert.Error,
+		},
+		"not a date, return error": {
+			value:     "yesterday",
+			wantError: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			w, err := parseDateBounds(tt.value)
+			tt.wantError(t, err)
+			if err == nil {
+				assert.Equal(t, tt.want, w)
+			}
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package usecases
+
+import (
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// splitLocalQualifiers removes the local qualifiers from a query, and returns the packages of its depends_on qualifiers
+func splitLocalQualifiers(q string) (github string, dependsOn []string) {
+	parts := make([]string, 0)
+	for _, part := range strings.Fields(q) {
+		qualifier, value, found := strings.Cut(part, ":")
+		if !found || !qualifiers[qualifier].local {
+			parts = append(parts, part)
+			continue
+		}
+
+		if qualifier == models.QualifierDependsOn {
+			_, normalized := normalizeEqual(value)
+			dependsOn = append(dependsOn, normalized)
+		}
+	}
+
+	return strings.Join(parts, " "), dependsOn
+}
+
+// declaredDependencies are the dependencies of the SBOM and of the manifests of a repository
+func declaredDependencies(repo *models.Repository) []models.Dependency {
+	dependencies := repo.Dependencies
+	for _, manifest := range repo.Manifests {
+		dependencies = append(dependencies, manifest.Dependencies...)
+	}
+	return dependencies
+}
+
+// matchesDependencies tells whether the dependencies contain every package, regardless of case
+// Several depends_on qualifiers must all match, like GitHub qualifiers.
+func matchesDependencies(dependencies []models.Dependency, packages []string) bool {
+	for _, pkg := range packages {
+		found := false
+		for _, dependency := range dependencies {
+			if strings.EqualFold(dependency.Name, pkg) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,31 @@
+package usecases
+
+import (
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitLocalQualifiers(t *testing.T) {
+	github, dependsOn := splitLocalQualifiers("cli language:go depends_on:github.com/Pkg/errors stars:>10 depends_on:golang.org/x/sync")
+	assert.Equal(t, "cli language:go stars:>10", github)
+	assert.Equal(t, []string{"github.com/pkg/errors", "golang.org/x/sync"}, dependsOn)
+
+	github, dependsOn = splitLocalQualifiers("language:go")
+	assert.Equal(t, "language:go", github)
+	assert.Empty(t, dependsOn)
+}
+
+func TestMatchesDependencies(t *testing.T) {
+	dependencies := []models.Dependency{
+		{Ecosystem: "golang", Name: "github.com/pkg/errors"},
+		{Ecosystem: "golang", Name: "golang.org/x/sync"},
+	}
+
+	assert.True(t, matchesDependencies(dependencies, nil))
+	assert.True(t, matchesDependencies(dependencies, []string{"github.com/PKG/errors"}))
+	assert.True(t, matchesDependencies(dependencies, []string{"github.com/pkg/errors", "golang.org/x/sync"}))
+	assert.False(t, matchesDependencies(dependencies, []string{"github.com/pkg/errors", "github.com/sirupsen/logrus"}))
+	assert.False(t, matchesDependencies(nil, []string{"github.com/pkg/errors"}))
+}
@@ -0,0 +1,101 @@
+package usecases
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/repositories"
+)
+
+const (
+	// maxOwnerLength and maxNameLength are the longest owner and repository names GitHub accepts
+	maxOwnerLength = 39
+	maxNameLength  = 100
+)
+
+var (
+	// ErrRepositoryNotFound is returned when GitHub does not know a repository, or hides it from the token
+	ErrRepositoryNotFound = repositories.ErrNotFound
+	// ErrRateLimited is returned when GitHub rate limits the token before any result could be computed
+	ErrRateLimited = repositories.ErrRateLimited
+)
+
+// GetRepository fetches a repository and enriches it like a search hit
+// The repository is fetched first, so its languages are cached under the full name GitHub returns.
+// Stats are computed for the given language, or for the primary language of the repository.
+func (ru *repositoryUseCase) GetRepository(params *models.RepositoryParams) (*models.Repository, error) {
+	if err := validateRepositoryName(params.Owner, params.Name); err != nil {
+		return nil, err
+	}
+
+	repo, err := ru.gr.GetRepository(params.Owner, params.Name, params.Header)
+	if err != nil {
+		log.Print("error fetching repository ", params.Owner, "/", params.Name, ": ", err)
+		return nil, fmt.Errorf("error fetching repository %s/%s: %w", params.Owner, params.Name, err)
+	}
+
+	languages, inferred, err := ru.getLanguages(repo, params.Header)
+	if err != nil {
+		log.Print("error fetching languages for ", repo.FullName, ": ", err)
+		return nil, fmt.Errorf("error fetching languages for %s: %w", repo.FullName, err)
+	}
+
+	setLanguageStats(repo, languages, inferred, params.Language, params.LanguagesMode)
+	return repo, nil
+}
+
+// setLanguageStats sets the languages of a repository and their stats for the requested language, or for its primary language
+// A repository without the requested language keeps empty languages.
+func setLanguageStats(repo *models.Repository, languages models.Languages, inferred bool, requested, mode string) {
+	if requested == "" {
+		if ranked := rankLanguages(languages); len(ranked) > 0 {
+			requested = ranked[0].name
+		}
+	}
+
+	repo.Languages = models.Languages{}
+	repo.LanguageStats = nil
+	if filtered, stats := buildLanguageStats(languages, requested, mode); filtered != nil {
+		repo.Languages = filtered
+		repo.LanguageStats = stats
+		repo.LanguageStats.Inferred = inferred
+	}
+}
+
+// validateRepositoryName verifies the owner and the name of a repository with the characters GitHub allows
+// Owners are alphanumeric with single inner hyphens, names may also contain dots and underscores.
+func validateRepositoryName(owner, name string) error {
+	if err := validateOwner(owner); err != nil {
+		return err
+	}
+
+	if name == "" || len(name) > maxNameLength || name == "." || name == ".." {
+		return fmt.Errorf("invalid repository name %q", name)
+	}
+	for _, r := range name {
+		if !isAlphanumeric(r) && r != '-' && r != '_' && r != '.' {
+			return fmt.Errorf("invalid repository name %q", name)
+		}
+	}
+
+	return nil
+}
+
+func isAlphanumeric(r rune) bool {
+	return r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+}
+
+// validateOwner verifies the login of a user or an organization
+func validateOwner(owner string) error {
+	if owner == "" || len(owner) > maxOwnerLength || strings.HasPrefix(owner, "-") || strings.HasSuffix(owner, "-") || strings.Contains(owner, "--") {
+		return fmt.Errorf("invalid repository owner %q", owner)
+	}
+	for _, r := range owner {
+		if !isAlphanumeric(r) && r != '-' {
+			return fmt.Errorf("invalid repository owner %q", owner)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package usecases
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRepository(t *testing.T) {
+	languages := models.Languages{"Go": 300, "Shell": 100}
+
+	tests := map[string]struct {
+		params   *models.RepositoryParams
+		mockCall func(*mockGitHubRepository)
+		wantErr  assert.ErrorAssertionFunc
+		want     *models.Repository
+	}{
+		"primary language": {
+			params: &models.RepositoryParams{Owner: "Scalingo", Name: "cli", Header: "token", LanguagesMode: models.LanguagesModeAll},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetRepository", "Scalingo", "cli", "token").Return(&models.Repository{FullName: "scalingo/cli"}, nil)
+				m.On("GetLanguages", "scalingo/cli", "token").Return(languages, nil)
+			},
+			wantErr: assert.NoError,
+			want: &models.Repository{
+				FullName:  "scalingo/cli",
+				Languages: languages,
+				LanguageStats: &models.LanguageStats{
+					TotalBytes:     400,
+					RequestedBytes: 300,
+					RequestedShare: 75,
+					IsPrimary:      true,
+					Rank:           1,
+					Percentages:    map[string]float64{"Go": 75, "Shell": 25},
+				},
+			},
+		},
+		"requested language": {
+			params: &models.RepositoryParams{Owner: "scalingo", Name: "cli", Header: "token", Language: "shell", LanguagesMode: models.LanguagesModeRequested},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetRepository", "scalingo", "cli", "token").Return(&models.Repository{FullName: "scalingo/cli"}, nil)
+				m.On("GetLanguages", "scalingo/cli", "token").Return(languages, nil)
+			},
+			wantErr: assert.NoError,
+			want: &models.Repository{
+				FullName:  "scalingo/cli",
+				Languages: models.Languages{"Shell": 100},
+				LanguageStats: &models.LanguageStats{
+					TotalBytes:     400,
+					RequestedBytes: 100,
+					RequestedShare: 25,
+					Rank:           2,
+					Percentages:    map[string]float64{"Shell": 25},
+				},
+			},
+		},
+		"no languages, inferred from the tree": {
+			params: &models.RepositoryParams{Owner: "scalingo", Name: "cli", Header: "token", LanguagesMode: models.LanguagesModeAll},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetRepository", "scalingo", "cli", "token").Return(&models.Repository{FullName: "scalingo/cli", DefaultBranch: "main"}, nil)
+				m.On("GetLanguages", "scalingo/cli", "token").Return(models.Languages{}, nil)
+				m.On("GetTree", "scalingo/cli", "main", "token").Return(&models.Tree{Entries: []models.TreeEntry{
+					{Path: "main.go", Type: "blob", Size: 300},
+					{Path: "cmd", Type: "tree"},
+					{Path: "cmd/run.sh", Type: "blob", Size: 100},
+				}}, nil)
+			},
+			wantErr: assert.NoError,
+			want: &models.Repository{
+				FullName:      "scalingo/cli",
+				DefaultBranch: "main",
+				Languages:     models.Languages{"Go": 300, "Shell": 100},
+				LanguageStats: &models.LanguageStats{
+					TotalBytes:     400,
+					RequestedBytes: 300,
+					RequestedShare: 75,
+					IsPrimary:      true,
+					Rank:           1,
+					Percentages:    map[string]float64{"Go": 75, "Shell": 25},
+					Inferred:       true,
+				},
+			},
+		},
+		"missing language": {
+			params: &models.RepositoryParams{Owner: "scalingo", Name: "cli", Header: "token", Language: "rust", LanguagesMode: models.LanguagesModeAll},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetRepository", "scalingo", "cli", "token").Return(&models.Repository{FullName: "scalingo/cli"}, nil)
+				m.On("GetLanguages", "scalingo/cli", "token").Return(languages, nil)
+			},
+			wantErr: assert.NoError,
+			want:    &models.Repository{FullName: "scalingo/cli", Languages: models.Languages{}},
+		},
+		"not found, return error": {
+			params: &models.RepositoryParams{Owner: "scalingo", Name: "missing", Header: "token"},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetRepository", "scalingo", "missing", "token").Return((*models.Repository)(nil), fmt.Errorf("%w (status 404): Not Found", repositories.ErrNotFound))
+			},
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrRepositoryNotFound)
+			},
+		},
+		"languages fail, return error": {
+			params: &models.RepositoryParams{Owner: "scalingo", Name: "cli", Header: "token"},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetRepository", "scalingo", "cli", "token").Return(&models.Repository{FullName: "scalingo/cli"}, nil)
+				m.On("GetLanguages", "scalingo/cli", "token").Return(models.Languages(nil), assert.AnError)
+			},
+			wantErr: assert.Error,
+		},
+		"invalid name, return error": {
+			params:  &models.RepositoryParams{Owner: "scalingo", Name: ".."},
+			wantErr: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := new(mockGitHubRepository)
+			if tt.mockCall != nil {
+				tt.mockCall(m)
+			}
+
+			repo, err := NewRepositoryUseCase(m, testCursorSecret).GetRepository(tt.params)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, repo)
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetRepositoryLanguageCache(t *testing.T) {
+	m := new(mockGitHubRepository)
+	m.On("GetRepository", "scalingo", "cli", "token").Return(&models.Repository{FullName: "scalingo/cli"}, nil).Twice()
+	m.On("GetLanguages", "scalingo/cli", "token").Return(models.Languages{"Go": 1}, nil).Once()
+
+	ru := NewRepositoryUseCase(m, testCursorSecret)
+	for i := 0; i < 2; i++ {
+		_, err := ru.GetRepository(&models.RepositoryParams{Owner: "scalingo", Name: "cli", Header: "token"})
+		assert.NoError(t, err)
+	}
+
+	m.AssertExpectations(t)
+}
+
+func TestValidateRepositoryName(t *testing.T) {
+	tests := map[string]struct {
+		owner   string
+		name    string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		"nominal": {
+			owner:   "Scalingo-io",
+			name:    "sclng_backend.test-v1",
+			wantErr: assert.NoError,
+		},
+		"empty owner, return error": {
+			name:    "cli",
+			wantErr: assert.Error,
+		},
+		"owner with leading hyphen, return error": {
+			owner:   "-scalingo",
+			name:    "cli",
+			wantErr: assert.Error,
+		},
+		"owner with double hyphen, return error": {
+			owner:   "scal--ingo",
+			name:    "cli",
+			wantErr: assert.Error,
+		},
+		"owner too long, return error": {
+			owner:   "abcdefghijabcdefghijabcdefghijabcdefghij",
+			name:    "cli",
+			wantErr: assert.Error,
+		},
+		"owner with dot, return error": {
+			owner:   "scalingo.io",
+			name:    "cli",
+			wantErr: assert.Error,
+		},
+		"dot name, return error": {
+			owner:   "scalingo",
+			name:    ".",
+			wantErr: assert.Error,
+		},
+		"name with special characters, return error": {
+			owner:   "scalingo",
+			name:    "cli?page=2",
+			wantErr: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tt.wantErr(t, validateRepositoryName(tt.owner, tt.name))
+		})
+	}
+}
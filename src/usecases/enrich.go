@@ -0,0 +1,357 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/repositories"
+)
+
+// maxConcurrentEnrichments bounds the repositories a request enriches at the same time
+const maxConcurrentEnrichments = 8
+
+// ErrRepositoryDropped is returned by an enricher when the repository must be removed from the results
+var ErrRepositoryDropped = errors.New("repository dropped by the enrichment")
+
+// Enricher adds data fetched from GitHub to the repositories of a search
+type Enricher interface {
+	// Name is the value selecting the enricher in the include parameter
+	Name() string
+	// Calls is the number of GitHub calls the enricher makes for a repository, at most
+	Calls() int
+	// Required tells whether a failure fails the whole request instead of being reported on the repository
+	Required() bool
+	// Enrich adds its data to the repository
+	Enrich(ec *EnrichContext, repo *models.Repository) error
+}
+
+// EnrichContext is what the enrichers of a request share
+type EnrichContext struct {
+	GitHub repositories.GitHubRepository
+	Params *models.RepositorySearchParams
+	// Languages returns the languages of a repository and whether they were inferred, from the cache when possible
+	Languages func(repo *models.Repository, header string) (models.Languages, bool, error)
+	// Activity returns the commit activity of a repository, from the cache when possible
+	Activity func(ctx context.Context, repoFullName, header string) (*models.Activity, error)
+}
+
+// enrichers are the enrichers a request can include, by name
+var enrichers = map[string]Enricher{
+	models.IncludeLanguages:    languagesEnricher{},
+	models.IncludeTopics:       topicsEnricher{},
+	models.IncludeReleases:     releasesEnricher{},
+	models.IncludeIssues:       issuesEnricher{},
+	models.IncludeCommunity:    communityEnricher{},
+	models.IncludeActivity:     activityEnricher{},
+	models.IncludeContributors: contributorsEnricher{},
+	models.IncludeSBOM:         sbomEnricher{},
+	models.IncludeManifests:    manifestsEnricher{},
+}
+
+// includedEnrichers returns the enrichers of a request in the order of models.IncludeNames
+// Languages come first, so dropped repositories do not cost the other enrichments.
+func includedEnrichers(include []string) []Enricher {
+	if include == nil {
+		include = models.DefaultIncludes
+	}
+
+	selected := make(map[string]bool, len(include))
+	for _, name := range include {
+		selected[name] = true
+	}
+
+	included := make([]Enricher, 0, len(include))
+	for _, name := range models.IncludeNames {
+		if selected[name] {
+			included = append(included, enrichers[name])
+		}
+	}
+	return included
+}
+
+// includesLanguages tells whether the languages of the repositories are fetched
+func includesLanguages(include []string) bool {
+	return includes(includedEnrichers(include), models.IncludeLanguages)
+}
+
+// includes tells whether the named enricher is among the enrichers
+func includes(enrichers []Enricher, name string) bool {
+	for _, enricher := range enrichers {
+		if enricher.Name() == name {
+			return true
+		}
+	}
+	return false
+}
+
+// enrichmentCalls is the number of calls per repository of the included enrichers other than languages
+// Languages are left aside since the cache makes most of them free.
+func enrichmentCalls(include []string) int {
+	calls := 0
+	for _, enricher := range includedEnrichers(include) {
+		if enricher.Name() != models.IncludeLanguages {
+			calls += enricher.Calls()
+		}
+	}
+	return calls
+}
+
+// enrichRepositories runs the included enrichers on each repository, a bounded number of repositories at a time
+// The result is aligned with items, dropped repositories are nil
+func (ru *repositoryUseCase) enrichRepositories(items []models.Repository, rsp *models.RepositorySearchParams) ([]*models.Repository, error) {
+	// Each call writes only its own index, so no lock is needed and the order is kept
+	enriched := make([]*models.Repository, len(items))
+
+	err := fanOut(len(items), func(i int) error {
+		enrichedRepo, err := ru.enrichRepository(items[i], rsp)
+		if err != nil {
+			return err
+		}
+		enriched[i] = enrichedRepo
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return enriched, nil
+}
+
+// fanOut calls fn for each index from 0 to n, maxConcurrentEnrichments at a time, and returns the first error
+func fanOut(n int, fn func(i int) error) error {
+	errChan := make(chan error, n)
+	slots := make(chan struct{}, maxConcurrentEnrichments)
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		i := i
+
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			if err := fn(i); err != nil {
+				errChan <- err
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errChan)
+
+	return <-errChan
+}
+
+// enrichRepository runs the included enrichers on a repository
+// It returns nil when an enricher drops the repository. Failures of optional enrichers are reported on the repository.
+func (ru *repositoryUseCase) enrichRepository(repo models.Repository, rsp *models.RepositorySearchParams) (*models.Repository, error) {
+	ec := &EnrichContext{GitHub: ru.gr, Params: rsp, Languages: ru.getLanguages, Activity: ru.getActivity}
+
+	included := includedEnrichers(rsp.Include)
+	for _, enricher := range included {
+		err := enricher.Enrich(ec, &repo)
+		switch {
+		case err == nil:
+		case errors.Is(err, ErrRepositoryDropped):
+			return nil, nil
+		case enricher.Required() || errors.Is(err, ErrBudgetExceeded):
+			log.Print("error fetching ", enricher.Name(), " for ", repo.FullName, ": ", err)
+			return nil, fmt.Errorf("error fetching %s for %s: %w", enricher.Name(), repo.FullName, err)
+		default:
+			repo.EnrichmentErrors = append(repo.EnrichmentErrors, models.EnrichmentError{
+				Enrichment: enricher.Name(),
+				Error:      err.Error(),
+			})
+		}
+	}
+
+	// depends_on is evaluated once the SBOM or the manifests are known, repositories whose dependencies failed are dropped too
+	if _, dependsOn := splitLocalQualifiers(rsp.Query); len(dependsOn) > 0 && (includes(included, models.IncludeSBOM) || includes(included, models.IncludeManifests)) {
+		if !matchesDependencies(declaredDependencies(&repo), dependsOn) {
+			return nil, nil
+		}
+	}
+
+	return &repo, nil
+}
+
+// languagesEnricher fetches the languages of a repository and drops the ones without the requested language
+// Repositories GitHub has no languages for cost a second call, to infer them from their files.
+type languagesEnricher struct{}
+
+func (languagesEnricher) Name() string   { return models.IncludeLanguages }
+func (languagesEnricher) Calls() int     { return 2 }
+func (languagesEnricher) Required() bool { return true }
+
+func (languagesEnricher) Enrich(ec *EnrichContext, repo *models.Repository) error {
+	languages, inferred, err := ec.Languages(repo, ec.Params.Header)
+	if err != nil {
+		return err
+	}
+
+	// Keep the languages requested by the mode and compute the share of each of them
+	filteredLanguages, stats := buildLanguageStats(languages, ec.Params.Language, ec.Params.LanguagesMode)
+
+	// If the repository has the requested language (useless i think it has to but just in case)
+	if len(filteredLanguages) == 0 {
+		return ErrRepositoryDropped
+	}
+
+	stats.Inferred = inferred
+	repo.Languages = filteredLanguages
+	repo.LanguageStats = stats
+	return nil
+}
+
+// topicsEnricher fetches the topics of a repository, the search index may lag behind them
+type topicsEnricher struct{}
+
+func (topicsEnricher) Name() string   { return models.IncludeTopics }
+func (topicsEnricher) Calls() int     { return 1 }
+func (topicsEnricher) Required() bool { return false }
+
+func (topicsEnricher) Enrich(ec *EnrichContext, repo *models.Repository) error {
+	topics, err := ec.GitHub.GetTopics(repo.FullName, ec.Params.Header)
+	if err != nil {
+		return err
+	}
+
+	repo.Topics = topics
+	return nil
+}
+
+// releasesEnricher fetches the latest release of a repository
+type releasesEnricher struct{}
+
+func (releasesEnricher) Name() string   { return models.IncludeReleases }
+func (releasesEnricher) Calls() int     { return 1 }
+func (releasesEnricher) Required() bool { return false }
+
+func (releasesEnricher) Enrich(ec *EnrichContext, repo *models.Repository) error {
+	release, err := ec.GitHub.GetLatestRelease(repo.FullName, ec.Params.Header)
+	if err != nil {
+		return err
+	}
+
+	repo.LatestRelease = release
+	return nil
+}
+
+// issuesEnricher counts the open issues of a repository and the good first issues among them
+// The open count is the one of the repository payload, only good first issues are searched: the issues search
+// has a much lower rate limit than the other endpoints.
+type issuesEnricher struct{}
+
+func (issuesEnricher) Name() string   { return models.IncludeIssues }
+func (issuesEnricher) Calls() int     { return 1 }
+func (issuesEnricher) Required() bool { return false }
+
+func (issuesEnricher) Enrich(ec *EnrichContext, repo *models.Repository) error {
+	query := fmt.Sprintf(`repo:%s is:issue is:open label:"good first issue"`, repo.FullName)
+
+	goodFirstIssues, err := ec.GitHub.CountIssues(query, ec.Params.Header)
+	if err != nil {
+		return err
+	}
+
+	repo.Issues = &models.IssueCounts{Open: repo.OpenIssuesCount, GoodFirstIssues: goodFirstIssues}
+	return nil
+}
+
+// communityEnricher fetches the community profile of a repository
+type communityEnricher struct{}
+
+func (communityEnricher) Name() string   { return models.IncludeCommunity }
+func (communityEnricher) Calls() int     { return 1 }
+func (communityEnricher) Required() bool { return false }
+
+func (communityEnricher) Enrich(ec *EnrichContext, repo *models.Repository) error {
+	profile, err := ec.GitHub.GetCommunityProfile(repo.FullName, ec.Params.Header)
+	if err != nil {
+		return err
+	}
+
+	repo.Community = profile
+	return nil
+}
+
+// activityEnricher fetches the commit activity of a repository
+// GitHub computes statistics on demand, the first request may wait for them or fail with repositories.ErrStatsComputing.
+// Its calls count every poll of both statistics.
+type activityEnricher struct{}
+
+func (activityEnricher) Name() string   { return models.IncludeActivity }
+func (activityEnricher) Calls() int     { return 2 * statsPolls() }
+func (activityEnricher) Required() bool { return false }
+
+func (activityEnricher) Enrich(ec *EnrichContext, repo *models.Repository) error {
+	activity, err := ec.Activity(ec.Params.Context, repo.FullName, ec.Params.Header)
+	if err != nil {
+		return err
+	}
+
+	repo.Activity = activity
+	return nil
+}
+
+// contributorsEnricher counts the contributors of a repository
+type contributorsEnricher struct{}
+
+func (contributorsEnricher) Name() string   { return models.IncludeContributors }
+func (contributorsEnricher) Calls() int     { return 1 }
+func (contributorsEnricher) Required() bool { return false }
+
+func (contributorsEnricher) Enrich(ec *EnrichContext, repo *models.Repository) error {
+	count, err := ec.GitHub.GetContributorCount(repo.FullName, ec.Params.Header)
+	if errors.Is(err, repositories.ErrTooLarge) {
+		repo.Contributors = &models.Contributors{TooLarge: true}
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	repo.Contributors = &models.Contributors{Count: count}
+	return nil
+}
+
+// sbomEnricher fetches the dependencies listed in the SBOM of a repository
+// GitHub builds it from the dependency graph, which may be disabled.
+type sbomEnricher struct{}
+
+func (sbomEnricher) Name() string   { return models.IncludeSBOM }
+func (sbomEnricher) Calls() int     { return 1 }
+func (sbomEnricher) Required() bool { return false }
+
+func (sbomEnricher) Enrich(ec *EnrichContext, repo *models.Repository) error {
+	dependencies, err := ec.GitHub.GetSBOM(repo.FullName, ec.Params.Header)
+	if err != nil {
+		return err
+	}
+
+	repo.Dependencies = dependencies
+	return nil
+}
+
+// manifestsEnricher fetches and parses the dependency manifests at the root of a repository
+type manifestsEnricher struct{}
+
+func (manifestsEnricher) Name() string   { return models.IncludeManifests }
+func (manifestsEnricher) Calls() int     { return len(manifestParsers) }
+func (manifestsEnricher) Required() bool { return false }
+
+func (manifestsEnricher) Enrich(ec *EnrichContext, repo *models.Repository) error {
+	manifests, err := fetchManifests(ec.GitHub, repo.FullName, ec.Params.Header)
+	if err != nil {
+		return err
+	}
+
+	repo.Manifests = manifests
+	return nil
+}
@@ -0,0 +1,191 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestEnrichRepository(t *testing.T) {
+	repo := models.Repository{FullName: "scalingo/cli"}
+	release := &models.Release{TagName: "v1.0.0", PublishedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	tests := map[string]struct {
+		include    []string
+		query      string
+		language   string
+		openIssues int
+		mockCall   func(*mockGitHubRepository)
+		wantErr    assert.ErrorAssertionFunc
+		want       *models.Repository
+	}{
+		"languages by default": {
+			language: "Go",
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetLanguages", "scalingo/cli", "token").Return(models.Languages{"Go": 100}, nil)
+			},
+			wantErr: assert.NoError,
+			want: &models.Repository{
+				FullName:      "scalingo/cli",
+				Languages:     models.Languages{"Go": 100},
+				LanguageStats: &models.LanguageStats{TotalBytes: 100, RequestedBytes: 100, RequestedShare: 100, IsPrimary: true, Rank: 1, Percentages: map[string]float64{"Go": 100}},
+			},
+		},
+		"no languages, inferred from the tree": {
+			language: "Go",
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetLanguages", "scalingo/cli", "token").Return(models.Languages{}, nil)
+				m.On("GetTree", "scalingo/cli", "HEAD", "token").Return(&models.Tree{Entries: []models.TreeEntry{
+					{Path: "main.go", Type: "blob", Size: 100},
+					{Path: "vendor/github.com/pkg/errors/errors.go", Type: "blob", Size: 500},
+				}}, nil)
+			},
+			wantErr: assert.NoError,
+			want: &models.Repository{
+				FullName:      "scalingo/cli",
+				Languages:     models.Languages{"Go": 100},
+				LanguageStats: &models.LanguageStats{TotalBytes: 100, RequestedBytes: 100, RequestedShare: 100, IsPrimary: true, Rank: 1, Percentages: map[string]float64{"Go": 100}, Inferred: true},
+			},
+		},
+		"no languages and tree unavailable, dropped": {
+			language: "Go",
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetLanguages", "scalingo/cli", "token").Return(models.Languages{}, nil)
+				m.On("GetTree", "scalingo/cli", "HEAD", "token").Return((*models.Tree)(nil), errors.New("git repository is empty"))
+			},
+			wantErr: assert.NoError,
+		},
+		"without the requested language, dropped before other enrichments": {
+			include:  []string{models.IncludeReleases, models.IncludeLanguages},
+			language: "Rust",
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetLanguages", "scalingo/cli", "token").Return(models.Languages{"Go": 100}, nil)
+			},
+			wantErr: assert.NoError,
+		},
+		"enrichments without languages": {
+			include:    []string{models.IncludeReleases, models.IncludeIssues, models.IncludeTopics},
+			openIssues: 12,
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetTopics", "scalingo/cli", "token").Return([]string{"paas"}, nil)
+				m.On("GetLatestRelease", "scalingo/cli", "token").Return(release, nil)
+				// The open issues come from the repository, only good first issues are searched
+				m.On("CountIssues", `repo:scalingo/cli is:issue is:open label:"good first issue"`, "token").Return(3, nil)
+			},
+			wantErr: assert.NoError,
+			want: &models.Repository{
+				FullName:        "scalingo/cli",
+				OpenIssuesCount: 12,
+				Topics:          []string{"paas"},
+				LatestRelease:   release,
+				Issues:          &models.IssueCounts{Open: 12, GoodFirstIssues: 3},
+			},
+		},
+		"optional enrichment fails, reported on the repository": {
+			include: []string{models.IncludeCommunity, models.IncludeReleases},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetLatestRelease", "scalingo/cli", "token").Return(release, nil)
+				m.On("GetCommunityProfile", "scalingo/cli", "token").Return((*models.CommunityProfile)(nil), errors.New("api error"))
+			},
+			wantErr: assert.NoError,
+			want: &models.Repository{
+				FullName:         "scalingo/cli",
+				LatestRelease:    release,
+				EnrichmentErrors: []models.EnrichmentError{{Enrichment: models.IncludeCommunity, Error: "api error"}},
+			},
+		},
+		"contributors": {
+			include: []string{models.IncludeContributors},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetContributorCount", "scalingo/cli", "token").Return(42, nil)
+			},
+			wantErr: assert.NoError,
+			want:    &models.Repository{FullName: "scalingo/cli", Contributors: &models.Contributors{Count: 42}},
+		},
+		"contributors too large to list": {
+			include: []string{models.IncludeContributors},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetContributorCount", "scalingo/cli", "token").Return(0, fmt.Errorf("%w (status 403): too large", repositories.ErrTooLarge))
+			},
+			wantErr: assert.NoError,
+			want:    &models.Repository{FullName: "scalingo/cli", Contributors: &models.Contributors{TooLarge: true}},
+		},
+		"depends_on matched": {
+			include: []string{models.IncludeSBOM},
+			query:   "language:go depends_on:github.com/pkg/errors",
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetSBOM", "scalingo/cli", "token").Return([]models.Dependency{{Ecosystem: "golang", Name: "github.com/pkg/errors"}}, nil)
+			},
+			wantErr: assert.NoError,
+			want:    &models.Repository{FullName: "scalingo/cli", Dependencies: []models.Dependency{{Ecosystem: "golang", Name: "github.com/pkg/errors"}}},
+		},
+		"depends_on not matched, dropped": {
+			include: []string{models.IncludeSBOM},
+			query:   "language:go depends_on:github.com/pkg/errors",
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetSBOM", "scalingo/cli", "token").Return([]models.Dependency(nil), errors.New("dependency graph disabled"))
+			},
+			wantErr: assert.NoError,
+		},
+		"depends_on matched by a manifest": {
+			include: []string{models.IncludeManifests},
+			query:   "depends_on:github.com/pkg/errors",
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetFileContents", "scalingo/cli", "go.mod", "token").Return([]byte("go 1.20\nrequire github.com/pkg/errors v0.9.1\n"), nil)
+				m.On("GetFileContents", "scalingo/cli", mock.Anything, "token").Return([]byte(nil), repositories.ErrNotFound)
+			},
+			wantErr: assert.NoError,
+			want: &models.Repository{FullName: "scalingo/cli", Manifests: []models.Manifest{{
+				Path:         "go.mod",
+				Ecosystem:    "golang",
+				GoVersion:    "1.20",
+				Dependencies: []models.Dependency{{Ecosystem: "golang", Name: "github.com/pkg/errors", Version: "v0.9.1"}},
+			}}},
+		},
+		"languages fail, return error": {
+			include:  []string{models.IncludeLanguages, models.IncludeReleases},
+			language: "Go",
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetLanguages", "scalingo/cli", "token").Return(models.Languages(nil), errors.New("api error"))
+			},
+			wantErr: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(mockGitHubRepository)
+			tt.mockCall(mockRepo)
+			ru := NewRepositoryUseCase(mockRepo, testCursorSecret).(*repositoryUseCase)
+
+			rsp := &models.RepositorySearchParams{Query: tt.query, Header: "token", Language: tt.language, LanguagesMode: models.LanguagesModeAll, Include: tt.include}
+			input := repo
+			input.OpenIssuesCount = tt.openIssues
+			got, err := ru.enrichRepository(input, rsp)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestIncludedEnrichers(t *testing.T) {
+	names := func(include []string) []string {
+		var names []string
+		for _, enricher := range includedEnrichers(include) {
+			names = append(names, enricher.Name())
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{models.IncludeLanguages}, names(nil))
+	assert.Equal(t, []string{models.IncludeLanguages, models.IncludeReleases}, names([]string{models.IncludeReleases, models.IncludeLanguages}))
+	assert.False(t, includesLanguages([]string{models.IncludeTopics}))
+	assert.Equal(t, 2, enrichmentCalls([]string{models.IncludeLanguages, models.IncludeIssues, models.IncludeCommunity}))
+}
@@ -0,0 +1,223 @@
+package usecases
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// ExplainQuery describes how a validated search would be run, without calling GitHub
+func (ru *repositoryUseCase) ExplainQuery(rsp *models.RepositorySearchParams) (*models.QueryExplanation, error) {
+	language, err := ru.ValidateQuery(rsp.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &models.QueryExplanation{
+		Query:      rsp.Query,
+		Terms:      []string{},
+		Qualifiers: []models.ExplainedQualifier{},
+	}
+
+	for _, part := range strings.Fields(rsp.Query) {
+		name, value, found := strings.Cut(part, ":")
+		if !found {
+			explanation.Terms = append(explanation.Terms, part)
+			continue
+		}
+
+		operator, normalized := qualifiers[name].normalize(value)
+		explanation.Qualifiers = append(explanation.Qualifiers, models.ExplainedQualifier{
+			Qualifier:  name,
+			Value:      value,
+			Operator:   operator,
+			Normalized: normalized,
+			Local:      name == "language" || qualifiers[name].local,
+		})
+	}
+
+	upstream := upstreamParams(rsp, rsp.Page)
+	explanation.GitHub = models.ExplainedGitHubPart{
+		Query:   upstream.Query,
+		Sort:    upstream.Sort,
+		Order:   upstream.Order,
+		PerPage: upstream.PerPage,
+		Page:    upstream.Page,
+		URL:     ru.gr.SearchURL(upstream),
+	}
+
+	explanation.Local = []models.ExplainedStep{}
+	for _, enricher := range includedEnrichers(rsp.Include) {
+		if enricher.Name() == models.IncludeLanguages {
+			explanation.Local = append(explanation.Local,
+				models.ExplainedStep{Name: "languages", Detail: "the languages of each repository are fetched, one call per repository, plus one to infer them from its files when GitHub has none"},
+				models.ExplainedStep{Name: "language_filter", Detail: fmt.Sprintf("repositories without %s in their languages are dropped", language)},
+				models.ExplainedStep{Name: "language_stats", Detail: fmt.Sprintf("the share of %s is computed, %s languages are returned", language, rsp.LanguagesMode)},
+			)
+			continue
+		}
+
+		explanation.Local = append(explanation.Local, models.ExplainedStep{
+			Name:   enricher.Name(),
+			Detail: includeSteps[enricher.Name()],
+		})
+	}
+
+	if _, dependsOn := splitLocalQualifiers(rsp.Query); len(dependsOn) > 0 {
+		explanation.Local = append(explanation.Local, models.ExplainedStep{
+			Name:   models.QualifierDependsOn,
+			Detail: fmt.Sprintf("repositories whose SBOM and manifests do not list %s are dropped", strings.Join(dependsOn, " and ")),
+		})
+	}
+
+	if models.LocalSorts[rsp.Sort] {
+		explanation.Local = append(explanation.Local, models.ExplainedStep{
+			Name:   "sort",
+			Detail: fmt.Sprintf("repositories of the page are sorted by %s %s", rsp.Sort, rsp.Order),
+		})
+	}
+
+	if rsp.Fill {
+		explanation.Local = append(explanation.Local, models.ExplainedStep{
+			Name:   "fill",
+			Detail: "following GitHub pages are fetched until per_page repositories are kept",
+		})
+	}
+
+	explanation.EstimatedCalls = estimateCalls(rsp)
+
+	return explanation, nil
+}
+
+// includeSteps describe the enrichments other than languages in explanations
+var includeSteps = map[string]string{
+	models.IncludeTopics:       "the topics of each repository are fetched, one call per repository",
+	models.IncludeReleases:     "the latest release of each repository is fetched, one call per repository",
+	models.IncludeIssues:       "the open issues of each repository are read from its open_issues_count, pull requests included, its good first issues are counted with one search call",
+	models.IncludeCommunity:    "the community profile of each repository is fetched, one call per repository",
+	models.IncludeActivity:     "the commit activity of each repository is fetched, two calls per repository, and one more for each poll while github computes it",
+	models.IncludeContributors: "the contributors of each repository are counted, one call per repository",
+	models.IncludeSBOM:         "the SBOM of each repository is fetched from its dependency graph, one call per repository",
+	models.IncludeManifests:    "the go.mod, package.json, Cargo.toml, requirements.txt and pyproject.toml of each repository are fetched and parsed, one call per manifest",
+}
+
+// estimateCalls bounds the GitHub API calls of a search, before any result is known
+// A page costs a search call and, per repository, the languages calls and the calls of the other included enrichments, a filled page may need every page up to the search limit.
+// Facets computed over several pages cost each of these pages.
+func estimateCalls(rsp *models.RepositorySearchParams) models.CallEstimate {
+	perPage, _ := strconv.Atoi(rsp.PerPage)
+	page, _ := strconv.Atoi(rsp.Page)
+	if page < 1 {
+		page = 1
+	}
+
+	estimate := models.CallEstimate{
+		Search: models.CallRange{Min: 1, Max: 1},
+	}
+	// repos is the number of repositories enriched, at most
+	repos := perPage
+
+	if rsp.Fill && perPage > 0 {
+		remaining := models.SearchResultsLimit - (page-1)*perPage
+		if remaining < 0 {
+			remaining = 0
+		}
+		if pages := (remaining + perPage - 1) / perPage; pages > 1 {
+			estimate.Search.Max = pages
+		}
+		repos = remaining
+	}
+
+	// Facets over several pages enrich the following pages too
+	if !rsp.Fill && rsp.FacetPages > 1 && perPage > 0 {
+		pages := rsp.FacetPages
+		remaining := models.SearchResultsLimit - (page-1)*perPage
+		if available := (remaining + perPage - 1) / perPage; available < pages {
+			pages = available
+		}
+		if pages > 1 {
+			estimate.Search.Max = pages
+			repos = pages * perPage
+		}
+	}
+
+	if includesLanguages(rsp.Include) {
+		estimate.Languages.Max = repos * languagesEnricher{}.Calls()
+	}
+	estimate.Enrichments.Max = repos * enrichmentCalls(rsp.Include)
+
+	estimate.Total = models.CallRange{
+		Min: estimate.Search.Min + estimate.Languages.Min + estimate.Enrichments.Min,
+		Max: estimate.Search.Max + estimate.Languages.Max + estimate.Enrichments.Max,
+	}
+
+	return estimate
+}
+
+// splitOperator separates the comparison operator of a value, ranges have the range operator
+func splitOperator(value string) (operator, rest string) {
+	switch {
+	case strings.Contains(value, ".."):
+		return "range", value
+	case strings.HasPrefix(value, ">=") || strings.HasPrefix(value, "<="):
+		return value[:2], value[2:]
+	case strings.HasPrefix(value, ">") || strings.HasPrefix(value, "<"):
+		return value[:1], value[1:]
+	default:
+		return "=", value
+	}
+}
+
+// normalizeNumber rewrites the numbers of a validated value in their canonical form
+func normalizeNumber(value string) (string, string) {
+	operator, rest := splitOperator(value)
+
+	number := func(s string) string {
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return s
+		}
+		return strconv.Itoa(n)
+	}
+
+	if operator == "range" {
+		start, end, _ := strings.Cut(rest, "..")
+		return operator, number(start) + ".." + number(end)
+	}
+
+	if operator == "=" {
+		return operator, number(rest)
+	}
+	return operator, operator + number(rest)
+}
+
+// normalizeEqual lowercases a value, GitHub matches languages and licenses regardless of case
+func normalizeEqual(value string) (string, string) {
+	return "=", strings.ToLower(strings.TrimSpace(value))
+}
+
+// normalizeDate rewrites the dates of a validated value in their canonical form
+func normalizeDate(value string) (string, string) {
+	operator, rest := splitOperator(value)
+
+	date := func(s string) string {
+		d, err := time.Parse(dateLayout, s)
+		if err != nil {
+			return s
+		}
+		return d.Format(dateLayout)
+	}
+
+	if operator == "range" {
+		start, end, _ := strings.Cut(rest, "..")
+		return operator, date(start) + ".." + date(end)
+	}
+
+	if operator == "=" {
+		return operator, date(rest)
+	}
+	return operator, operator + date(rest)
+}
@@ -0,0 +1,118 @@
+package usecases
+
+import (
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExplainQuery(t *testing.T) {
+	rsp := &models.RepositorySearchParams{
+		Query:         "tetris language:Go stars:>=010 created:2023-01-01..2023-12-31",
+		PerPage:       "10",
+		Page:          "2",
+		Language:      "Go",
+		LanguagesMode: models.LanguagesModeRequested,
+		Sort:          models.SortLanguageShare,
+		Order:         models.OrderDesc,
+	}
+
+	mockRepo := new(mockGitHubRepository)
+	mockRepo.On("SearchURL", upstreamParams(rsp, "2")).Return("https://api.github.com/search/repositories?q=tetris")
+
+	explanation, err := NewRepositoryUseCase(mockRepo, testCursorSecret).ExplainQuery(rsp)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"tetris"}, explanation.Terms)
+	assert.Equal(t, []models.ExplainedQualifier{
+		{Qualifier: "language", Value: "Go", Operator: "=", Normalized: "go", Local: true},
+		{Qualifier: "stars", Value: ">=010", Operator: ">=", Normalized: ">=10"},
+		{Qualifier: "created", Value: "2023-01-01..2023-12-31", Operator: "range", Normalized: "2023-01-01..2023-12-31"},
+	}, explanation.Qualifiers)
+	assert.Equal(t, models.ExplainedGitHubPart{
+		Query:   rsp.Query,
+		PerPage: "10",
+		Page:    "2",
+		URL:     "https://api.github.com/search/repositories?q=tetris",
+	}, explanation.GitHub)
+	assert.Len(t, explanation.Local, 4)
+	assert.Equal(t, "sort", explanation.Local[3].Name)
+	assert.Equal(t, models.CallRange{Min: 1, Max: 21}, explanation.EstimatedCalls.Total)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestExplainQueryInvalid(t *testing.T) {
+	_, err := NewRepositoryUseCase(new(mockGitHubRepository), testCursorSecret).ExplainQuery(&models.RepositorySearchParams{Query: "stars:>10"})
+	assert.Error(t, err)
+}
+
+func TestEstimateCalls(t *testing.T) {
+	tests := map[string]struct {
+		rsp  *models.RepositorySearchParams
+		want models.CallEstimate
+	}{
+		"single page": {
+			rsp: &models.RepositorySearchParams{PerPage: "100", Page: "1"},
+			want: models.CallEstimate{
+				Search:    models.CallRange{Min: 1, Max: 1},
+				Languages: models.CallRange{Min: 0, Max: 200},
+				Total:     models.CallRange{Min: 1, Max: 201},
+			},
+		},
+		"filled page": {
+			rsp: &models.RepositorySearchParams{PerPage: "100", Page: "3", Fill: true},
+			want: models.CallEstimate{
+				Search:    models.CallRange{Min: 1, Max: 8},
+				Languages: models.CallRange{Min: 0, Max: 1600},
+				Total:     models.CallRange{Min: 1, Max: 1608},
+			},
+		},
+		"filled last page": {
+			rsp: &models.RepositorySearchParams{PerPage: "30", Page: "34", Fill: true},
+			want: models.CallEstimate{
+				Search:    models.CallRange{Min: 1, Max: 1},
+				Languages: models.CallRange{Min: 0, Max: 20},
+				Total:     models.CallRange{Min: 1, Max: 21},
+			},
+		},
+		"included enrichments": {
+			rsp: &models.RepositorySearchParams{PerPage: "10", Page: "1", Include: []string{models.IncludeIssues, models.IncludeReleases}},
+			want: models.CallEstimate{
+				Search:      models.CallRange{Min: 1, Max: 1},
+				Enrichments: models.CallRange{Min: 0, Max: 20},
+				Total:       models.CallRange{Min: 1, Max: 21},
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, estimateCalls(tt.rsp))
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]struct {
+		normalize      func(string) (string, string)
+		value          string
+		wantOperator   string
+		wantNormalized string
+	}{
+		"number":       {normalize: normalizeNumber, value: "007", wantOperator: "=", wantNormalized: "7"},
+		"number bound": {normalize: normalizeNumber, value: "<5", wantOperator: "<", wantNormalized: "<5"},
+		"number range": {normalize: normalizeNumber, value: "01..10", wantOperator: "range", wantNormalized: "1..10"},
+		"equal":        {normalize: normalizeEqual, value: "JavaScript", wantOperator: "=", wantNormalized: "javascript"},
+		"date":         {normalize: normalizeDate, value: ">2024-01-01", wantOperator: ">", wantNormalized: ">2024-01-01"},
+		"open range":   {normalize: normalizeDate, value: "2024-01-01..*", wantOperator: "range", wantNormalized: "2024-01-01..*"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			operator, normalized := tt.normalize(tt.value)
+			assert.Equal(t, tt.wantOperator, operator)
+			assert.Equal(t, tt.wantNormalized, normalized)
+		})
+	}
+}
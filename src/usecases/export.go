@@ -0,0 +1,218 @@
+package usecases
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// exportPerPage is the biggest page GitHub search accepts, it minimizes the calls of an export
+const exportPerPage = 100
+
+// ExportCheckpointFunc is called each time a window is crawled, with the state needed to resume the export
+// and the repositories the window added to it.
+type ExportCheckpointFunc func(checkpoint *models.ExportCheckpoint, window *models.ExportWindow) error
+
+// ExportRepositories enumerates every repository of a query, beyond the 1000 results GitHub search can return.
+// The query is split into created date windows, any window with more than 1000 results is bisected until
+// it fits in the search window, then every page of every window is fetched and its repositories enriched.
+// A checkpoint from a previous run can be given to resume it, onCheckpoint is optional.
+func (ru *repositoryUseCase) ExportRepositories(rsp *models.RepositorySearchParams, checkpoint *models.ExportCheckpoint, onCheckpoint ExportCheckpointFunc) (*models.ExportResult, error) {
+	base, window, err := splitCreatedQualifier(rsp.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkpoint == nil {
+		checkpoint = &models.ExportCheckpoint{
+			PendingWindows: []string{window.String()},
+			Result: models.ExportResult{
+				Query: normalizeQuery(rsp.Query),
+				Items: []models.Repository{},
+				Coverage: models.ExportCoverage{
+					IncompleteWindows: []string{},
+				},
+			},
+			Seen: []string{},
+		}
+	}
+
+	ex := &exporter{
+		ru:           ru,
+		rsp:          rsp,
+		base:         base,
+		checkpoint:   checkpoint,
+		onCheckpoint: onCheckpoint,
+		seen:         make(map[string]bool, len(checkpoint.Seen)),
+	}
+	for _, fullName := range checkpoint.Seen {
+		ex.seen[fullName] = true
+	}
+
+	if err := ex.crawl(); err != nil {
+		log.Print("error exporting repositories: ", err)
+		return nil, err
+	}
+
+	result := &checkpoint.Result
+	result.Coverage.Collected = len(checkpoint.Seen)
+	result.Coverage.Complete = len(result.Coverage.IncompleteWindows) == 0
+
+	return result, nil
+}
+
+// exporter holds the state of a running export
+type exporter struct {
+	ru           *repositoryUseCase
+	rsp          *models.RepositorySearchParams
+	base         string
+	checkpoint   *models.ExportCheckpoint
+	onCheckpoint ExportCheckpointFunc
+	seen         map[string]bool
+}
+
+// crawl collects every repository created in the pending windows, bisecting them while they have too many results
+func (ex *exporter) crawl() error {
+	for len(ex.checkpoint.PendingWindows) > 0 {
+		w, err := parseDateBounds(ex.checkpoint.PendingWindows[0])
+		if err != nil {
+			return fmt.Errorf("invalid export window: %w", err)
+		}
+
+		first, err := ex.search(w, 1)
+		if err != nil {
+			return err
+		}
+
+		if first.TotalCount > models.SearchResultsLimit && w.days() > 0 {
+			left, right := w.bisect()
+			ex.checkpoint.PendingWindows = append([]string{left.String(), right.String()}, ex.checkpoint.PendingWindows[1:]...)
+			continue
+		}
+
+		items, seen := len(ex.checkpoint.Result.Items), len(ex.checkpoint.Seen)
+		if err := ex.crawlWindow(w, first); err != nil {
+			return err
+		}
+		ex.checkpoint.PendingWindows = ex.checkpoint.PendingWindows[1:]
+
+		if ex.onCheckpoint != nil {
+			window := &models.ExportWindow{
+				Window: w.String(),
+				Items:  ex.checkpoint.Result.Items[items:],
+				Seen:   ex.checkpoint.Seen[seen:],
+			}
+			if err := ex.onCheckpoint(ex.checkpoint, window); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// crawlWindow collects every page of a window small enough for GitHub search
+func (ex *exporter) crawlWindow(w dateWindow, first *models.RepositorySearchResponse) error {
+	coverage := &ex.checkpoint.Result.Coverage
+	coverage.Windows++
+	coverage.TotalCount += first.TotalCount
+
+	// A single day can still have more than 1000 results, only the first ones are reachable
+	incomplete := first.TotalCount > models.SearchResultsLimit || first.IncompleteResults
+	if err := ex.collect(first.Items); err != nil {
+		return err
+	}
+
+	for page := 2; page <= lastPage(exportPerPage, first.TotalCount); page++ {
+		repos, err := ex.search(w, page)
+		if err != nil {
+			return err
+		}
+		incomplete = incomplete || repos.IncompleteResults
+		if err := ex.collect(repos.Items); err != nil {
+			return err
+		}
+	}
+
+	if incomplete {
+		coverage.IncompleteWindows = append(coverage.IncompleteWindows, w.String())
+	}
+
+	return nil
+}
+
+// search fetches a page of the repositories created in the window
+func (ex *exporter) search(w dateWindow, page int) (*models.RepositorySearchResponse, error) {
+	query := strings.TrimSpace(ex.base + " created:" + w.String())
+
+	repos, err := ex.ru.gr.SearchRepositories(&models.RepositorySearchParams{
+		Query:    query,
+		PerPage:  strconv.Itoa(exportPerPage),
+		Page:     strconv.Itoa(page),
+		Header:   ex.rsp.Header,
+		Language: ex.rsp.Language,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error searching repositories created %s: %w", w, err)
+	}
+
+	return repos, nil
+}
+
+// collect enriches and adds repositories not already collected, results can move from a window to another during the export
+func (ex *exporter) collect(items []models.Repository) error {
+	result := &ex.checkpoint.Result
+
+	unseen := make([]models.Repository, 0, len(items))
+	for _, repo := range items {
+		if ex.seen[repo.FullName] {
+			result.Coverage.Duplicates++
+			continue
+		}
+		ex.seen[repo.FullName] = true
+		ex.checkpoint.Seen = append(ex.checkpoint.Seen, repo.FullName)
+		unseen = append(unseen, repo)
+	}
+
+	enriched, err := ex.ru.enrichRepositories(unseen, ex.rsp)
+	if err != nil {
+		return fmt.Errorf("error fetching repository languages: %w", err)
+	}
+
+	repos := compactRepositories(enriched)
+	result.Coverage.WithoutLanguage += len(unseen) - len(repos)
+	result.Items = append(result.Items, repos...)
+
+	return nil
+}
+
+// splitCreatedQualifier removes the created filter from the query and returns the window of days it matches
+// Without created filter, the window goes from the creation of GitHub to today
+func splitCreatedQualifier(q string) (string, dateWindow, error) {
+	window := dateWindow{from: githubCreation, to: today()}
+	found := false
+
+	parts := make([]string, 0)
+	for _, part := range strings.Fields(q) {
+		qualifier, value, ok := strings.Cut(part, ":")
+		if !ok || qualifier != "created" {
+			parts = append(parts, part)
+			continue
+		}
+
+		if found {
+			return "", window, fmt.Errorf("only one created filter can be used to export repositories")
+		}
+		found = true
+
+		var err error
+		if window, err = parseDateBounds(value); err != nil {
+			return "", window, err
+		}
+	}
+
+	return strings.Join(parts, " "), window, nil
+}
@@ -0,0 +1,276 @@
+package usecases
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/repositories"
+)
+
+var (
+	// ErrExportNotFound is returned for an unknown export id
+	ErrExportNotFound = errors.New("export not found")
+	// ErrExportNotDone is returned when the result of an export still running is requested
+	ErrExportNotDone = errors.New("export is not done yet")
+	// ErrExportNotInterrupted is returned when resuming an export that was not interrupted by a restart
+	ErrExportNotInterrupted = errors.New("export is not interrupted")
+)
+
+// maxRunningExports bounds the exports crawling GitHub at the same time, each of them can use the whole rate limit
+const maxRunningExports = 2
+
+// ExportUseCase is the interface for the export jobs use case
+type ExportUseCase interface {
+	CreateExport(rsp *models.RepositorySearchParams) (*models.ExportJob, error)
+	GetExport(id string) (*models.ExportJob, error)
+	GetExportResult(id string) (*models.ExportResult, error)
+	ResumeExports() error
+	ResumeExport(id, header string) (*models.ExportJob, error)
+}
+
+type exportUseCase struct {
+	ru    RepositoryUseCase
+	store repositories.ExportStore
+	// header is the Authorization header resuming the jobs after a restart, their own token is not persisted
+	header string
+
+	mu   sync.Mutex
+	jobs map[string]*models.ExportJob
+	// slots limits the number of exports running at the same time
+	slots chan struct{}
+	// wg tracks the running jobs, tests wait for them
+	wg sync.WaitGroup
+}
+
+// NewExportUseCase creates a new export jobs use case
+// Without a header, the jobs unfinished at startup are interrupted until a client resumes them.
+func NewExportUseCase(ru RepositoryUseCase, store repositories.ExportStore, header string) ExportUseCase {
+	return &exportUseCase{
+		ru:     ru,
+		store:  store,
+		header: header,
+		jobs:   make(map[string]*models.ExportJob),
+		slots:  make(chan struct{}, maxRunningExports),
+	}
+}
+
+// CreateExport registers a job exporting every repository of the query and starts it in background
+func (eu *exportUseCase) CreateExport(rsp *models.RepositorySearchParams) (*models.ExportJob, error) {
+	id, err := newExportID()
+	if err != nil {
+		return nil, err
+	}
+
+	params := *rsp
+	createdAt := time.Now().UTC()
+	job := &models.ExportJob{
+		ID:        id,
+		Status:    models.ExportStatusPending,
+		Query:     normalizeQuery(rsp.Query),
+		CreatedAt: createdAt,
+		UpdatedAt: createdAt,
+		Params:    &params,
+	}
+
+	if err := eu.store.SaveJob(job); err != nil {
+		log.Print("error saving export job: ", err)
+		return nil, err
+	}
+
+	eu.mu.Lock()
+	eu.jobs[id] = job
+	snapshot := snapshotJob(job)
+	eu.mu.Unlock()
+
+	eu.start(job)
+
+	return snapshot, nil
+}
+
+// GetExport returns the current state of a job
+func (eu *exportUseCase) GetExport(id string) (*models.ExportJob, error) {
+	eu.mu.Lock()
+	defer eu.mu.Unlock()
+
+	job, ok := eu.jobs[id]
+	if !ok {
+		return nil, ErrExportNotFound
+	}
+
+	return snapshotJob(job), nil
+}
+
+// snapshotJob copies the job for clients, without the state owned by the running export
+func snapshotJob(job *models.ExportJob) *models.ExportJob {
+	snapshot := *job
+	snapshot.Params = nil
+	snapshot.Checkpoint = nil
+	return &snapshot
+}
+
+// GetExportResult returns the repositories collected by a finished job
+func (eu *exportUseCase) GetExportResult(id string) (*models.ExportResult, error) {
+	job, err := eu.GetExport(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status != models.ExportStatusDone {
+		return nil, ErrExportNotDone
+	}
+
+	result, err := eu.store.LoadResult(id)
+	if errors.Is(err, repositories.ErrExportNotFound) {
+		return nil, ErrExportNotFound
+	}
+	return result, err
+}
+
+// ResumeExports loads the jobs saved by a previous run and restarts the unfinished ones from their checkpoint
+// The token of a job is not saved: without the header of the server, unfinished jobs are interrupted instead.
+func (eu *exportUseCase) ResumeExports() error {
+	jobs, err := eu.store.ListJobs()
+	if err != nil {
+		return fmt.Errorf("error loading export jobs: %w", err)
+	}
+
+	for _, job := range jobs {
+		eu.mu.Lock()
+		eu.jobs[job.ID] = job
+		eu.mu.Unlock()
+
+		if job.Status != models.ExportStatusPending && job.Status != models.ExportStatusRunning {
+			continue
+		}
+
+		if eu.header == "" {
+			log.Print("export ", job.ID, " interrupted, waiting for a token to resume")
+			eu.update(job, func() {
+				job.Status = models.ExportStatusInterrupted
+			})
+			continue
+		}
+
+		log.Print("resuming export ", job.ID)
+		job.Params.Header = eu.header
+		eu.start(job)
+	}
+
+	return nil
+}
+
+// ResumeExport restarts a job interrupted by a restart with the header of the client
+func (eu *exportUseCase) ResumeExport(id, header string) (*models.ExportJob, error) {
+	eu.mu.Lock()
+	job, ok := eu.jobs[id]
+	if !ok {
+		eu.mu.Unlock()
+		return nil, ErrExportNotFound
+	}
+	if job.Status != models.ExportStatusInterrupted {
+		eu.mu.Unlock()
+		return nil, ErrExportNotInterrupted
+	}
+	// Pending right away, a second resume of the same job is refused
+	job.Status = models.ExportStatusPending
+	job.Params.Header = header
+	snapshot := snapshotJob(job)
+	eu.mu.Unlock()
+
+	log.Print("resuming export ", job.ID)
+	eu.start(job)
+
+	return snapshot, nil
+}
+
+// start runs the job in background once a slot is free
+func (eu *exportUseCase) start(job *models.ExportJob) {
+	eu.wg.Add(1)
+
+	go func() {
+		defer eu.wg.Done()
+
+		eu.slots <- struct{}{}
+		defer func() { <-eu.slots }()
+
+		eu.run(job)
+	}()
+}
+
+// run crawls the export, saving each window and the job so a restart can resume it
+func (eu *exportUseCase) run(job *models.ExportJob) {
+	eu.update(job, func() {
+		job.Status = models.ExportStatusRunning
+	})
+
+	result, err := eu.ru.ExportRepositories(job.Params, job.Checkpoint, func(checkpoint *models.ExportCheckpoint, window *models.ExportWindow) error {
+		// Only the window is written, the job keeps where the log ends
+		offset, err := eu.store.AppendWindow(job.ID, checkpoint.Offset, window)
+		if err != nil {
+			log.Print("error saving export window ", job.ID, ": ", err)
+			return err
+		}
+		checkpoint.Offset = offset
+
+		return eu.update(job, func() {
+			job.Checkpoint = checkpoint
+			job.Progress = models.ExportProgress{
+				WindowsDone:    checkpoint.Result.Coverage.Windows,
+				WindowsPending: len(checkpoint.PendingWindows),
+				Collected:      len(checkpoint.Seen),
+				Items:          len(checkpoint.Result.Items),
+			}
+		})
+	})
+	if err == nil {
+		err = eu.store.SaveResult(job.ID, result)
+	}
+
+	if err != nil {
+		log.Print("error running export ", job.ID, ": ", err)
+		eu.update(job, func() {
+			job.Status = models.ExportStatusFailed
+			job.Error = err.Error()
+		})
+		return
+	}
+
+	eu.update(job, func() {
+		job.Status = models.ExportStatusDone
+		job.Progress.WindowsPending = 0
+		job.Progress.Collected = result.Coverage.Collected
+		job.Progress.Items = len(result.Items)
+		// The result is stored apart, the checkpoint is no longer needed
+		job.Checkpoint = nil
+	})
+}
+
+// update changes the job under lock and saves it
+func (eu *exportUseCase) update(job *models.ExportJob, change func()) error {
+	eu.mu.Lock()
+	defer eu.mu.Unlock()
+
+	change()
+	job.UpdatedAt = time.Now().UTC()
+
+	if err := eu.store.SaveJob(job); err != nil {
+		log.Print("error saving export job ", job.ID, ": ", err)
+		return err
+	}
+	return nil
+}
+
+// newExportID generates an id clients cannot guess to reach the exports of others
+func newExportID() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("error generating export id: %w", err)
+	}
+	return hex.EncodeToString(raw), nil
+}
@@ -0,0 +1,235 @@
+package usecases
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// memoryExportStore keeps exports in memory, jobs are copied as a file store would do
+type memoryExportStore struct {
+	mu      sync.Mutex
+	jobs    map[string]models.ExportJob
+	windows map[string][]models.ExportWindow
+	results map[string]*models.ExportResult
+}
+
+func newMemoryExportStore() *memoryExportStore {
+	return &memoryExportStore{
+		jobs:    make(map[string]models.ExportJob),
+		windows: make(map[string][]models.ExportWindow),
+		results: make(map[string]*models.ExportResult),
+	}
+}
+
+func (ms *memoryExportStore) SaveJob(job *models.ExportJob) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.jobs[job.ID] = *job
+	return nil
+}
+
+func (ms *memoryExportStore) ListJobs() ([]*models.ExportJob, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	jobs := make([]*models.ExportJob, 0, len(ms.jobs))
+	for _, job := range ms.jobs {
+		job := job
+		jobs = append(jobs, &job)
+	}
+	return jobs, nil
+}
+
+// AppendWindow uses the number of windows as offset
+func (ms *memoryExportStore) AppendWindow(id string, offset int64, window *models.ExportWindow) (int64, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.windows[id] = append(ms.windows[id][:offset], *window)
+	return int64(len(ms.windows[id])), nil
+}
+
+func (ms *memoryExportStore) SaveResult(id string, result *models.ExportResult) error {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	ms.results[id] = result
+	return nil
+}
+
+func (ms *memoryExportStore) LoadResult(id string) (*models.ExportResult, error) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+	result, ok := ms.results[id]
+	if !ok {
+		return nil, repositories.ErrExportNotFound
+	}
+	return result, nil
+}
+
+func TestCreateExport(t *testing.T) {
+	params := &models.RepositorySearchParams{
+		Query:    "language:go created:2024-01-01",
+		Header:   "Bearer token",
+		Language: "go",
+	}
+
+	tests := map[string]struct {
+		mockCall   func(*mockGitHubRepository)
+		wantStatus string
+		checkStore func(*testing.T, *exportUseCase, string)
+	}{
+		"nominal": {
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", windowParams("language:go created:2024-01-01", "1")).
+					Return(&models.RepositorySearchResponse{TotalCount: 1, Items: []models.Repository{{FullName: "a"}}}, nil)
+				m.On("GetLanguages", "a", "Bearer token").Return(models.Languages{"Go": 10}, nil)
+			},
+			wantStatus: models.ExportStatusDone,
+			checkStore: func(t *testing.T, eu *exportUseCase, id string) {
+				result, err := eu.GetExportResult(id)
+				assert.NoError(t, err)
+				assert.Len(t, result.Items, 1)
+
+				job, _ := eu.GetExport(id)
+				assert.Equal(t, models.ExportProgress{WindowsDone: 1, Collected: 1, Items: 1}, job.Progress)
+
+				// Each window is appended apart from the job
+				assert.Equal(t, []models.ExportWindow{{
+					Window: "2024-01-01",
+					Items:  result.Items,
+					Seen:   []string{"a"},
+				}}, eu.store.(*memoryExportStore).windows[id])
+			},
+		},
+		"crawl error": {
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", mock.Anything).Return(&models.RepositorySearchResponse{}, errors.New("rate limit exceeded"))
+			},
+			wantStatus: models.ExportStatusFailed,
+			checkStore: func(t *testing.T, eu *exportUseCase, id string) {
+				job, _ := eu.GetExport(id)
+				assert.NotEmpty(t, job.Error)
+
+				_, err := eu.GetExportResult(id)
+				assert.ErrorIs(t, err, ErrExportNotDone)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(mockGitHubRepository)
+			tt.mockCall(mockRepo)
+
+			store := newMemoryExportStore()
+			eu := NewExportUseCase(NewRepositoryUseCase(mockRepo, testCursorSecret), store, "").(*exportUseCase)
+
+			job, err := eu.CreateExport(params)
+			assert.NoError(t, err)
+			assert.Len(t, job.ID, 32)
+			assert.Equal(t, models.ExportStatusPending, job.Status)
+			assert.Nil(t, job.Params)
+
+			eu.wg.Wait()
+
+			job, err = eu.GetExport(job.ID)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantStatus, job.Status)
+			assert.Equal(t, tt.wantStatus, store.jobs[job.ID].Status)
+			tt.checkStore(t, eu, job.ID)
+		})
+	}
+}
+
+func TestGetExportUnknown(t *testing.T) {
+	eu := NewExportUseCase(nil, newMemoryExportStore(), "")
+
+	_, err := eu.GetExport("unknown")
+	assert.ErrorIs(t, err, ErrExportNotFound)
+
+	_, err = eu.GetExportResult("unknown")
+	assert.ErrorIs(t, err, ErrExportNotFound)
+}
+
+// interruptedStore returns a store holding a running job saved by a previous run, without its token, and a finished one
+func interruptedStore() *memoryExportStore {
+	store := newMemoryExportStore()
+	store.jobs["interrupted"] = models.ExportJob{
+		ID:     "interrupted",
+		Status: models.ExportStatusRunning,
+		Params: &models.RepositorySearchParams{
+			Query:    "language:go created:2024-01-01..2024-01-02",
+			Language: "go",
+		},
+		Checkpoint: &models.ExportCheckpoint{
+			PendingWindows: []string{"2024-01-02"},
+			Result: models.ExportResult{
+				Items:    []models.Repository{{FullName: "a"}},
+				Coverage: models.ExportCoverage{IncompleteWindows: []string{}},
+			},
+			Seen: []string{"a"},
+		},
+	}
+	store.jobs["finished"] = models.ExportJob{ID: "finished", Status: models.ExportStatusDone}
+	return store
+}
+
+func TestResumeExports(t *testing.T) {
+	mockRepo := new(mockGitHubRepository)
+	mockRepo.On("SearchRepositories", windowParams("language:go created:2024-01-02", "1")).
+		Return(&models.RepositorySearchResponse{TotalCount: 1, Items: []models.Repository{{FullName: "b"}}}, nil)
+	mockRepo.On("GetLanguages", "b", "Bearer token").Return(models.Languages{"Go": 10}, nil)
+
+	eu := NewExportUseCase(NewRepositoryUseCase(mockRepo, testCursorSecret), interruptedStore(), "Bearer token").(*exportUseCase)
+	assert.NoError(t, eu.ResumeExports())
+	eu.wg.Wait()
+
+	job, err := eu.GetExport("interrupted")
+	assert.NoError(t, err)
+	assert.Equal(t, models.ExportStatusDone, job.Status)
+
+	result, err := eu.GetExportResult("interrupted")
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 2)
+
+	job, err = eu.GetExport("finished")
+	assert.NoError(t, err)
+	assert.Equal(t, models.ExportStatusDone, job.Status)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestResumeExportsWithoutToken(t *testing.T) {
+	mockRepo := new(mockGitHubRepository)
+	mockRepo.On("SearchRepositories", windowParams("language:go created:2024-01-02", "1")).
+		Return(&models.RepositorySearchResponse{TotalCount: 1, Items: []models.Repository{{FullName: "b"}}}, nil)
+	mockRepo.On("GetLanguages", "b", "Bearer token").Return(models.Languages{"Go": 10}, nil)
+
+	store := interruptedStore()
+	eu := NewExportUseCase(NewRepositoryUseCase(mockRepo, testCursorSecret), store, "").(*exportUseCase)
+	assert.NoError(t, eu.ResumeExports())
+	eu.wg.Wait()
+
+	job, err := eu.GetExport("interrupted")
+	assert.NoError(t, err)
+	assert.Equal(t, models.ExportStatusInterrupted, job.Status)
+	assert.Equal(t, models.ExportStatusInterrupted, store.jobs["interrupted"].Status)
+
+	_, err = eu.ResumeExport("finished", "Bearer token")
+	assert.ErrorIs(t, err, ErrExportNotInterrupted)
+	_, err = eu.ResumeExport("unknown", "Bearer token")
+	assert.ErrorIs(t, err, ErrExportNotFound)
+
+	job, err = eu.ResumeExport("interrupted", "Bearer token")
+	assert.NoError(t, err)
+	assert.Equal(t, models.ExportStatusPending, job.Status)
+	eu.wg.Wait()
+
+	job, err = eu.GetExport("interrupted")
+	assert.NoError(t, err)
+	assert.Equal(t, models.ExportStatusDone, job.Status)
+	mockRepo.AssertExpectations(t)
+}
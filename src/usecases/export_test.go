@@ -0,0 +1,197 @@
+package usecases
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func windowParams(query, page string) *models.RepositorySearchParams {
+	return &models.RepositorySearchParams{
+		Query:    query,
+		PerPage:  "100",
+		Page:     page,
+		Header:   "Bearer token",
+		Language: "go",
+	}
+}
+
+func TestExportRepositories(t *testing.T) {
+	tests := map[string]struct {
+		query         string
+		mockCall      func(*mockGitHubRepository)
+		wantError     assert.ErrorAssertionFunc
+		checkResponse func(*testing.T, *models.ExportResult)
+	}{
+		"bisect window over search limit": {
+			query: "language:go  created:2024-01-01..2024-01-04",
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", windowParams("language:go created:2024-01-01..2024-01-04", "1")).
+					Return(&models.RepositorySearchResponse{TotalCount: 1500}, nil)
+				m.On("SearchRepositories", windowParams("language:go created:2024-01-01..2024-01-02", "1")).
+					Return(&models.RepositorySearchResponse{
+						TotalCount: 2,
+						Items:      []models.Repository{{FullName: "a"}, {FullName: "b"}},
+					}, nil)
+				m.On("SearchRepositories", windowParams("language:go created:2024-01-03..2024-01-04", "1")).
+					Return(&models.RepositorySearchResponse{
+						TotalCount: 3,
+						Items:      []models.Repository{{FullName: "b"}, {FullName: "c"}, {FullName: "d"}},
+					}, nil)
+				m.On("GetLanguages", "a", "Bearer token").Return(models.Languages{"Go": 10}, nil)
+				m.On("GetLanguages", "b", "Bearer token").Return(models.Languages{"Go": 10}, nil)
+				m.On("GetLanguages", "c", "Bearer token").Return(models.Languages{"Go": 10}, nil)
+				m.On("GetLanguages", "d", "Bearer token").Return(models.Languages{"Python": 10}, nil)
+			},
+			wantError: assert.NoError,
+			checkResponse: func(t *testing.T, result *models.ExportResult) {
+				assert.Equal(t, "language:go created:2024-01-01..2024-01-04", result.Query)
+				assert.Len(t, result.Items, 3)
+				assert.Equal(t, models.ExportCoverage{
+					TotalCount:        5,
+					Collected:         4,
+					Duplicates:        1,
+					WithoutLanguage:   1,
+					Windows:           2,
+					IncompleteWindows: []string{},
+					Complete:          true,
+				}, result.Coverage)
+			},
+		},
+		"fetch every page of a window": {
+			query: "language:go created:2024-01-01",
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", windowParams("language:go created:2024-01-01", "1")).
+					Return(&models.RepositorySearchResponse{TotalCount: 101, Items: []models.Repository{{FullName: "a"}}}, nil)
+				m.On("SearchRepositories", windowParams("language:go created:2024-01-01", "2")).
+					Return(&models.RepositorySearchResponse{TotalCount: 101, Items: []models.Repository{{FullName: "b"}}}, nil)
+				m.On("GetLanguages", mock.Anything, "Bearer token").Return(models.Languages{"Go": 10}, nil)
+			},
+			wantError: assert.NoError,
+			checkResponse: func(t *testing.T, result *models.ExportResult) {
+				assert.Len(t, result.Items, 2)
+				assert.True(t, result.Coverage.Complete)
+			},
+		},
+		"single day over search limit is incomplete": {
+			query: "language:go created:2024-01-01",
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", mock.Anything).
+					Return(&models.RepositorySearchResponse{TotalCount: 1200, Items: []models.Repository{{FullName: "a"}}}, nil)
+				m.On("GetLanguages", "a", "Bearer token").Return(models.Languages{"Go": 10}, nil)
+			},
+			wantError: assert.NoError,
+			checkResponse: func(t *testing.T, result *models.ExportResult) {
+				assert.Equal(t, 1, result.Coverage.Windows)
+				assert.Equal(t, []string{"2024-01-01"}, result.Coverage.IncompleteWindows)
+				assert.False(t, result.Coverage.Complete)
+			},
+		},
+		"search error": {
+			query: "language:go created:2024-01-01",
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", mock.Anything).
+					Return(&models.RepositorySearchResponse{}, errors.New("rate limit exceeded"))
+			},
+			wantError: assert.Error,
+			checkResponse: func(t *testing.T, result *models.ExportResult) {
+				assert.Nil(t, result)
+			},
+		},
+		"several created filters, return error": {
+			query:     "language:go created:2024-01-01 created:2024-01-02",
+			wantError: assert.Error,
+			checkResponse: func(t *testing.T, result *models.ExportResult) {
+				assert.Nil(t, result)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(mockGitHubRepository)
+			if tt.mockCall != nil {
+				tt.mockCall(mockRepo)
+			}
+
+			ru := NewRepositoryUseCase(mockRepo, testCursorSecret)
+			result, err := ru.ExportRepositories(&models.RepositorySearchParams{
+				Query:    tt.query,
+				Header:   "Bearer token",
+				Language: "go",
+			}, nil, nil)
+
+			tt.wantError(t, err)
+			tt.checkResponse(t, result)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestExportRepositoriesCheckpoint(t *testing.T) {
+	mockRepo := new(mockGitHubRepository)
+	mockRepo.On("SearchRepositories", windowParams("language:go created:2024-01-03..2024-01-04", "1")).
+		Return(&models.RepositorySearchResponse{
+			TotalCount: 2,
+			Items:      []models.Repository{{FullName: "b"}, {FullName: "c"}},
+		}, nil)
+	mockRepo.On("GetLanguages", "c", "Bearer token").Return(models.Languages{"Go": 10}, nil)
+
+	// The first window was crawled before a restart
+	checkpoint := &models.ExportCheckpoint{
+		PendingWindows: []string{"2024-01-03..2024-01-04"},
+		Result: models.ExportResult{
+			Query:    "language:go created:2024-01-01..2024-01-04",
+			Items:    []models.Repository{{FullName: "a"}, {FullName: "b"}},
+			Coverage: models.ExportCoverage{TotalCount: 2, Windows: 1, IncompleteWindows: []string{}},
+		},
+		Seen: []string{"a", "b"},
+	}
+
+	checkpoints := 0
+	ru := NewRepositoryUseCase(mockRepo, testCursorSecret)
+	result, err := ru.ExportRepositories(&models.RepositorySearchParams{
+		Query:    "language:go created:2024-01-01..2024-01-04",
+		Header:   "Bearer token",
+		Language: "go",
+	}, checkpoint, func(cp *models.ExportCheckpoint, window *models.ExportWindow) error {
+		checkpoints++
+		assert.Empty(t, cp.PendingWindows)
+		// Only what the window added, b was collected before the restart
+		assert.Equal(t, "2024-01-03..2024-01-04", window.Window)
+		assert.Equal(t, []string{"c"}, window.Seen)
+		assert.Len(t, window.Items, 1)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, checkpoints)
+	assert.Len(t, result.Items, 3)
+	assert.Equal(t, 3, result.Coverage.Collected)
+	assert.Equal(t, 1, result.Coverage.Duplicates)
+	assert.Equal(t, 2, result.Coverage.Windows)
+	assert.True(t, result.Coverage.Complete)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestSplitCreatedQualifier(t *testing.T) {
+	now = func() time.Time { return time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC) }
+	defer func() { now = time.Now }()
+
+	base, window, err := splitCreatedQualifier("tetris created:>=2024-01-01 language:go")
+	assert.NoError(t, err)
+	assert.Equal(t, "tetris language:go", base)
+	assert.Equal(t, dateWindow{from: day("2024-01-01"), to: day("2024-06-15")}, window)
+
+	base, window, err = splitCreatedQualifier("tetris language:go")
+	assert.NoError(t, err)
+	assert.Equal(t, "tetris language:go", base)
+	assert.Equal(t, dateWindow{from: githubCreation, to: day("2024-06-15")}, window)
+
+	_, _, err = splitCreatedQualifier("language:go created:2024/01/01")
+	assert.Error(t, err)
+}
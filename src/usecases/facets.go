@@ -0,0 +1,172 @@
+package usecases
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// maxFacetValues bounds the values returned by the owner, topic and license facets
+const maxFacetValues = 20
+
+// histogramBounds are the lower bounds of the buckets of the stars and size histograms
+var histogramBounds = []int{0, 1, 10, 100, 1000, 10000, 100000}
+
+// searchFacets computes the requested facets over the page, and over the following ones when FacetPages asks for them
+// Following pages are enriched like the page itself, so they cost the same calls.
+func (ru *repositoryUseCase) searchFacets(rsp *models.RepositorySearchParams, resp *models.RepositorySearchResponse) (*models.Facets, error) {
+	repos := resp.Items
+	pages := 1
+
+	perPage, _ := strconv.Atoi(rsp.PerPage)
+	page, _ := strconv.Atoi(rsp.Page)
+	available := resp.TotalCount
+	if available > models.SearchResultsLimit {
+		available = models.SearchResultsLimit
+	}
+
+	for next := page + 1; !rsp.Fill && pages < rsp.FacetPages; next++ {
+		if (next-1)*perPage >= available {
+			break
+		}
+
+		following := *rsp
+		following.Page = strconv.Itoa(next)
+		nextResp, err := ru.searchPage(&following)
+		if err != nil {
+			return nil, err
+		}
+
+		repos = append(repos, nextResp.Items...)
+		pages++
+	}
+
+	return computeFacets(repos, rsp.Facets, pages), nil
+}
+
+// computeFacets aggregates enriched repositories into the named facets
+func computeFacets(repos []models.Repository, names []string, pages int) *models.Facets {
+	facets := &models.Facets{Repositories: len(repos), Pages: pages}
+
+	for _, name := range names {
+		switch name {
+		case models.FacetLicense:
+			facets.License = countValues(repos, func(r models.Repository) []string {
+				if r.License == nil || r.License.Key == "" {
+					return []string{"none"}
+				}
+				return []string{r.License.Key}
+			})
+		case models.FacetOwner:
+			facets.Owner = countValues(repos, func(r models.Repository) []string {
+				return []string{r.Owner.Login}
+			})
+		case models.FacetOwnerType:
+			facets.OwnerType = countValues(repos, func(r models.Repository) []string {
+				return []string{r.Owner.Type}
+			})
+		case models.FacetTopic:
+			facets.Topic = countValues(repos, func(r models.Repository) []string {
+				return r.Topics
+			})
+		case models.FacetStars:
+			facets.Stars = histogram(repos, func(r models.Repository) int { return r.StargazersCount })
+		case models.FacetSize:
+			facets.Size = histogram(repos, func(r models.Repository) int { return r.Size })
+		case models.FacetCreatedYear:
+			facets.CreatedYear = countYears(repos)
+		case models.FacetLanguageBytes:
+			facets.LanguageBytes = sumLanguageBytes(repos)
+		}
+	}
+
+	return facets
+}
+
+// countValues counts the repositories of each value, from the largest count, ties are sorted by value
+// Empty values are ignored and only the maxFacetValues largest counts are kept.
+func countValues(repos []models.Repository, values func(models.Repository) []string) []models.FacetCount {
+	counts := make(map[string]int)
+	for _, repo := range repos {
+		for _, value := range values(repo) {
+			if value != "" {
+				counts[value]++
+			}
+		}
+	}
+
+	result := make([]models.FacetCount, 0, len(counts))
+	for value, count := range counts {
+		result = append(result, models.FacetCount{Value: value, Count: count})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Value < result[j].Value
+	})
+
+	if len(result) > maxFacetValues {
+		result = result[:maxFacetValues]
+	}
+	return result
+}
+
+// countYears counts the repositories created each year, in chronological order
+func countYears(repos []models.Repository) []models.FacetCount {
+	counts := make(map[int]int)
+	for _, repo := range repos {
+		if !repo.CreatedAt.IsZero() {
+			counts[repo.CreatedAt.Year()]++
+		}
+	}
+
+	years := make([]int, 0, len(counts))
+	for year := range counts {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+
+	result := make([]models.FacetCount, 0, len(years))
+	for _, year := range years {
+		result = append(result, models.FacetCount{Value: strconv.Itoa(year), Count: counts[year]})
+	}
+	return result
+}
+
+// histogram counts the repositories in each bucket of histogramBounds, every bucket is returned even when empty
+func histogram(repos []models.Repository, value func(models.Repository) int) []models.HistogramBucket {
+	buckets := make([]models.HistogramBucket, len(histogramBounds))
+	for i, from := range histogramBounds {
+		buckets[i].From = from
+		if i+1 < len(histogramBounds) {
+			to := histogramBounds[i+1] - 1
+			buckets[i].To = &to
+		}
+	}
+
+	for _, repo := range repos {
+		v := value(repo)
+		for i := len(buckets) - 1; i >= 0; i-- {
+			if v >= buckets[i].From {
+				buckets[i].Count++
+				break
+			}
+		}
+	}
+
+	return buckets
+}
+
+// sumLanguageBytes sums the bytes of each language across repositories
+func sumLanguageBytes(repos []models.Repository) map[string]int {
+	total := make(map[string]int)
+	for _, repo := range repos {
+		for language, bytes := range repo.Languages {
+			total[language] += bytes
+		}
+	}
+	return total
+}
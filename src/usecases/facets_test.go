@@ -0,0 +1,160 @@
+package usecases
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComputeFacets(t *testing.T) {
+	repos := []models.Repository{
+		{
+			FullName:        "scalingo/first",
+			Owner:           models.Owner{Login: "scalingo", Type: "Organization"},
+			License:         &models.License{Key: "mit"},
+			Topics:          []string{"paas", "go"},
+			StargazersCount: 0,
+			Size:            150,
+			CreatedAt:       time.Date(2015, 3, 1, 0, 0, 0, 0, time.UTC),
+			Languages:       models.Languages{"Go": 100, "Shell": 10},
+		},
+		{
+			FullName:        "scalingo/second",
+			Owner:           models.Owner{Login: "scalingo", Type: "Organization"},
+			Topics:          []string{"go"},
+			StargazersCount: 42,
+			Size:            150000,
+			CreatedAt:       time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			Languages:       models.Languages{"Go": 50},
+		},
+		{
+			FullName:        "john/third",
+			Owner:           models.Owner{Login: "john", Type: "User"},
+			License:         &models.License{Key: "apache-2.0"},
+			StargazersCount: 12000,
+			CreatedAt:       time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	to := func(v int) *int { return &v }
+
+	tests := map[string]struct {
+		names []string
+		want  *models.Facets
+	}{
+		"counts": {
+			names: []string{models.FacetLicense, models.FacetOwner, models.FacetOwnerType, models.FacetTopic, models.FacetCreatedYear},
+			want: &models.Facets{
+				Repositories: 3,
+				Pages:        1,
+				License:      []models.FacetCount{{Value: "apache-2.0", Count: 1}, {Value: "mit", Count: 1}, {Value: "none", Count: 1}},
+				Owner:        []models.FacetCount{{Value: "scalingo", Count: 2}, {Value: "john", Count: 1}},
+				OwnerType:    []models.FacetCount{{Value: "Organization", Count: 2}, {Value: "User", Count: 1}},
+				Topic:        []models.FacetCount{{Value: "go", Count: 2}, {Value: "paas", Count: 1}},
+				CreatedYear:  []models.FacetCount{{Value: "2015", Count: 2}, {Value: "2020", Count: 1}},
+			},
+		},
+		"histograms and language bytes": {
+			names: []string{models.FacetStars, models.FacetLanguageBytes},
+			want: &models.Facets{
+				Repositories: 3,
+				Pages:        1,
+				Stars: []models.HistogramBucket{
+					{From: 0, To: to(0), Count: 1},
+					{From: 1, To: to(9)},
+					{From: 10, To: to(99), Count: 1},
+					{From: 100, To: to(999)},
+					{From: 1000, To: to(9999)},
+					{From: 10000, To: to(99999), Count: 1},
+					{From: 100000},
+				},
+				LanguageBytes: map[string]int{"Go": 150, "Shell": 10},
+			},
+		},
+		"no facet": {
+			want: &models.Facets{Repositories: 3, Pages: 1},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, computeFacets(repos, tt.names, 1))
+		})
+	}
+}
+
+func TestSearchFacetPages(t *testing.T) {
+	tests := map[string]struct {
+		facetPages    int
+		totalCount    int
+		mockCall      func(*mockGitHubRepository)
+		wantErr       assert.ErrorAssertionFunc
+		wantPages     int
+		wantOwnerType []models.FacetCount
+	}{
+		"single page": {
+			facetPages:    1,
+			totalCount:    10,
+			wantErr:       assert.NoError,
+			wantPages:     1,
+			wantOwnerType: []models.FacetCount{{Value: "User", Count: 1}},
+		},
+		"following page": {
+			facetPages: 3,
+			totalCount: 2,
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", &models.RepositorySearchParams{Query: "language:go", Language: "go", PerPage: "1", Page: "2"}).
+					Return(&models.RepositorySearchResponse{
+						TotalCount: 2,
+						Items:      []models.Repository{{FullName: "scalingo/second", Owner: models.Owner{Type: "Organization"}}},
+					}, nil)
+				m.On("GetLanguages", "scalingo/second", "").Return(models.Languages{"go": 10}, nil)
+			},
+			wantErr:       assert.NoError,
+			wantPages:     2,
+			wantOwnerType: []models.FacetCount{{Value: "Organization", Count: 1}, {Value: "User", Count: 1}},
+		},
+		"following page fails, return error": {
+			facetPages: 2,
+			totalCount: 2,
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", &models.RepositorySearchParams{Query: "language:go", Language: "go", PerPage: "1", Page: "2"}).
+					Return((*models.RepositorySearchResponse)(nil), assert.AnError)
+			},
+			wantErr: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := new(mockGitHubRepository)
+			if tt.mockCall != nil {
+				tt.mockCall(m)
+			}
+			ru := NewRepositoryUseCase(m, []byte("secret")).(*repositoryUseCase)
+
+			rsp := &models.RepositorySearchParams{
+				Query:      "language:go",
+				Language:   "go",
+				PerPage:    "1",
+				Page:       "1",
+				Facets:     []string{models.FacetOwnerType},
+				FacetPages: tt.facetPages,
+			}
+			resp := &models.RepositorySearchResponse{
+				TotalCount: tt.totalCount,
+				Items:      []models.Repository{{FullName: "john/first", Owner: models.Owner{Type: "User"}}},
+			}
+
+			facets, err := ru.searchFacets(rsp, resp)
+			tt.wantErr(t, err)
+			if err == nil {
+				assert.Equal(t, tt.wantPages, facets.Pages)
+				assert.Equal(t, tt.wantOwnerType, facets.OwnerType)
+			}
+			m.AssertExpectations(t)
+		})
+	}
+}
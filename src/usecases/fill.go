@@ -0,0 +1,99 @@
+package usecases
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// fillPage keeps fetching GitHub pages until per_page repositories with the requested language are found
+// Repositories without the language are dropped after enrichment, so a single GitHub page is often not enough.
+// The returned cursor records the GitHub page and the position in it where the next call must resume.
+func (ru *repositoryUseCase) fillPage(rsp *models.RepositorySearchParams) (*models.RepositorySearchResponse, error) {
+	perPage, err := strconv.Atoi(rsp.PerPage)
+	if err != nil || perPage < 1 {
+		return nil, fmt.Errorf("per_page must be a positive number to fill pages")
+	}
+
+	cursor := newPageCursor(rsp)
+	if rsp.Cursor != "" {
+		if cursor, err = ru.cursors.decode(rsp.Cursor); err != nil {
+			return nil, err
+		}
+	} else if cursor.Page < 1 {
+		return nil, fmt.Errorf("page must be a positive number")
+	}
+	cursor.PerPage = perPage
+
+	resp := &models.RepositorySearchResponse{
+		PerPage: perPage,
+		Page:    cursor.Page,
+		Items:   make([]models.Repository, 0, perPage),
+		Cursors: &models.PageLinks{
+			First: ru.cursors.encode(cursor.at(1, 0)),
+		},
+	}
+
+	exhausted := false
+	for len(resp.Items) < perPage {
+		if (cursor.Page-1)*perPage >= models.SearchResultsLimit {
+			break
+		}
+
+		repos, err := ru.gr.SearchRepositories(upstreamParams(rsp, strconv.Itoa(cursor.Page)))
+		if err != nil {
+			log.Print("error searching repositories: ", err)
+			return nil, err
+		}
+		resp.TotalCount = repos.TotalCount
+		resp.IncompleteResults = resp.IncompleteResults || repos.IncompleteResults
+
+		remaining := []models.Repository{}
+		if cursor.Offset < len(repos.Items) {
+			remaining = repos.Items[cursor.Offset:]
+		}
+
+		enriched, err := ru.enrichRepositories(remaining, rsp)
+		if err != nil {
+			log.Print("error fetching repository languages: ", err)
+			return nil, fmt.Errorf("error fetching repository languages: %w", err)
+		}
+
+		consumed := 0
+		for _, repo := range enriched {
+			if len(resp.Items) == perPage {
+				break
+			}
+			consumed++
+			if repo != nil {
+				resp.Items = append(resp.Items, *repo)
+			}
+		}
+
+		cursor.Offset += consumed
+		// The GitHub page is exhausted, the next repository is the first one of the following page
+		if cursor.Offset >= len(repos.Items) {
+			// A short page is the last one GitHub has for this query
+			exhausted = len(repos.Items) < perPage
+			cursor = cursor.at(cursor.Page+1, 0)
+		}
+
+		if exhausted || !cursor.within(resp.TotalCount) {
+			break
+		}
+	}
+
+	if !exhausted && cursor.within(resp.TotalCount) {
+		resp.NextCursor = ru.cursors.encode(cursor)
+		resp.Cursors.Next = resp.NextCursor
+	}
+
+	if models.LocalSorts[rsp.Sort] {
+		sortRepositories(resp.Items, rsp.Sort, rsp.Order)
+	}
+	resp.Count = len(resp.Items)
+
+	return resp, nil
+}
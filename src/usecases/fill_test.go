@@ -0,0 +1,136 @@
+package usecases
+
+import (
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFillPage(t *testing.T) {
+	const language = "go"
+	codec := newCursorCodec(testCursorSecret)
+
+	searchParams := func(page string) *models.RepositorySearchParams {
+		return &models.RepositorySearchParams{
+			Query:    "tetris language:go",
+			Language: language,
+			PerPage:  "2",
+			Page:     page,
+		}
+	}
+
+	tests := map[string]struct {
+		rsp           *models.RepositorySearchParams
+		mockCall      func(*mockGitHubRepository)
+		wantError     assert.ErrorAssertionFunc
+		checkResponse func(*testing.T, *models.RepositorySearchResponse)
+	}{
+		"fill from following page": {
+			rsp: &models.RepositorySearchParams{
+				Query:    "tetris language:go",
+				Language: language,
+				PerPage:  "2",
+				Page:     "1",
+				Fill:     true,
+			},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", searchParams("1")).Return(&models.RepositorySearchResponse{
+					TotalCount: 10,
+					Items:      []models.Repository{{FullName: "a"}, {FullName: "b"}},
+				}, nil)
+				m.On("SearchRepositories", searchParams("2")).Return(&models.RepositorySearchResponse{
+					TotalCount: 10,
+					Items:      []models.Repository{{FullName: "c"}, {FullName: "d"}},
+				}, nil)
+				m.On("GetLanguages", "a", "").Return(models.Languages{"Go": 1}, nil)
+				m.On("GetLanguages", "b", "").Return(models.Languages{"Rust": 1}, nil)
+				m.On("GetLanguages", "c", "").Return(models.Languages{"Go": 1}, nil)
+				m.On("GetLanguages", "d", "").Return(models.Languages{"Go": 1}, nil)
+			},
+			wantError: assert.NoError,
+			checkResponse: func(t *testing.T, resp *models.RepositorySearchResponse) {
+				assert.Equal(t, 2, resp.Count)
+				assert.Equal(t, "a", resp.Items[0].FullName)
+				assert.Equal(t, "c", resp.Items[1].FullName)
+
+				cursor, err := codec.decode(resp.NextCursor)
+				assert.NoError(t, err)
+				assert.Equal(t, pageCursor{Query: "tetris language:go", Fill: true, PerPage: 2, Page: 2, Offset: 1}, cursor)
+				assert.Equal(t, resp.NextCursor, resp.Cursors.Next)
+			},
+		},
+		"resume from cursor until last page": {
+			rsp: &models.RepositorySearchParams{
+				Query:    "tetris language:go",
+				Language: language,
+				PerPage:  "2",
+				Page:     "1",
+				Fill:     true,
+				Cursor:   codec.encode(pageCursor{Query: "tetris language:go", Fill: true, PerPage: 2, Page: 2, Offset: 1}),
+			},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", searchParams("2")).Return(&models.RepositorySearchResponse{
+					TotalCount: 5,
+					Items:      []models.Repository{{FullName: "c"}, {FullName: "d"}},
+				}, nil)
+				m.On("SearchRepositories", searchParams("3")).Return(&models.RepositorySearchResponse{
+					TotalCount: 5,
+					Items:      []models.Repository{{FullName: "e"}},
+				}, nil)
+				m.On("GetLanguages", "d", "").Return(models.Languages{"Go": 1}, nil)
+				m.On("GetLanguages", "e", "").Return(models.Languages{"Go": 1}, nil)
+			},
+			wantError: assert.NoError,
+			checkResponse: func(t *testing.T, resp *models.RepositorySearchResponse) {
+				assert.Equal(t, 2, resp.Count)
+				assert.Equal(t, "d", resp.Items[0].FullName)
+				assert.Equal(t, "e", resp.Items[1].FullName)
+				assert.Empty(t, resp.NextCursor)
+			},
+		},
+		"page outside search window": {
+			rsp: &models.RepositorySearchParams{
+				Query:    "tetris language:go",
+				Language: language,
+				PerPage:  "100",
+				Page:     "11",
+				Fill:     true,
+			},
+			wantError: assert.NoError,
+			checkResponse: func(t *testing.T, resp *models.RepositorySearchResponse) {
+				assert.Equal(t, 0, resp.Count)
+				assert.Empty(t, resp.NextCursor)
+			},
+		},
+		"invalid cursor, return error": {
+			rsp: &models.RepositorySearchParams{
+				Query:    "tetris language:go",
+				Language: language,
+				PerPage:  "2",
+				Fill:     true,
+				Cursor:   "invalid",
+			},
+			wantError: assert.Error,
+			checkResponse: func(t *testing.T, resp *models.RepositorySearchResponse) {
+				assert.Nil(t, resp)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(mockGitHubRepository)
+			if tt.mockCall != nil {
+				tt.mockCall(mockRepo)
+			}
+
+			ru := NewRepositoryUseCase(mockRepo, testCursorSecret)
+			resp, err := ru.SearchRepositories(tt.rsp)
+
+			tt.wantError(t, err)
+			tt.checkResponse(t, resp)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
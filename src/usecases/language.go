@@ -0,0 +1,191 @@
+package usecases
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/repositories"
+)
+
+const (
+	// countsCacheTTL is how long a search count is reused, counts move slowly and need no enrichment
+	countsCacheTTL = time.Hour
+	// countsCacheSize bounds the number of counts kept in the cache
+	countsCacheSize = 10000
+)
+
+// LanguageUseCase is the interface for the language use case, it compares languages with search counts
+type LanguageUseCase interface {
+	LanguageShare(params *models.LanguageShareParams) (*models.LanguageShare, error)
+	LanguageTimeseries(params *models.TimeseriesParams) (*models.LanguageTimeseries, error)
+}
+
+type languageUseCase struct {
+	gr     repositories.GitHubRepository
+	counts *ttlCache[searchCount]
+}
+
+// NewLanguageUseCase creates a new language use case
+func NewLanguageUseCase(gr repositories.GitHubRepository) LanguageUseCase {
+	return &languageUseCase{
+		gr:     gr,
+		counts: newTTLCache[searchCount](countsCacheTTL, countsCacheSize),
+	}
+}
+
+// searchCount is the total count of a search
+type searchCount struct {
+	count      int
+	incomplete bool
+}
+
+// LanguageShare counts the repositories of each language with a search per language, concurrently
+// Only total_count is read, so each search asks for a single repository.
+func (lu *languageUseCase) LanguageShare(params *models.LanguageShareParams) (*models.LanguageShare, error) {
+	languages, err := validateShare(params)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make([]searchCount, len(languages))
+	errChan := make(chan error, len(languages))
+	var wg sync.WaitGroup
+
+	for i, language := range languages {
+		wg.Add(1)
+		i, language := i, language
+
+		go func() {
+			defer wg.Done()
+
+			count, err := lu.count(languageQuery(params.Query, language), params.Header)
+			if err != nil {
+				errChan <- fmt.Errorf("error counting %s repositories: %w", language, err)
+				return
+			}
+			counts[i] = count
+		}()
+	}
+
+	wg.Wait()
+	close(errChan)
+
+	if err := <-errChan; err != nil {
+		return nil, err
+	}
+
+	share := &models.LanguageShare{
+		Query:     params.Query,
+		Languages: make([]models.LanguageCount, 0, len(languages)),
+	}
+	for _, count := range counts {
+		share.Total += count.count
+		share.IncompleteResults = share.IncompleteResults || count.incomplete
+	}
+	for i, language := range languages {
+		share.Languages = append(share.Languages, models.LanguageCount{
+			Language:   language,
+			Count:      counts[i].count,
+			Percentage: percentage(counts[i].count, share.Total),
+		})
+	}
+
+	return share, nil
+}
+
+// count returns the total count of a search, from the cache when possible
+// Incomplete counts are not cached, the next request may get the full one.
+func (lu *languageUseCase) count(query, header string) (searchCount, error) {
+	if count, ok := lu.counts.get(query, header); ok {
+		return count, nil
+	}
+
+	resp, err := lu.gr.SearchRepositories(&models.RepositorySearchParams{
+		Query:   query,
+		PerPage: "1",
+		Page:    "1",
+		Header:  header,
+	})
+	if err != nil {
+		log.Print("error counting repositories: ", err)
+		return searchCount{}, err
+	}
+
+	count := searchCount{count: resp.TotalCount, incomplete: resp.IncompleteResults}
+	if !count.incomplete {
+		lu.counts.set(query, header, count)
+	}
+	return count, nil
+}
+
+// validateShare verifies the languages and the query of a share request
+// Languages are lowercased like the language qualifier, duplicates are dropped.
+func validateShare(params *models.LanguageShareParams) ([]string, error) {
+	if err := validateCountQuery(params.Query); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(params.Languages))
+	languages := make([]string, 0, len(params.Languages))
+	for _, language := range params.Languages {
+		language, err := normalizeLanguage(language)
+		if err != nil {
+			return nil, err
+		}
+		if seen[language] {
+			continue
+		}
+		seen[language] = true
+		languages = append(languages, language)
+
+		if err := verifyQueryLength(languageQuery(params.Query, language)); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(languages) < 1 || len(languages) > models.MaxShareLanguages {
+		return nil, fmt.Errorf("languages must contain between 1 and %d languages", models.MaxShareLanguages)
+	}
+
+	return languages, nil
+}
+
+// validateCountQuery verifies the optional query of a count, the language qualifier is added for each counted language
+func validateCountQuery(query string) error {
+	if query == "" {
+		return nil
+	}
+
+	_, hasLanguageFilter, err := validateQualifiers(query)
+	if err != nil {
+		return err
+	}
+	if hasLanguageFilter {
+		return fmt.Errorf("q cannot contain a language qualifier, the counted languages are given apart")
+	}
+	if _, dependsOn := splitLocalQualifiers(query); len(dependsOn) > 0 {
+		return fmt.Errorf("q cannot contain a %s qualifier, counts come from the search only", models.QualifierDependsOn)
+	}
+	return nil
+}
+
+// normalizeLanguage lowercases a language like the language qualifier and verifies it
+func normalizeLanguage(language string) (string, error) {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if err := validateEqualOperator("language", language); err != nil {
+		return "", err
+	}
+	if strings.ContainsAny(language, " :") {
+		return "", fmt.Errorf("invalid language %q", language)
+	}
+	return language, nil
+}
+
+// languageQuery restricts a query to a language
+func languageQuery(query, language string) string {
+	return strings.TrimSpace(query + " language:" + language)
+}
@@ -0,0 +1,110 @@
+package usecases
+
+import (
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func countParams(query string) *models.RepositorySearchParams {
+	return &models.RepositorySearchParams{Query: query, PerPage: "1", Page: "1", Header: "token"}
+}
+
+func TestLanguageShare(t *testing.T) {
+	const period = "created:2024-01-01..2024-12-31"
+
+	tests := map[string]struct {
+		params   *models.LanguageShareParams
+		mockCall func(*mockGitHubRepository)
+		wantErr  assert.ErrorAssertionFunc
+		want     *models.LanguageShare
+	}{
+		"nominal": {
+			params: &models.LanguageShareParams{Languages: []string{"Go", "rust", "go", "zig"}, Query: period, Header: "token"},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", countParams(period+" language:go")).Return(&models.RepositorySearchResponse{TotalCount: 300}, nil)
+				m.On("SearchRepositories", countParams(period+" language:rust")).Return(&models.RepositorySearchResponse{TotalCount: 100}, nil)
+				m.On("SearchRepositories", countParams(period+" language:zig")).Return(&models.RepositorySearchResponse{TotalCount: 0, IncompleteResults: true}, nil)
+			},
+			wantErr: assert.NoError,
+			want: &models.LanguageShare{
+				Query: period,
+				Total: 400,
+				Languages: []models.LanguageCount{
+					{Language: "go", Count: 300, Percentage: 75},
+					{Language: "rust", Count: 100, Percentage: 25},
+					{Language: "zig", Count: 0, Percentage: 0},
+				},
+				IncompleteResults: true,
+			},
+		},
+		"without query": {
+			params: &models.LanguageShareParams{Languages: []string{"go"}, Header: "token"},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", countParams("language:go")).Return(&models.RepositorySearchResponse{TotalCount: 3}, nil)
+			},
+			wantErr: assert.NoError,
+			want: &models.LanguageShare{
+				Total:     3,
+				Languages: []models.LanguageCount{{Language: "go", Count: 3, Percentage: 100}},
+			},
+		},
+		"search fails, return error": {
+			params: &models.LanguageShareParams{Languages: []string{"go"}, Header: "token"},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", countParams("language:go")).Return((*models.RepositorySearchResponse)(nil), assert.AnError)
+			},
+			wantErr: assert.Error,
+		},
+		"language qualifier in query, return error": {
+			params:  &models.LanguageShareParams{Languages: []string{"go"}, Query: "language:rust"},
+			wantErr: assert.Error,
+		},
+		"local qualifier in query, return error": {
+			params:  &models.LanguageShareParams{Languages: []string{"go"}, Query: "depends_on:github.com/pkg/errors"},
+			wantErr: assert.Error,
+		},
+		"invalid qualifier in query, return error": {
+			params:  &models.LanguageShareParams{Languages: []string{"go"}, Query: "stars:many"},
+			wantErr: assert.Error,
+		},
+		"empty language, return error": {
+			params:  &models.LanguageShareParams{Languages: []string{"go", ""}},
+			wantErr: assert.Error,
+		},
+		"too many languages, return error": {
+			params:  &models.LanguageShareParams{Languages: []string{"a", "b", "c", "d", "e", "f", "g", "h", "i", "j", "k"}},
+			wantErr: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := new(mockGitHubRepository)
+			if tt.mockCall != nil {
+				tt.mockCall(m)
+			}
+
+			share, err := NewLanguageUseCase(m).LanguageShare(tt.params)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, share)
+			m.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLanguageShareCache(t *testing.T) {
+	m := new(mockGitHubRepository)
+	m.On("SearchRepositories", countParams("language:go")).Return(&models.RepositorySearchResponse{TotalCount: 3}, nil).Once()
+	m.On("SearchRepositories", countParams("language:zig")).Return(&models.RepositorySearchResponse{TotalCount: 1, IncompleteResults: true}, nil).Twice()
+
+	lu := NewLanguageUseCase(m)
+	for i := 0; i < 2; i++ {
+		_, err := lu.LanguageShare(&models.LanguageShareParams{Languages: []string{"go", "zig"}, Header: "token"})
+		assert.NoError(t, err)
+	}
+
+	// Complete counts are searched once, incomplete ones every time
+	m.AssertExpectations(t)
+}
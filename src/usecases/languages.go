@@ -0,0 +1,82 @@
+package usecases
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// languageShare is a language with its size in bytes, used to rank languages of a repository
+type languageShare struct {
+	name  string
+	bytes int
+}
+
+// rankLanguages sorts the languages of a repository from the most used to the least used one
+// Languages with the same size are sorted by name so the rank is stable between calls
+func rankLanguages(languages models.Languages) []languageShare {
+	ranked := make([]languageShare, 0, len(languages))
+	for name, bytes := range languages {
+		ranked = append(ranked, languageShare{name: name, bytes: bytes})
+	}
+
+	sort.Slice(ranked, func(i, j int) bool {
+		if ranked[i].bytes != ranked[j].bytes {
+			return ranked[i].bytes > ranked[j].bytes
+		}
+		return ranked[i].name < ranked[j].name
+	})
+
+	return ranked
+}
+
+// percentage returns the share of part in total, rounded to two decimals
+func percentage(part, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(total)*10000) / 100
+}
+
+// buildLanguageStats filters the languages of a repository according to the mode and computes their shares
+// It returns nil languages when the repository does not use the requested language
+func buildLanguageStats(languages models.Languages, requested, mode string) (models.Languages, *models.LanguageStats) {
+	stats := &models.LanguageStats{
+		Percentages: make(map[string]float64),
+	}
+
+	for _, bytes := range languages {
+		stats.TotalBytes += bytes
+	}
+
+	// GitHub names languages with its own case (Go, JavaScript...), the query may not
+	requestedName := ""
+	for rank, ls := range rankLanguages(languages) {
+		if strings.EqualFold(ls.name, requested) {
+			requestedName = ls.name
+			stats.RequestedBytes = ls.bytes
+			stats.Rank = rank + 1
+			break
+		}
+	}
+
+	if requestedName == "" {
+		return nil, nil
+	}
+
+	stats.RequestedShare = percentage(stats.RequestedBytes, stats.TotalBytes)
+	stats.IsPrimary = stats.Rank == 1
+
+	filtered := models.Languages{requestedName: stats.RequestedBytes}
+	if mode == models.LanguagesModeAll {
+		filtered = languages
+	}
+
+	for name, bytes := range filtered {
+		stats.Percentages[name] = percentage(bytes, stats.TotalBytes)
+	}
+
+	return filtered, stats
+}
@@ -0,0 +1,108 @@
+package usecases
+
+import (
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRankLanguages(t *testing.T) {
+	ranked := rankLanguages(models.Languages{"Go": 10, "Shell": 30, "C": 10, "Makefile": 1})
+
+	assert.Equal(t, []languageShare{
+		{name: "Shell", bytes: 30},
+		{name: "C", bytes: 10},
+		{name: "Go", bytes: 10},
+		{name: "Makefile", bytes: 1},
+	}, ranked)
+}
+
+func TestPercentage(t *testing.T) {
+	tests := map[string]struct {
+		part     int
+		total    int
+		expected float64
+	}{
+		"full share": {
+			part:     10,
+			total:    10,
+			expected: 100,
+		},
+		"rounded share": {
+			part:     1,
+			total:    3,
+			expected: 33.33,
+		},
+		"empty total": {
+			part:     0,
+			total:    0,
+			expected: 0,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, percentage(tt.part, tt.total))
+		})
+	}
+}
+
+func TestBuildLanguageStats(t *testing.T) {
+	languages := models.Languages{"Go": 300, "Shell": 600, "Makefile": 100}
+
+	tests := map[string]struct {
+		languages     models.Languages
+		requested     string
+		mode          string
+		wantLanguages models.Languages
+		wantStats     *models.LanguageStats
+	}{
+		"requested language only": {
+			languages:     languages,
+			requested:     "go",
+			mode:          models.LanguagesModeRequested,
+			wantLanguages: models.Languages{"Go": 300},
+			wantStats: &models.LanguageStats{
+				TotalBytes:     1000,
+				RequestedBytes: 300,
+				RequestedShare: 30,
+				IsPrimary:      false,
+				Rank:           2,
+				Percentages:    map[string]float64{"Go": 30},
+			},
+		},
+		"full breakdown": {
+			languages:     languages,
+			requested:     "SHELL",
+			mode:          models.LanguagesModeAll,
+			wantLanguages: languages,
+			wantStats: &models.LanguageStats{
+				TotalBytes:     1000,
+				RequestedBytes: 600,
+				RequestedShare: 60,
+				IsPrimary:      true,
+				Rank:           1,
+				Percentages:    map[string]float64{"Go": 30, "Shell": 60, "Makefile": 10},
+			},
+		},
+		"requested language missing": {
+			languages: languages,
+			requested: "rust",
+			mode:      models.LanguagesModeAll,
+		},
+		"no languages": {
+			languages: models.Languages{},
+			requested: "go",
+			mode:      models.LanguagesModeRequested,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			filtered, stats := buildLanguageStats(tt.languages, tt.requested, tt.mode)
+			assert.Equal(t, tt.wantLanguages, filtered)
+			assert.Equal(t, tt.wantStats, stats)
+		})
+	}
+}
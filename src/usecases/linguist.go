@@ -0,0 +1,214 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/repositories"
+)
+
+// linguistFilenames are the languages of files recognized by their name, named like linguist does
+// https://github.com/github-linguist/linguist/blob/master/lib/linguist/languages.yml
+var linguistFilenames = map[string]string{
+	"Dockerfile":     "Dockerfile",
+	"Containerfile":  "Dockerfile",
+	"Makefile":       "Makefile",
+	"GNUmakefile":    "Makefile",
+	"makefile":       "Makefile",
+	"CMakeLists.txt": "CMake",
+	"Rakefile":       "Ruby",
+	"Gemfile":        "Ruby",
+	"Vagrantfile":    "Ruby",
+	"Jenkinsfile":    "Groovy",
+	"BUILD":          "Starlark",
+	"BUILD.bazel":    "Starlark",
+	"WORKSPACE":      "Starlark",
+	"meson.build":    "Meson",
+	"Justfile":       "Just",
+	"justfile":       "Just",
+	"PKGBUILD":       "Shell",
+}
+
+// linguistExtensions are the languages of files recognized by their extension, lower cased
+// Extensions shared by several languages go to the most common one, linguist reads the content to tell them apart.
+var linguistExtensions = map[string]string{
+	".go":      "Go",
+	".c":       "C",
+	".h":       "C",
+	".cc":      "C++",
+	".cpp":     "C++",
+	".cxx":     "C++",
+	".hh":      "C++",
+	".hpp":     "C++",
+	".hxx":     "C++",
+	".cs":      "C#",
+	".java":    "Java",
+	".kt":      "Kotlin",
+	".kts":     "Kotlin",
+	".scala":   "Scala",
+	".groovy":  "Groovy",
+	".gradle":  "Groovy",
+	".clj":     "Clojure",
+	".cljs":    "Clojure",
+	".js":      "JavaScript",
+	".mjs":     "JavaScript",
+	".cjs":     "JavaScript",
+	".jsx":     "JavaScript",
+	".ts":      "TypeScript",
+	".mts":     "TypeScript",
+	".cts":     "TypeScript",
+	".tsx":     "TypeScript",
+	".vue":     "Vue",
+	".svelte":  "Svelte",
+	".html":    "HTML",
+	".htm":     "HTML",
+	".css":     "CSS",
+	".scss":    "SCSS",
+	".sass":    "Sass",
+	".less":    "Less",
+	".py":      "Python",
+	".pyi":     "Python",
+	".ipynb":   "Jupyter Notebook",
+	".rb":      "Ruby",
+	".erb":     "HTML+ERB",
+	".php":     "PHP",
+	".pl":      "Perl",
+	".pm":      "Perl",
+	".lua":     "Lua",
+	".r":       "R",
+	".jl":      "Julia",
+	".rs":      "Rust",
+	".swift":   "Swift",
+	".m":       "Objective-C",
+	".mm":      "Objective-C++",
+	".dart":    "Dart",
+	".ex":      "Elixir",
+	".exs":     "Elixir",
+	".erl":     "Erlang",
+	".hrl":     "Erlang",
+	".hs":      "Haskell",
+	".ml":      "OCaml",
+	".mli":     "OCaml",
+	".fs":      "F#",
+	".fsx":     "F#",
+	".elm":     "Elm",
+	".zig":     "Zig",
+	".nim":     "Nim",
+	".sol":     "Solidity",
+	".sh":      "Shell",
+	".bash":    "Shell",
+	".zsh":     "Shell",
+	".fish":    "fish",
+	".ps1":     "PowerShell",
+	".psm1":    "PowerShell",
+	".bat":     "Batchfile",
+	".cmd":     "Batchfile",
+	".sql":     "SQL",
+	".tf":      "HCL",
+	".hcl":     "HCL",
+	".nix":     "Nix",
+	".cmake":   "CMake",
+	".mk":      "Makefile",
+	".asm":     "Assembly",
+	".s":       "Assembly",
+	".vim":     "Vim Script",
+	".el":      "Emacs Lisp",
+	".tex":     "TeX",
+	".proto":   "Protocol Buffer",
+	".graphql": "GraphQL",
+	".cu":      "Cuda",
+	".f90":     "Fortran",
+	".pas":     "Pascal",
+	".ada":     "Ada",
+	".cob":     "COBOL",
+	".vb":      "Visual Basic .NET",
+	".coffee":  "CoffeeScript",
+	".hx":      "Haxe",
+	".cr":      "Crystal",
+}
+
+// linguistVendored are the directories linguist leaves out of the languages, like dependencies checked in
+var linguistVendored = []string{"vendor/", "node_modules/", "third_party/", "bower_components/", "Godeps/", ".git/"}
+
+// inferLanguages estimates the languages of a repository from the files of its default branch
+// Each recognized file counts its size, truncated trees give a partial estimate.
+// Vendored files only count when they are all the repository has, like in vendored-only repositories.
+func inferLanguages(gr repositories.GitHubRepository, repo *models.Repository, header string) (models.Languages, error) {
+	ref := repo.DefaultBranch
+	if ref == "" {
+		ref = "HEAD"
+	}
+
+	tree, err := gr.GetTree(repo.FullName, ref, header)
+	if err != nil {
+		return nil, err
+	}
+
+	languages := make(models.Languages)
+	vendored := make(models.Languages)
+	for _, entry := range tree.Entries {
+		if entry.Type != "blob" {
+			continue
+		}
+		language := fileLanguage(entry.Path)
+		if language == "" {
+			continue
+		}
+		if isVendored(entry.Path) {
+			vendored[language] += entry.Size
+		} else {
+			languages[language] += entry.Size
+		}
+	}
+
+	if len(languages) == 0 {
+		return vendored, nil
+	}
+	return languages, nil
+}
+
+// fileLanguage returns the language of a file from its name, then from its extension, empty when it is unknown
+func fileLanguage(filePath string) string {
+	name := path.Base(filePath)
+	if language, ok := linguistFilenames[name]; ok {
+		return language
+	}
+	// Minified files are generated, linguist leaves them out
+	if strings.Contains(name, ".min.") {
+		return ""
+	}
+	return linguistExtensions[strings.ToLower(path.Ext(name))]
+}
+
+// isVendored tells whether a file is in a vendored directory, at any depth
+func isVendored(filePath string) bool {
+	for _, dir := range linguistVendored {
+		if strings.HasPrefix(filePath, dir) || strings.Contains(filePath, "/"+dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// repositoryLanguages returns the languages of a repository, inferred from its files when GitHub has none
+// Repositories whose files cannot be listed keep no languages, only an exhausted budget is an error.
+func repositoryLanguages(gr repositories.GitHubRepository, languages models.Languages, repo *models.Repository, header string) (models.Languages, bool, error) {
+	if len(languages) > 0 {
+		return languages, false, nil
+	}
+
+	inferred, err := inferLanguages(gr, repo, header)
+	if errors.Is(err, ErrBudgetExceeded) {
+		return nil, false, fmt.Errorf("error inferring languages for %s: %w", repo.FullName, err)
+	}
+	if err != nil {
+		log.Print("error inferring languages for ", repo.FullName, ": ", err)
+		return languages, false, nil
+	}
+
+	return inferred, len(inferred) > 0, nil
+}
@@ -0,0 +1,136 @@
+package usecases
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileLanguage(t *testing.T) {
+	tests := map[string]string{
+		"main.go":                   "Go",
+		"src/App.TSX":               "TypeScript",
+		"docker/Dockerfile":         "Dockerfile",
+		"CMakeLists.txt":            "CMake",
+		"static/jquery.min.js":      "",
+		"README.md":                 "",
+		"LICENSE":                   "",
+		"scripts/deploy.production": "",
+	}
+
+	for filePath, want := range tests {
+		t.Run(filePath, func(t *testing.T) {
+			assert.Equal(t, want, fileLanguage(filePath))
+		})
+	}
+}
+
+func TestIsVendored(t *testing.T) {
+	assert.True(t, isVendored("vendor/github.com/pkg/errors/errors.go"))
+	assert.True(t, isVendored("web/node_modules/react/index.js"))
+	assert.False(t, isVendored("src/vendors.go"))
+	assert.False(t, isVendored("main.go"))
+}
+
+func TestRepositoryLanguages(t *testing.T) {
+	repo := &models.Repository{FullName: "scalingo/cli", DefaultBranch: "release/v1"}
+	tree := &models.Tree{Entries: []models.TreeEntry{
+		{Path: "main.go", Type: "blob", Size: 300},
+		{Path: "web", Type: "tree"},
+		{Path: "web/app.js", Type: "blob", Size: 100},
+		{Path: "web/app.min.js", Type: "blob", Size: 900},
+		{Path: "Makefile", Type: "blob", Size: 50},
+		{Path: "docs/index.md", Type: "blob", Size: 1000},
+		{Path: "lib", Type: "commit"},
+	}}
+
+	tests := map[string]struct {
+		languages    models.Languages
+		mockCall     func(*mockGitHubRepository)
+		wantErr      assert.ErrorAssertionFunc
+		want         models.Languages
+		wantInferred bool
+	}{
+		"languages from github kept": {
+			languages: models.Languages{"Go": 10},
+			mockCall:  func(m *mockGitHubRepository) {},
+			wantErr:   assert.NoError,
+			want:      models.Languages{"Go": 10},
+		},
+		"no languages, inferred": {
+			languages: models.Languages{},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetTree", "scalingo/cli", "release/v1", "token").Return(tree, nil)
+			},
+			wantErr:      assert.NoError,
+			want:         models.Languages{"Go": 300, "JavaScript": 100, "Makefile": 50},
+			wantInferred: true,
+		},
+		"vendored files only, inferred": {
+			languages: models.Languages{},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetTree", "scalingo/cli", "release/v1", "token").Return(&models.Tree{Entries: []models.TreeEntry{
+					{Path: "README.md", Type: "blob", Size: 10},
+					{Path: "vendor/github.com/pkg/errors/errors.go", Type: "blob", Size: 200},
+				}}, nil)
+			},
+			wantErr:      assert.NoError,
+			want:         models.Languages{"Go": 200},
+			wantInferred: true,
+		},
+		"no recognized file, not inferred": {
+			languages: models.Languages{},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetTree", "scalingo/cli", "release/v1", "token").Return(&models.Tree{Entries: []models.TreeEntry{{Path: "README.md", Type: "blob", Size: 10}}}, nil)
+			},
+			wantErr: assert.NoError,
+			want:    models.Languages{},
+		},
+		"tree error, no languages": {
+			languages: models.Languages{},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetTree", "scalingo/cli", "release/v1", "token").Return((*models.Tree)(nil), fmt.Errorf("api error"))
+			},
+			wantErr: assert.NoError,
+			want:    models.Languages{},
+		},
+		"budget exceeded, return error": {
+			languages: models.Languages{},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetTree", "scalingo/cli", "release/v1", "token").Return((*models.Tree)(nil), ErrBudgetExceeded)
+			},
+			wantErr: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(mockGitHubRepository)
+			tt.mockCall(mockRepo)
+
+			got, inferred, err := repositoryLanguages(mockRepo, tt.languages, repo, "token")
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantInferred, inferred)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetLanguagesCachesInferred(t *testing.T) {
+	repo := &models.Repository{FullName: "scalingo/cli", DefaultBranch: "master"}
+	mockRepo := new(mockGitHubRepository)
+	mockRepo.On("GetLanguages", "scalingo/cli", "token").Return(models.Languages{}, nil).Once()
+	mockRepo.On("GetTree", "scalingo/cli", "master", "token").Return(&models.Tree{Entries: []models.TreeEntry{{Path: "main.go", Type: "blob", Size: 10}}}, nil).Once()
+
+	ru := NewRepositoryUseCase(mockRepo, testCursorSecret).(*repositoryUseCase)
+	for i := 0; i < 2; i++ {
+		languages, inferred, err := ru.getLanguages(repo, "token")
+		assert.NoError(t, err)
+		assert.Equal(t, models.Languages{"Go": 10}, languages)
+		assert.True(t, inferred, "the inferred languages come from the cache the second time")
+	}
+	mockRepo.AssertExpectations(t)
+}
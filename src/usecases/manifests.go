@@ -0,0 +1,386 @@
+package usecases
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/repositories"
+)
+
+// manifestParser reads the dependencies declared in a manifest
+type manifestParser struct {
+	path      string
+	ecosystem string
+	parse     func(content []byte, manifest *models.Manifest) error
+}
+
+// manifestParsers are the manifests looked for at the root of repositories, ecosystems are named like purl types
+var manifestParsers = []manifestParser{
+	{path: "go.mod", ecosystem: "golang", parse: parseGoMod},
+	{path: "package.json", ecosystem: "npm", parse: parsePackageJSON},
+	{path: "Cargo.toml", ecosystem: "cargo", parse: parseCargoToml},
+	{path: "requirements.txt", ecosystem: "pypi", parse: parseRequirements},
+	{path: "pyproject.toml", ecosystem: "pypi", parse: parsePyproject},
+}
+
+// fetchManifests fetches and parses the manifests of a repository, missing ones are skipped
+func fetchManifests(gr repositories.GitHubRepository, repoFullName, header string) ([]models.Manifest, error) {
+	manifests := make([]models.Manifest, 0)
+	for _, parser := range manifestParsers {
+		content, err := gr.GetFileContents(repoFullName, parser.path, header)
+		if errors.Is(err, repositories.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		manifest := models.Manifest{Path: parser.path, Ecosystem: parser.ecosystem, Dependencies: []models.Dependency{}}
+		if err := parser.parse(content, &manifest); err != nil {
+			return nil, fmt.Errorf("error parsing %s: %w", parser.path, err)
+		}
+		for i := range manifest.Dependencies {
+			manifest.Dependencies[i].Ecosystem = parser.ecosystem
+		}
+		manifests = append(manifests, manifest)
+	}
+
+	return manifests, nil
+}
+
+// parseGoMod reads the go directive and the required modules of a go.mod, indirect ones are scoped
+// https://go.dev/ref/mod#go-mod-file
+func parseGoMod(content []byte, manifest *models.Manifest) error {
+	inRequire := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line, comment, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+
+		switch {
+		case len(fields) == 0:
+			continue
+		case inRequire && fields[0] == ")":
+			inRequire = false
+			continue
+		case inRequire:
+		case fields[0] == "go" && len(fields) == 2:
+			manifest.GoVersion = fields[1]
+			continue
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inRequire = true
+			continue
+		case fields[0] == "require":
+			fields = fields[1:]
+		default:
+			continue
+		}
+
+		if len(fields) < 2 {
+			return fmt.Errorf("invalid require %q", strings.TrimSpace(scanner.Text()))
+		}
+
+		dependency := models.Dependency{Name: fields[0], Version: fields[1]}
+		if strings.TrimSpace(comment) == "indirect" {
+			dependency.Scope = "indirect"
+		}
+		manifest.Dependencies = append(manifest.Dependencies, dependency)
+	}
+
+	return scanner.Err()
+}
+
+// parsePackageJSON reads the dependencies of a package.json, each section but dependencies gives its scope
+// https://docs.npmjs.com/cli/v10/configuring-npm/package-json#dependencies
+func parsePackageJSON(content []byte, manifest *models.Manifest) error {
+	var pkg struct {
+		Dependencies         map[string]string `json:"dependencies"`
+		DevDependencies      map[string]string `json:"devDependencies"`
+		PeerDependencies     map[string]string `json:"peerDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+	}
+	if err := json.Unmarshal(content, &pkg); err != nil {
+		return err
+	}
+
+	add := func(dependencies map[string]string, scope string) {
+		for _, name := range sortedKeys(dependencies) {
+			manifest.Dependencies = append(manifest.Dependencies, models.Dependency{Name: name, Version: dependencies[name], Scope: scope})
+		}
+	}
+	add(pkg.Dependencies, "")
+	add(pkg.DevDependencies, "dev")
+	add(pkg.PeerDependencies, "peer")
+	add(pkg.OptionalDependencies, "optional")
+
+	return nil
+}
+
+// parseCargoToml reads the dependencies tables of a Cargo.toml, target specific ones included
+// Only the TOML Cargo manifests use is understood: tables, and name = "version" or inline tables.
+// https://doc.rust-lang.org/cargo/reference/specifying-dependencies.html
+func parseCargoToml(content []byte, manifest *models.Manifest) error {
+	scopes := map[string]string{"dependencies": "", "dev-dependencies": "dev", "build-dependencies": "build"}
+
+	// scope is the scope of the current dependencies table, named is the dependency of a [dependencies.name] table
+	var scope, named string
+	inDependencies := false
+
+	return scanToml(content, func(table, key, value string) {
+		if key == "" {
+			inDependencies, named = false, ""
+			parts := strings.Split(table, ".")
+			// [target.'cfg(unix)'.dependencies] has the same meaning as [dependencies]
+			if len(parts) > 2 && parts[0] == "target" {
+				parts = parts[2:]
+			}
+			if s, ok := scopes[parts[0]]; ok && len(parts) <= 2 {
+				inDependencies, scope = true, s
+				if len(parts) == 2 {
+					named = parts[1]
+					manifest.Dependencies = append(manifest.Dependencies, models.Dependency{Name: named, Scope: scope})
+				}
+			}
+			return
+		}
+
+		if !inDependencies {
+			return
+		}
+
+		if named != "" {
+			if key == "version" {
+				manifest.Dependencies[len(manifest.Dependencies)-1].Version = tomlString(value)
+			}
+			return
+		}
+
+		dependency := models.Dependency{Name: key, Scope: scope}
+		if strings.HasPrefix(value, "{") {
+			dependency.Version = tomlString(inlineTableValue(value, "version"))
+		} else {
+			dependency.Version = tomlString(value)
+		}
+		manifest.Dependencies = append(manifest.Dependencies, dependency)
+	})
+}
+
+// parseRequirements reads a pip requirements file, options and includes of other files are skipped
+// https://pip.pypa.io/en/stable/reference/requirements-file-format/
+func parseRequirements(content []byte, manifest *models.Manifest) error {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "-") {
+			continue
+		}
+
+		// Requirements given as a bare URL or path have no name to read
+		dependency := parsePEP508(line)
+		if strings.ContainsAny(dependency.Name, ":/") {
+			continue
+		}
+		manifest.Dependencies = append(manifest.Dependencies, dependency)
+	}
+
+	return scanner.Err()
+}
+
+// parsePyproject reads the PEP 621 dependencies of a pyproject.toml, and the Poetry ones
+// https://packaging.python.org/en/latest/specifications/pyproject-toml/#dependencies-optional-dependencies
+func parsePyproject(content []byte, manifest *models.Manifest) error {
+	return scanToml(content, func(table, key, value string) {
+		switch {
+		case key == "":
+		case table == "project" && key == "dependencies":
+			for _, requirement := range tomlArray(value) {
+				manifest.Dependencies = append(manifest.Dependencies, parsePEP508(requirement))
+			}
+		case table == "project.optional-dependencies":
+			for _, requirement := range tomlArray(value) {
+				dependency := parsePEP508(requirement)
+				dependency.Scope = "optional"
+				manifest.Dependencies = append(manifest.Dependencies, dependency)
+			}
+		case table == "tool.poetry.dependencies" && key != "python":
+			manifest.Dependencies = append(manifest.Dependencies, poetryDependency(key, value, ""))
+		case table == "tool.poetry.dev-dependencies" || strings.HasPrefix(table, "tool.poetry.group.") && strings.HasSuffix(table, ".dependencies"):
+			manifest.Dependencies = append(manifest.Dependencies, poetryDependency(key, value, "dev"))
+		}
+	})
+}
+
+// poetryDependency reads a Poetry dependency, given as a version or as an inline table
+func poetryDependency(name, value, scope string) models.Dependency {
+	if strings.HasPrefix(value, "{") {
+		value = inlineTableValue(value, "version")
+	}
+	return models.Dependency{Name: name, Version: tomlString(value), Scope: scope}
+}
+
+// parsePEP508 splits a Python requirement into its name and version specifier, extras and markers are dropped
+// requests[socks]>=2.31 ; python_version>"3.8" gives requests and >=2.31, an exact ==1.0 gives 1.0
+func parsePEP508(requirement string) models.Dependency {
+	requirement, _, _ = strings.Cut(requirement, ";")
+	requirement = strings.TrimSpace(requirement)
+
+	end := strings.IndexAny(requirement, "[=<>!~@ (")
+	if end < 0 {
+		return models.Dependency{Name: requirement}
+	}
+
+	name := requirement[:end]
+	specifier := requirement[end:]
+	if strings.HasPrefix(specifier, "[") {
+		if closing := strings.Index(specifier, "]"); closing >= 0 {
+			specifier = specifier[closing+1:]
+		}
+	}
+	// A direct reference, name @ url, has no version
+	if strings.HasPrefix(strings.TrimSpace(specifier), "@") {
+		return models.Dependency{Name: name}
+	}
+	specifier = strings.Trim(strings.TrimSpace(specifier), "()")
+	specifier = strings.ReplaceAll(specifier, " ", "")
+	if strings.HasPrefix(specifier, "==") && !strings.Contains(specifier, ",") {
+		specifier = strings.TrimPrefix(specifier, "==")
+	}
+
+	return models.Dependency{Name: name, Version: specifier}
+}
+
+// scanToml calls fn with each table header, key empty, then with each key of the table
+// Values spanning several lines, like arrays, are joined. Only what manifests use is understood.
+func scanToml(content []byte, fn func(table, key, value string)) error {
+	table := ""
+	pending := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(stripTomlComment(scanner.Text()))
+		if pending != "" {
+			line = pending + " " + line
+			pending = ""
+		}
+		if line == "" {
+			continue
+		}
+
+		// Keys never start with a bracket, [[name]] arrays of tables are read as tables
+		if strings.HasPrefix(line, "[") {
+			table = strings.Trim(line, "[] ")
+			fn(table, "", "")
+			continue
+		}
+
+		key, value, found := strings.Cut(line, "=")
+		if !found {
+			return fmt.Errorf("invalid line %q", line)
+		}
+		value = strings.TrimSpace(value)
+
+		// Arrays, inline tables and multi-line strings may span several lines
+		if strings.Count(value, "[") > strings.Count(value, "]") || strings.Count(value, "{") > strings.Count(value, "}") ||
+			strings.Count(value, `"""`)%2 == 1 || strings.Count(value, `'''`)%2 == 1 {
+			pending = line
+			continue
+		}
+
+		fn(table, strings.Trim(strings.TrimSpace(key), `"'`), value)
+	}
+	if pending != "" {
+		return fmt.Errorf("unterminated value %q", pending)
+	}
+
+	return scanner.Err()
+}
+
+// stripTomlComment removes the comment of a line, # inside strings are kept
+func stripTomlComment(line string) string {
+	var quote rune
+	for i, c := range line {
+		switch {
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == '#':
+			return line[:i]
+		}
+	}
+	return line
+}
+
+// tomlString unquotes a TOML string value
+func tomlString(value string) string {
+	return strings.Trim(strings.TrimSpace(value), `"'`)
+}
+
+// tomlArray returns the strings of a TOML array
+func tomlArray(value string) []string {
+	value = strings.TrimSpace(value)
+	value = strings.TrimSuffix(strings.TrimPrefix(value, "["), "]")
+
+	items := make([]string, 0)
+	for _, item := range splitTomlList(value) {
+		if item = tomlString(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// inlineTableValue returns the raw value of a key of a TOML inline table, empty when it is missing
+func inlineTableValue(table, key string) string {
+	table = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(table), "{"), "}")
+	for _, pair := range splitTomlList(table) {
+		k, v, found := strings.Cut(pair, "=")
+		if found && strings.TrimSpace(k) == key {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// splitTomlList splits the items of an array or inline table, commas inside strings and nested values are kept
+func splitTomlList(list string) []string {
+	items := make([]string, 0)
+	var quote rune
+	depth, start := 0, 0
+	for i, c := range list {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			items = append(items, list[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, list[start:])
+}
+
+// sortedKeys returns the keys of a map in order, so manifests are always read the same way
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
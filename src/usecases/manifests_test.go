@@ -0,0 +1,232 @@
+package usecases
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFetchManifests(t *testing.T) {
+	tests := map[string]struct {
+		mockCall func(*mockGitHubRepository)
+		wantErr  assert.ErrorAssertionFunc
+		want     []models.Manifest
+	}{
+		"missing manifests skipped": {
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetFileContents", "scalingo/cli", "go.mod", "token").Return([]byte("module github.com/Scalingo/cli\n\ngo 1.20\n\nrequire github.com/pkg/errors v0.9.1\n"), nil)
+				m.On("GetFileContents", "scalingo/cli", mock.Anything, "token").Return([]byte(nil), repositories.ErrNotFound)
+			},
+			wantErr: assert.NoError,
+			want: []models.Manifest{{
+				Path:         "go.mod",
+				Ecosystem:    "golang",
+				GoVersion:    "1.20",
+				Dependencies: []models.Dependency{{Ecosystem: "golang", Name: "github.com/pkg/errors", Version: "v0.9.1"}},
+			}},
+		},
+		"no manifest": {
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetFileContents", "scalingo/cli", mock.Anything, "token").Return([]byte(nil), repositories.ErrNotFound)
+			},
+			wantErr: assert.NoError,
+			want:    []models.Manifest{},
+		},
+		"invalid manifest, return error": {
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetFileContents", "scalingo/cli", "go.mod", "token").Return([]byte(nil), repositories.ErrNotFound)
+				m.On("GetFileContents", "scalingo/cli", "package.json", "token").Return([]byte("{"), nil)
+			},
+			wantErr: assert.Error,
+		},
+		"api error, return error": {
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("GetFileContents", "scalingo/cli", "go.mod", "token").Return([]byte(nil), errors.New("api error"))
+			},
+			wantErr: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(mockGitHubRepository)
+			tt.mockCall(mockRepo)
+
+			got, err := fetchManifests(mockRepo, "scalingo/cli", "token")
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseManifests(t *testing.T) {
+	tests := map[string]struct {
+		parse     func(content []byte, manifest *models.Manifest) error
+		content   string
+		wantErr   assert.ErrorAssertionFunc
+		want      []models.Dependency
+		wantGoVer string
+	}{
+		"go.mod": {
+			parse: parseGoMod,
+			content: `module github.com/Scalingo/cli
+
+go 1.21 // toolchain below
+
+require github.com/urfave/cli/v2 v2.25.7
+
+require (
+	github.com/pkg/errors v0.9.1
+	golang.org/x/sync v0.3.0 // indirect
+)
+
+replace github.com/pkg/errors => ../errors
+`,
+			wantErr:   assert.NoError,
+			wantGoVer: "1.21",
+			want: []models.Dependency{
+				{Name: "github.com/urfave/cli/v2", Version: "v2.25.7"},
+				{Name: "github.com/pkg/errors", Version: "v0.9.1"},
+				{Name: "golang.org/x/sync", Version: "v0.3.0", Scope: "indirect"},
+			},
+		},
+		"go.mod invalid require, return error": {
+			parse:   parseGoMod,
+			content: "require (\n\tgithub.com/pkg/errors\n)\n",
+			wantErr: assert.Error,
+		},
+		"package.json": {
+			parse: parsePackageJSON,
+			content: `{
+  "name": "dashboard",
+  "dependencies": {"react": "^18.2.0", "axios": "1.6.0"},
+  "devDependencies": {"jest": "^29.0.0"},
+  "peerDependencies": {"react-dom": ">=18"},
+  "optionalDependencies": {"fsevents": "*"}
+}`,
+			wantErr: assert.NoError,
+			want: []models.Dependency{
+				{Name: "axios", Version: "1.6.0"},
+				{Name: "react", Version: "^18.2.0"},
+				{Name: "jest", Version: "^29.0.0", Scope: "dev"},
+				{Name: "react-dom", Version: ">=18", Scope: "peer"},
+				{Name: "fsevents", Version: "*", Scope: "optional"},
+			},
+		},
+		"package.json invalid, return error": {
+			parse:   parsePackageJSON,
+			content: `{"dependencies": ["react"]}`,
+			wantErr: assert.Error,
+		},
+		"Cargo.toml": {
+			parse: parseCargoToml,
+			content: `[package]
+name = "cli" # the binary
+version = "0.1.0"
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"] }
+anyhow = "1"
+local = { path = "../local" }
+
+[dependencies.tokio]
+version = "1.32"
+features = [
+  "full",
+]
+
+[dev-dependencies]
+criterion = "0.5"
+
+[target.'cfg(unix)'.build-dependencies]
+cc = "1.0"
+`,
+			wantErr: assert.NoError,
+			want: []models.Dependency{
+				{Name: "serde", Version: "1.0"},
+				{Name: "anyhow", Version: "1"},
+				{Name: "local"},
+				{Name: "tokio", Version: "1.32"},
+				{Name: "criterion", Version: "0.5", Scope: "dev"},
+				{Name: "cc", Version: "1.0", Scope: "build"},
+			},
+		},
+		"Cargo.toml unterminated array, return error": {
+			parse:   parseCargoToml,
+			content: "[dependencies]\nserde = { version = \"1.0\", features = [\"derive\"\n",
+			wantErr: assert.Error,
+		},
+		"requirements.txt": {
+			parse: parseRequirements,
+			content: `# web
+-r base.txt
+--index-url https://pypi.org/simple
+Django==4.2.7
+requests[socks]>=2.31,<3 ; python_version > "3.8"
+numpy
+mylib @ git+https://github.com/example/mylib.git
+https://example.com/archive.zip#egg=archive
+`,
+			wantErr: assert.NoError,
+			want: []models.Dependency{
+				{Name: "Django", Version: "4.2.7"},
+				{Name: "requests", Version: ">=2.31,<3"},
+				{Name: "numpy"},
+				{Name: "mylib"},
+			},
+		},
+		"pyproject.toml": {
+			parse: parsePyproject,
+			content: `[project]
+name = "service"
+description = """
+A service = with an equal sign
+"""
+dependencies = [
+  "fastapi>=0.100",
+  "pydantic (>=2, <3)",
+]
+
+[project.optional-dependencies]
+test = ["pytest==7.4.0"]
+
+[tool.poetry.dependencies]
+python = "^3.10"
+httpx = { version = "^0.25", extras = ["http2"] }
+
+[tool.poetry.group.dev.dependencies]
+black = "^23.0"
+`,
+			wantErr: assert.NoError,
+			want: []models.Dependency{
+				{Name: "fastapi", Version: ">=0.100"},
+				{Name: "pydantic", Version: ">=2,<3"},
+				{Name: "pytest", Version: "7.4.0", Scope: "optional"},
+				{Name: "httpx", Version: "^0.25"},
+				{Name: "black", Version: "^23.0", Scope: "dev"},
+			},
+		},
+		"pyproject.toml invalid line, return error": {
+			parse:   parsePyproject,
+			content: "[project]\ndependencies\n",
+			wantErr: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			manifest := &models.Manifest{}
+			err := tt.parse([]byte(tt.content), manifest)
+			tt.wantErr(t, err)
+			if err != nil {
+				return
+			}
+			assert.Equal(t, tt.want, manifest.Dependencies)
+			assert.Equal(t, tt.wantGoVer, manifest.GoVersion)
+		})
+	}
+}
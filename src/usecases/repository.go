@@ -1,11 +1,11 @@
 package usecases
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"strconv"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/Scalingo/sclng-backend-test-v1/src/models"
@@ -16,88 +16,234 @@ import (
 type RepositoryUseCase interface {
 	SearchRepositories(rsp *models.RepositorySearchParams) (*models.RepositorySearchResponse, error)
 	ValidateQuery(query string) (language string, err error)
+	CompileQuery(search *models.StructuredSearch) (string, error)
+	ExplainQuery(rsp *models.RepositorySearchParams) (*models.QueryExplanation, error)
+	ResolveCursor(cursor string) (*models.RepositorySearchParams, error)
+	ExportRepositories(rsp *models.RepositorySearchParams, checkpoint *models.ExportCheckpoint, onCheckpoint ExportCheckpointFunc) (*models.ExportResult, error)
+	StreamRepositories(rsp *models.RepositorySearchParams, emit StreamFunc) error
+	SearchBatch(rsps []*models.RepositorySearchParams) []BatchResult
+	GetRepository(params *models.RepositoryParams) (*models.Repository, error)
+	SearchCode(params *models.CodeSearchParams) (*models.CodeSearchResult, error)
 }
 
 type repositoryUseCase struct {
-	gr repositories.GitHubRepository
+	gr         repositories.GitHubRepository
+	cursors    *cursorCodec
+	languages  *ttlCache[cachedLanguages]
+	activities *ttlCache[*models.Activity]
+
+	// counter and skipEnrichment are only set on the copy of the use case serving a budgeted request
+	counter        *countingRepository
+	skipEnrichment bool
 }
 
 // NewRepositoryUseCase creates a new repository use case
-func NewRepositoryUseCase(gr repositories.GitHubRepository) RepositoryUseCase {
+// cursorSecret signs the pagination cursors, a random one is generated when empty
+func NewRepositoryUseCase(gr repositories.GitHubRepository, cursorSecret []byte) RepositoryUseCase {
 	return &repositoryUseCase{
-		gr: gr,
+		gr:         gr,
+		cursors:    newCursorCodec(cursorSecret),
+		languages:  newTTLCache[cachedLanguages](languagesCacheTTL, languagesCacheSize),
+		activities: newTTLCache[*models.Activity](activityCacheTTL, activityCacheSize),
 	}
 }
 
-// SearchRepositories searches repositories and fetches their languages concurrently
+// SearchRepositories searches repositories and runs the included enrichments concurrently
+// The request is adapted to its budget of GitHub calls, and the calls it spent are reported.
 func (ru *repositoryUseCase) SearchRepositories(rsp *models.RepositorySearchParams) (*models.RepositorySearchResponse, error) {
-	repos, err := ru.gr.SearchRepositories(rsp)
+	plan, err := planBudget(rsp)
 	if err != nil {
-		log.Print("error searching repositories: ", err)
 		return nil, err
 	}
 
-	errChan := make(chan error, len(repos.Items))
-	var wg sync.WaitGroup
-
-	var mu sync.Mutex
-	clientRepos := make([]models.Repository, 0, len(repos.Items))
-
-	// For each repository, start a goroutine to fetch its languages
-	for i := range repos.Items {
-		wg.Add(1)
-		repo := repos.Items[i]
+	if plan.params.DryRun {
+		cursor := newPageCursor(plan.params)
+		return &models.RepositorySearchResponse{
+			PerPage: cursor.PerPage,
+			Page:    cursor.Page,
+			Items:   []models.Repository{},
+			Cost:    plan.cost,
+		}, nil
+	}
 
-		go func() {
-			defer wg.Done()
+	// The calls of this request are counted on a copy of the use case, so concurrent requests do not mix
+	counter := &countingRepository{GitHubRepository: ru.gr, limit: int64(plan.params.MaxGitHubCalls)}
+	scoped := *ru
+	scoped.gr = counter
+	scoped.counter = counter
+	scoped.skipEnrichment = plan.skipEnrichment
+
+	var resp *models.RepositorySearchResponse
+	if plan.params.Fill {
+		resp, err = scoped.fillPage(plan.params)
+	} else {
+		resp, err = scoped.searchPage(plan.params)
+	}
+	if err == nil && len(plan.params.Facets) > 0 {
+		resp.Facets, err = scoped.searchFacets(plan.params, resp)
+	}
+	counter.cost(plan.cost)
+	if err != nil {
+		if errors.Is(err, ErrBudgetExceeded) {
+			return nil, fmt.Errorf("%w, %d calls spent", err, plan.cost.Spent)
+		}
+		return nil, err
+	}
 
-			languages, err := ru.gr.GetLanguages(repo.FullName, rsp.Header)
-			if err != nil {
-				log.Print("error fetching languages for ", repo.FullName, ": ", err)
-				errChan <- fmt.Errorf("error fetching languages for %s: %w", repo.FullName, err)
-				return
-			}
+	resp.Cost = plan.cost
+	return resp, nil
+}
 
-			// Filter languages to only keep the requested language
-			filteredLanguages := make(models.Languages)
-			queryLanguage := strings.ToUpper(rsp.Language)
+// searchPage searches a single GitHub page and enriches its repositories
+func (ru *repositoryUseCase) searchPage(rsp *models.RepositorySearchParams) (*models.RepositorySearchResponse, error) {
+	repos, err := ru.gr.SearchRepositories(upstreamParams(rsp, rsp.Page))
+	if err != nil {
+		log.Print("error searching repositories: ", err)
+		return nil, err
+	}
 
-			// Convert and check each language from the repo
-			for repoLang, langBytes := range languages {
-				if strings.ToUpper(repoLang) == queryLanguage {
-					filteredLanguages[repoLang] = langBytes
-					break
-				}
-			}
+	clientRepos := repos.Items
+	if !ru.skipEnrichment {
+		if err := ru.checkEnrichmentBudget(repos.Items, rsp); err != nil {
+			return nil, err
+		}
 
-			// If the repository has the requested language (useless i think it has to but just in case)
-			if len(filteredLanguages) > 0 {
-				repo.Languages = filteredLanguages
-				mu.Lock()
-				clientRepos = append(clientRepos, repo)
-				mu.Unlock()
-			}
-		}()
+		enriched, err := ru.enrichRepositories(repos.Items, rsp)
+		if err != nil {
+			log.Print("error fetching repository languages: ", err)
+			return nil, fmt.Errorf("error fetching repository languages: %w", err)
+		}
+		clientRepos = compactRepositories(enriched)
 	}
 
-	wg.Wait()
-	close(errChan)
-
-	if err := <-errChan; err != nil {
-		log.Print("error fetching repository languages: ", err)
-		return nil, fmt.Errorf("error fetching repository languages: %w", err)
+	if models.LocalSorts[rsp.Sort] && !ru.skipEnrichment {
+		sortRepositories(clientRepos, rsp.Sort, rsp.Order)
 	}
 
+	cursor := newPageCursor(rsp)
+
 	return &models.RepositorySearchResponse{
 		TotalCount:        repos.TotalCount,
 		Count:             len(clientRepos),
-		PerPage:           rsp.PerPage,
-		Page:              rsp.Page,
+		PerPage:           cursor.PerPage,
+		Page:              cursor.Page,
 		IncompleteResults: repos.IncompleteResults,
 		Items:             clientRepos,
+		Cursors:           ru.pageCursors(cursor, repos.GitHubPages, repos.TotalCount),
 	}, nil
 }
 
+// pageCursors builds the navigation cursors from the relations of the GitHub Link header
+// GitHub omits the header when there is a single page, first and last are then computed
+func (ru *repositoryUseCase) pageCursors(cursor pageCursor, githubPages map[string]int, totalCount int) *models.PageLinks {
+	last := lastPage(cursor.PerPage, totalCount)
+	if page, ok := githubPages["last"]; ok && page < last {
+		last = page
+	}
+
+	links := &models.PageLinks{
+		First: ru.cursors.encode(cursor.at(1, 0)),
+		Last:  ru.cursors.encode(cursor.at(last, 0)),
+	}
+
+	if page, ok := githubPages["prev"]; ok && page <= last {
+		links.Prev = ru.cursors.encode(cursor.at(page, 0))
+	}
+
+	if page, ok := githubPages["next"]; ok && page <= last {
+		links.Next = ru.cursors.encode(cursor.at(page, 0))
+	}
+
+	return links
+}
+
+// ResolveCursor verifies a cursor and returns the search it records
+func (ru *repositoryUseCase) ResolveCursor(cursor string) (*models.RepositorySearchParams, error) {
+	c, err := ru.cursors.decode(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	rsp := &models.RepositorySearchParams{
+		Query:         c.Query,
+		PerPage:       strconv.Itoa(c.PerPage),
+		Page:          strconv.Itoa(c.Page),
+		LanguagesMode: c.LanguagesMode,
+		Sort:          c.Sort,
+		Order:         c.Order,
+		Fill:          c.Fill,
+		Include:       c.Include,
+		Highlight:     c.Highlight,
+	}
+
+	// Filled pages may resume in the middle of a GitHub page, the offset is read again from the cursor
+	if c.Fill {
+		rsp.Cursor = cursor
+	}
+
+	return rsp, nil
+}
+
+// upstreamParams returns the parameters sent to GitHub for the given page
+// Local qualifiers and sorts, page filling, budgets and facets are unknown to GitHub, they are handled by the use case
+func upstreamParams(rsp *models.RepositorySearchParams, page string) *models.RepositorySearchParams {
+	upstream := *rsp
+	upstream.Query, _ = splitLocalQualifiers(rsp.Query)
+	upstream.Page = page
+	upstream.Fill = false
+	upstream.Cursor = ""
+	upstream.MaxGitHubCalls = 0
+	upstream.OnBudget = ""
+	upstream.DryRun = false
+	upstream.Facets = nil
+	upstream.FacetPages = 0
+	upstream.Include = nil
+	if models.LocalSorts[rsp.Sort] {
+		upstream.Sort = ""
+		upstream.Order = ""
+	}
+	return &upstream
+}
+
+// cachedLanguages are the languages of a repository, inferred is set when they were estimated from its files
+type cachedLanguages struct {
+	languages models.Languages
+	inferred  bool
+}
+
+// getLanguages returns the languages of a repository, inferred from its files when GitHub has none, from the cache when possible
+// Concurrent requests for the same repository share a single fetch.
+func (ru *repositoryUseCase) getLanguages(repo *models.Repository, header string) (models.Languages, bool, error) {
+	cached, shared, err := ru.languages.load(repo.FullName, header, func() (cachedLanguages, error) {
+		languages, err := ru.gr.GetLanguages(repo.FullName, header)
+		if err != nil {
+			return cachedLanguages{}, err
+		}
+
+		// GitHub has no languages for some repositories, like vendored-only or very new ones
+		languages, inferred, err := repositoryLanguages(ru.gr, languages, repo, header)
+		if err != nil {
+			return cachedLanguages{}, err
+		}
+		return cachedLanguages{languages: languages, inferred: inferred}, nil
+	})
+	if shared && ru.counter != nil {
+		ru.counter.hit()
+	}
+	return cached.languages, cached.inferred, err
+}
+
+// compactRepositories drops the repositories discarded by the enrichment, keeping the order given by GitHub
+func compactRepositories(enriched []*models.Repository) []models.Repository {
+	clientRepos := make([]models.Repository, 0, len(enriched))
+	for _, repo := range enriched {
+		if repo != nil {
+			clientRepos = append(clientRepos, *repo)
+		}
+	}
+	return clientRepos
+}
+
 // ValidateQuery verifies the query and filters inside it
 func (ru *repositoryUseCase) ValidateQuery(q string) (language string, err error) {
 	if err := verifyQueryLength(q); err != nil {
@@ -114,25 +260,56 @@ func (ru *repositoryUseCase) ValidateQuery(q string) (language string, err error
 // ValidatorFunc is used to validates a filter
 type ValidatorFunc func(qualifier, value string) error
 
-// validateFilters verifies the filters in the query
+// qualifierKind describes how the values of a search qualifier are validated and normalized
+type qualifierKind struct {
+	validate  ValidatorFunc
+	normalize func(value string) (operator, normalized string)
+	// local qualifiers are unknown to GitHub, they are removed from its query and evaluated on enriched repositories
+	local bool
+}
+
+var (
+	numberQualifier = qualifierKind{validate: validateNumberOperator, normalize: normalizeNumber}
+	equalQualifier  = qualifierKind{validate: validateEqualOperator, normalize: normalizeEqual}
+	dateQualifier   = qualifierKind{validate: validateDateOperator, normalize: normalizeDate}
+	// dependencyQualifier is matched against the packages of the SBOM, see matchesDependencies
+	dependencyQualifier = qualifierKind{validate: validateEqualOperator, normalize: normalizeEqual, local: true}
+)
+
+// qualifiers are the search qualifiers accepted in a query
+var qualifiers = map[string]qualifierKind{
+	"size":      numberQualifier,
+	"topics":    numberQualifier,
+	"stars":     numberQualifier,
+	"followers": numberQualifier,
+	"forks":     numberQualifier,
+	"license":   equalQualifier,
+	"language":  equalQualifier,
+	"created":   dateQualifier,
+	"pushed":    dateQualifier,
+	// Evaluated by the API
+	models.QualifierDependsOn: dependencyQualifier,
+}
+
+// validateFilters verifies the filters in the query, one of them must be the language
 func validateFilters(q string) (language string, err error) {
-	validators := map[string]ValidatorFunc{
-		"size":      validateNumberOperator,
-		"topics":    validateNumberOperator,
-		"stars":     validateNumberOperator,
-		"followers": validateNumberOperator,
-		"forks":     validateNumberOperator,
-		"license":   validateEqualOperator,
-		"language":  validateEqualOperator,
-		"created":   validateDateOperator,
-		"pushed":    validateDateOperator,
+	language, hasLanguageFilter, err := validateQualifiers(q)
+	if err != nil {
+		return "", err
+	}
+
+	if !hasLanguageFilter {
+		return "", fmt.Errorf("no language filter set, please provide one")
 	}
 
-	hasLanguageFilter := false
+	return language, nil
+}
 
+// validateQualifiers verifies each qualifier of the query and returns the language one, if any
+func validateQualifiers(q string) (language string, hasLanguageFilter bool, err error) {
 	for _, part := range strings.Fields(q) {
 		if strings.Count(part, ":") > 1 {
-			return "", fmt.Errorf("invalid filter format in '%s': use '+' to separate filters, not ':'", part)
+			return "", false, fmt.Errorf("invalid filter format in '%s': use '+' to separate filters, not ':'", part)
 		}
 
 		qualifier, value, found := strings.Cut(part, ":")
@@ -140,13 +317,13 @@ func validateFilters(q string) (language string, err error) {
 			continue
 		}
 
-		validator, exists := validators[qualifier]
+		kind, exists := qualifiers[qualifier]
 		if !exists {
-			return "", fmt.Errorf("unknown qualifier: %s", qualifier)
+			return "", false, fmt.Errorf("unknown qualifier: %s", qualifier)
 		}
 
-		if err := validator(qualifier, value); err != nil {
-			return "", err
+		if err := kind.validate(qualifier, value); err != nil {
+			return "", false, err
 		}
 
 		if qualifier == "language" {
@@ -155,11 +332,7 @@ func validateFilters(q string) (language string, err error) {
 		}
 	}
 
-	if !hasLanguageFilter {
-		return "", fmt.Errorf("no language filter set, please provide one")
-	}
-
-	return language, nil
+	return language, hasLanguageFilter, nil
 }
 
 // validateNumberOperator verifies number filters
@@ -195,9 +368,16 @@ func validateDateOperator(qualifier, value string) error {
 		return fmt.Errorf("%s cannot be empty", qualifier)
 	}
 
+	if strings.Contains(value, "..") {
+		if _, err := parseDateBounds(value); err != nil {
+			return fmt.Errorf("%s: %w", qualifier, err)
+		}
+		return nil
+	}
+
 	date := extractValue(value)
 
-	_, err := time.Parse("2006-01-02", date)
+	_, err := time.Parse(dateLayout, date)
 	if err != nil {
 		return fmt.Errorf("%s must be a valid date in YYYY-MM-DD format, got '%s'", qualifier, value)
 	}
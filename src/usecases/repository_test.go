@@ -20,20 +20,88 @@ func (m *mockGitHubRepository) SearchRepositories(rsp *models.RepositorySearchPa
 	return args.Get(0).(*models.RepositorySearchResponse), args.Error(1)
 }
 
+func (m *mockGitHubRepository) SearchURL(rsp *models.RepositorySearchParams) string {
+	args := m.Called(rsp)
+	return args.String(0)
+}
+
 func (m *mockGitHubRepository) GetLanguages(repoFullName, header string) (models.Languages, error) {
 	args := m.Called(repoFullName, header)
 	return args.Get(0).(models.Languages), args.Error(1)
 }
 
+func (m *mockGitHubRepository) GetRepository(owner, name, header string) (*models.Repository, error) {
+	args := m.Called(owner, name, header)
+	return args.Get(0).(*models.Repository), args.Error(1)
+}
+
+func (m *mockGitHubRepository) GetTopics(repoFullName, header string) ([]string, error) {
+	args := m.Called(repoFullName, header)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *mockGitHubRepository) GetLatestRelease(repoFullName, header string) (*models.Release, error) {
+	args := m.Called(repoFullName, header)
+	return args.Get(0).(*models.Release), args.Error(1)
+}
+
+func (m *mockGitHubRepository) CountIssues(query, header string) (int, error) {
+	args := m.Called(query, header)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockGitHubRepository) GetCommunityProfile(repoFullName, header string) (*models.CommunityProfile, error) {
+	args := m.Called(repoFullName, header)
+	return args.Get(0).(*models.CommunityProfile), args.Error(1)
+}
+
+func (m *mockGitHubRepository) GetCommitActivity(repoFullName, header string) ([]models.WeeklyCommits, error) {
+	args := m.Called(repoFullName, header)
+	return args.Get(0).([]models.WeeklyCommits), args.Error(1)
+}
+
+func (m *mockGitHubRepository) GetParticipation(repoFullName, header string) (*models.Participation, error) {
+	args := m.Called(repoFullName, header)
+	return args.Get(0).(*models.Participation), args.Error(1)
+}
+
+func (m *mockGitHubRepository) GetContributorCount(repoFullName, header string) (int, error) {
+	args := m.Called(repoFullName, header)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *mockGitHubRepository) GetSBOM(repoFullName, header string) ([]models.Dependency, error) {
+	args := m.Called(repoFullName, header)
+	return args.Get(0).([]models.Dependency), args.Error(1)
+}
+
+func (m *mockGitHubRepository) GetFileContents(repoFullName, path, header string) ([]byte, error) {
+	args := m.Called(repoFullName, path, header)
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *mockGitHubRepository) GetTree(repoFullName, ref, header string) (*models.Tree, error) {
+	args := m.Called(repoFullName, ref, header)
+	return args.Get(0).(*models.Tree), args.Error(1)
+}
+
+func (m *mockGitHubRepository) SearchCode(params *models.CodeSearchParams) (*models.CodeSearchResponse, error) {
+	args := m.Called(params)
+	return args.Get(0).(*models.CodeSearchResponse), args.Error(1)
+}
+
+var testCursorSecret = []byte("secret")
+
 func TestNewRepositoryUseCase(t *testing.T) {
 	mockRepo := &mockGitHubRepository{}
-	usecase := NewRepositoryUseCase(mockRepo)
+	usecase := NewRepositoryUseCase(mockRepo, testCursorSecret)
 
 	assert.NotNil(t, usecase)
 
 	ru, ok := usecase.(*repositoryUseCase)
 	assert.True(t, ok)
 	assert.Equal(t, mockRepo, ru.gr)
+	assert.Equal(t, testCursorSecret, ru.cursors.secret)
 }
 
 func TestSearchRepositories(t *testing.T) {
@@ -73,6 +141,70 @@ func TestSearchRepositories(t *testing.T) {
 				assert.Equal(t, "scalingo/scalingo-test", resp.Items[0].FullName)
 			},
 		},
+		"github sort keeps github order": {
+			rsp: &models.RepositorySearchParams{
+				Language: language,
+				Query:    "tetris" + query,
+				Sort:     "stars",
+				Order:    models.OrderDesc,
+			},
+			mockCall: func(m *mockGitHubRepository) {
+				response := &models.RepositorySearchResponse{
+					TotalCount: 3,
+					Items: []models.Repository{
+						{FullName: "scalingo/first"},
+						{FullName: "scalingo/second"},
+						{FullName: "scalingo/third"},
+					},
+				}
+
+				m.On("SearchRepositories", &models.RepositorySearchParams{
+					Language: language,
+					Query:    "tetris" + query,
+					Sort:     "stars",
+					Order:    models.OrderDesc,
+				}).Return(response, nil)
+				m.On("GetLanguages", "scalingo/first", "").Return(models.Languages{"Go": 10}, nil)
+				m.On("GetLanguages", "scalingo/second", "").Return(models.Languages{"Rust": 10}, nil)
+				m.On("GetLanguages", "scalingo/third", "").Return(models.Languages{"Go": 30}, nil)
+			},
+			wantError: assert.NoError,
+			checkResponse: func(t *testing.T, resp *models.RepositorySearchResponse) {
+				assert.Equal(t, 2, resp.Count)
+				assert.Equal(t, "scalingo/first", resp.Items[0].FullName)
+				assert.Equal(t, "scalingo/third", resp.Items[1].FullName)
+			},
+		},
+		"local sort is not sent to github": {
+			rsp: &models.RepositorySearchParams{
+				Language: language,
+				Query:    "tetris" + query,
+				Sort:     models.SortLanguageShare,
+				Order:    models.OrderDesc,
+			},
+			mockCall: func(m *mockGitHubRepository) {
+				response := &models.RepositorySearchResponse{
+					TotalCount: 2,
+					Items: []models.Repository{
+						{FullName: "scalingo/first"},
+						{FullName: "scalingo/second"},
+					},
+				}
+
+				m.On("SearchRepositories", &models.RepositorySearchParams{
+					Language: language,
+					Query:    "tetris" + query,
+				}).Return(response, nil)
+				m.On("GetLanguages", "scalingo/first", "").Return(models.Languages{"Go": 10, "C": 90}, nil)
+				m.On("GetLanguages", "scalingo/second", "").Return(models.Languages{"Go": 30}, nil)
+			},
+			wantError: assert.NoError,
+			checkResponse: func(t *testing.T, resp *models.RepositorySearchResponse) {
+				assert.Equal(t, 2, resp.Count)
+				assert.Equal(t, "scalingo/second", resp.Items[0].FullName)
+				assert.Equal(t, "scalingo/first", resp.Items[1].FullName)
+			},
+		},
 		"error search": {
 			rsp: &models.RepositorySearchParams{
 				Language: language,
@@ -121,7 +253,7 @@ func TestSearchRepositories(t *testing.T) {
 				tt.mockCall(mockRepo)
 			}
 
-			ru := NewRepositoryUseCase(mockRepo)
+			ru := NewRepositoryUseCase(mockRepo, testCursorSecret)
 			resp, err := ru.SearchRepositories(tt.rsp)
 
 			tt.wantError(t, err)
@@ -325,6 +457,21 @@ func TestValidateDateOperator(t *testing.T) {
 			value:     "2024-13-45",
 			wantError: assert.Error,
 		},
+		"valid date range": {
+			qualifier: "created",
+			value:     "2024-01-01..2024-12-31",
+			wantError: assert.NoError,
+		},
+		"open date range": {
+			qualifier: "pushed",
+			value:     "2024-01-01..*",
+			wantError: assert.NoError,
+		},
+		"reversed date range, return error": {
+			qualifier: "created",
+			value:     "2024-12-31..2024-01-01",
+			wantError: assert.Error,
+		},
 		"not a date, return error": {
 			qualifier: "pushed",
 			value:     "hello",
@@ -491,3 +638,106 @@ func TestVerifyQueryLength(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveCursor(t *testing.T) {
+	codec := newCursorCodec(testCursorSecret)
+	ru := NewRepositoryUseCase(&mockGitHubRepository{}, testCursorSecret)
+
+	tests := map[string]struct {
+		cursor    string
+		want      *models.RepositorySearchParams
+		wantError assert.ErrorAssertionFunc
+	}{
+		"page cursor": {
+			cursor: codec.encode(pageCursor{Query: "language:go", Sort: "stars", Order: "asc", PerPage: 30, Page: 2}),
+			want: &models.RepositorySearchParams{
+				Query:   "language:go",
+				Sort:    "stars",
+				Order:   "asc",
+				PerPage: "30",
+				Page:    "2",
+			},
+			wantError: assert.NoError,
+		},
+		"filled page cursor keeps the cursor for its offset": {
+			cursor: codec.encode(pageCursor{Query: "language:go", Fill: true, PerPage: 30, Page: 2, Offset: 5}),
+			want: &models.RepositorySearchParams{
+				Query:   "language:go",
+				PerPage: "30",
+				Page:    "2",
+				Fill:    true,
+				Cursor:  codec.encode(pageCursor{Query: "language:go", Fill: true, PerPage: 30, Page: 2, Offset: 5}),
+			},
+			wantError: assert.NoError,
+		},
+		"cursor with includes and highlight": {
+			cursor: codec.encode(pageCursor{Query: "language:go", Sort: "activity", Include: []string{"activity"}, Highlight: true, PerPage: 30, Page: 2}),
+			want: &models.RepositorySearchParams{
+				Query:     "language:go",
+				Sort:      "activity",
+				PerPage:   "30",
+				Page:      "2",
+				Include:   []string{"activity"},
+				Highlight: true,
+			},
+			wantError: assert.NoError,
+		},
+		"forged cursor, return error": {
+			cursor:    "forged.cursor",
+			wantError: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rsp, err := ru.ResolveCursor(tt.cursor)
+			tt.wantError(t, err)
+			assert.Equal(t, tt.want, rsp)
+		})
+	}
+}
+
+func TestPageCursors(t *testing.T) {
+	codec := newCursorCodec(testCursorSecret)
+	ru := &repositoryUseCase{cursors: codec}
+	cursor := pageCursor{Query: "language:go", PerPage: 100, Page: 2}
+
+	tests := map[string]struct {
+		githubPages map[string]int
+		totalCount  int
+		want        *models.PageLinks
+	}{
+		"relations from github": {
+			githubPages: map[string]int{"first": 1, "prev": 1, "next": 3, "last": 10},
+			totalCount:  5000,
+			want: &models.PageLinks{
+				First: codec.encode(cursor.at(1, 0)),
+				Prev:  codec.encode(cursor.at(1, 0)),
+				Next:  codec.encode(cursor.at(3, 0)),
+				Last:  codec.encode(cursor.at(10, 0)),
+			},
+		},
+		"single page without link header": {
+			totalCount: 42,
+			want: &models.PageLinks{
+				First: codec.encode(cursor.at(1, 0)),
+				Last:  codec.encode(cursor.at(1, 0)),
+			},
+		},
+		"relations beyond search window are dropped": {
+			githubPages: map[string]int{"prev": 1, "next": 11, "last": 50},
+			totalCount:  5000,
+			want: &models.PageLinks{
+				First: codec.encode(cursor.at(1, 0)),
+				Prev:  codec.encode(cursor.at(1, 0)),
+				Last:  codec.encode(cursor.at(10, 0)),
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ru.pageCursors(cursor, tt.githubPages, tt.totalCount))
+		})
+	}
+}
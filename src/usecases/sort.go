@@ -0,0 +1,45 @@
+package usecases
+
+import (
+	"sort"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// sortKeys extracts the value used by each local sort from an enriched repository
+var sortKeys = map[string]func(models.Repository) float64{
+	models.SortLanguageBytes: func(r models.Repository) float64 {
+		if r.LanguageStats == nil {
+			return 0
+		}
+		return float64(r.LanguageStats.RequestedBytes)
+	},
+	models.SortLanguageShare: func(r models.Repository) float64 {
+		if r.LanguageStats == nil {
+			return 0
+		}
+		return r.LanguageStats.RequestedShare
+	},
+	models.SortActivity: func(r models.Repository) float64 {
+		if r.Activity == nil {
+			return 0
+		}
+		return float64(r.Activity.Last12Weeks)
+	},
+}
+
+// sortRepositories sorts enriched repositories with a local sort key
+// The sort is stable so repositories with the same value keep the order given by GitHub
+func sortRepositories(repos []models.Repository, key, order string) {
+	value, ok := sortKeys[key]
+	if !ok {
+		return
+	}
+
+	sort.SliceStable(repos, func(i, j int) bool {
+		if order == models.OrderAsc {
+			return value(repos[i]) < value(repos[j])
+		}
+		return value(repos[i]) > value(repos[j])
+	})
+}
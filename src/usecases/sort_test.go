@@ -0,0 +1,64 @@
+package usecases
+
+import (
+	"testing"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSortRepositories(t *testing.T) {
+	repos := func() []models.Repository {
+		return []models.Repository{
+			{FullName: "a", LanguageStats: &models.LanguageStats{RequestedBytes: 10, RequestedShare: 90}, Activity: &models.Activity{Last12Weeks: 4}},
+			{FullName: "b", LanguageStats: &models.LanguageStats{RequestedBytes: 300, RequestedShare: 20}},
+			{FullName: "c", Activity: &models.Activity{Last12Weeks: 40}},
+			{FullName: "d", LanguageStats: &models.LanguageStats{RequestedBytes: 300, RequestedShare: 50}},
+		}
+	}
+
+	tests := map[string]struct {
+		key      string
+		order    string
+		expected []string
+	}{
+		"language bytes descending keeps github order on ties": {
+			key:      models.SortLanguageBytes,
+			order:    models.OrderDesc,
+			expected: []string{"b", "d", "a", "c"},
+		},
+		"language bytes ascending": {
+			key:      models.SortLanguageBytes,
+			order:    models.OrderAsc,
+			expected: []string{"c", "a", "b", "d"},
+		},
+		"language share descending": {
+			key:      models.SortLanguageShare,
+			order:    models.OrderDesc,
+			expected: []string{"a", "d", "b", "c"},
+		},
+		"activity descending": {
+			key:      models.SortActivity,
+			order:    models.OrderDesc,
+			expected: []string{"c", "a", "b", "d"},
+		},
+		"unknown key keeps order": {
+			key:      "stars",
+			order:    models.OrderDesc,
+			expected: []string{"a", "b", "c", "d"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			sorted := repos()
+			sortRepositories(sorted, tt.key, tt.order)
+
+			names := make([]string, 0, len(sorted))
+			for _, repo := range sorted {
+				names = append(names, repo.FullName)
+			}
+			assert.Equal(t, tt.expected, names)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package usecases
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+// StreamFunc receives the events of a stream, in order, from a single goroutine
+// Returning an error (the client went away) stops the stream.
+type StreamFunc func(event string, data interface{}) error
+
+// enrichment is the outcome of the enrichment of a single repository
+type enrichment struct {
+	repo *models.Repository
+	err  error
+}
+
+// StreamRepositories searches a page of repositories and emits each of them as soon as its languages are fetched
+// Failing enrichments do not stop the stream, they are reported in the summary.
+func (ru *repositoryUseCase) StreamRepositories(rsp *models.RepositorySearchParams, emit StreamFunc) error {
+	if rsp.Fill {
+		return fmt.Errorf("fill cannot be used when streaming")
+	}
+
+	// Local sorts need every repository before the first one can be sent
+	if models.LocalSorts[rsp.Sort] {
+		return fmt.Errorf("sort %s cannot be used when streaming", rsp.Sort)
+	}
+
+	repos, err := ru.gr.SearchRepositories(upstreamParams(rsp, rsp.Page))
+	if err != nil {
+		log.Print("error searching repositories: ", err)
+		return err
+	}
+
+	cursor := newPageCursor(rsp)
+
+	err = emit(models.StreamEventMetadata, &models.StreamMetadata{
+		TotalCount:        repos.TotalCount,
+		PerPage:           cursor.PerPage,
+		Page:              cursor.Page,
+		IncompleteResults: repos.IncompleteResults,
+		Pending:           len(repos.Items),
+		Cursors:           ru.pageCursors(cursor, repos.GitHubPages, repos.TotalCount),
+	})
+	if err != nil {
+		return err
+	}
+
+	// The channel holds every result so goroutines never block, even when the stream stops early
+	results := make(chan enrichment, len(repos.Items))
+	slots := make(chan struct{}, maxConcurrentEnrichments)
+	for _, repo := range repos.Items {
+		repo := repo
+		go func() {
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			enriched, err := ru.enrichRepository(repo, rsp)
+			results <- enrichment{repo: enriched, err: err}
+		}()
+	}
+
+	summary := &models.StreamSummary{Errors: []string{}}
+	for range repos.Items {
+		result := <-results
+
+		switch {
+		case result.err != nil:
+			summary.Failed++
+			summary.Errors = append(summary.Errors, result.err.Error())
+		case result.repo == nil:
+			summary.Dropped++
+		default:
+			summary.Count++
+			if err := emit(models.StreamEventRepository, result.repo); err != nil {
+				return err
+			}
+		}
+	}
+
+	return emit(models.StreamEventSummary, summary)
+}
@@ -0,0 +1,155 @@
+package usecases
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+type streamedEvent struct {
+	event string
+	data  interface{}
+}
+
+func TestStreamRepositories(t *testing.T) {
+	rsp := &models.RepositorySearchParams{
+		Query:    "tetris language:go",
+		Language: "go",
+		PerPage:  "3",
+		Page:     "1",
+	}
+
+	tests := map[string]struct {
+		rsp         *models.RepositorySearchParams
+		mockCall    func(*mockGitHubRepository)
+		wantError   assert.ErrorAssertionFunc
+		checkEvents func(*testing.T, []streamedEvent)
+	}{
+		"nominal": {
+			rsp: rsp,
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", rsp).Return(&models.RepositorySearchResponse{
+					TotalCount: 3,
+					Items:      []models.Repository{{FullName: "a"}, {FullName: "b"}, {FullName: "c"}},
+				}, nil)
+				m.On("GetLanguages", "a", "").Return(models.Languages{"Go": 1}, nil)
+				m.On("GetLanguages", "b", "").Return(models.Languages{"Rust": 1}, nil)
+				m.On("GetLanguages", "c", "").Return(models.Languages(nil), errors.New("not found"))
+			},
+			wantError: assert.NoError,
+			checkEvents: func(t *testing.T, events []streamedEvent) {
+				assert.Len(t, events, 3)
+
+				assert.Equal(t, models.StreamEventMetadata, events[0].event)
+				metadata := events[0].data.(*models.StreamMetadata)
+				assert.Equal(t, 3, metadata.TotalCount)
+				assert.Equal(t, 3, metadata.Pending)
+				assert.NotEmpty(t, metadata.Cursors.First)
+
+				assert.Equal(t, models.StreamEventRepository, events[1].event)
+				assert.Equal(t, "a", events[1].data.(*models.Repository).FullName)
+
+				assert.Equal(t, models.StreamEventSummary, events[2].event)
+				assert.Equal(t, &models.StreamSummary{
+					Count:   1,
+					Dropped: 1,
+					Failed:  1,
+					Errors:  []string{"error fetching languages for c: not found"},
+				}, events[2].data)
+			},
+		},
+		"search error, return error": {
+			rsp: rsp,
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", rsp).Return(&models.RepositorySearchResponse{}, errors.New("rate limit exceeded"))
+			},
+			wantError: assert.Error,
+			checkEvents: func(t *testing.T, events []streamedEvent) {
+				assert.Empty(t, events)
+			},
+		},
+		"fill, return error": {
+			rsp:       &models.RepositorySearchParams{Query: "language:go", Fill: true},
+			mockCall:  func(m *mockGitHubRepository) {},
+			wantError: assert.Error,
+			checkEvents: func(t *testing.T, events []streamedEvent) {
+				assert.Empty(t, events)
+			},
+		},
+		"local sort, return error": {
+			rsp:       &models.RepositorySearchParams{Query: "language:go", Sort: models.SortLanguageShare},
+			mockCall:  func(m *mockGitHubRepository) {},
+			wantError: assert.Error,
+			checkEvents: func(t *testing.T, events []streamedEvent) {
+				assert.Empty(t, events)
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mockRepo := new(mockGitHubRepository)
+			tt.mockCall(mockRepo)
+			ru := NewRepositoryUseCase(mockRepo, testCursorSecret)
+
+			var events []streamedEvent
+			err := ru.StreamRepositories(tt.rsp, func(event string, data interface{}) error {
+				events = append(events, streamedEvent{event: event, data: data})
+				return nil
+			})
+
+			tt.wantError(t, err)
+			tt.checkEvents(t, events)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestStreamRepositoriesOrder(t *testing.T) {
+	rsp := &models.RepositorySearchParams{Query: "language:go", Language: "go", PerPage: "2", Page: "1"}
+
+	// The languages of b are only returned once a has been emitted
+	aEmitted := make(chan time.Time)
+
+	mockRepo := new(mockGitHubRepository)
+	mockRepo.On("SearchRepositories", rsp).Return(&models.RepositorySearchResponse{
+		TotalCount: 2,
+		Items:      []models.Repository{{FullName: "b"}, {FullName: "a"}},
+	}, nil)
+	mockRepo.On("GetLanguages", "a", "").Return(models.Languages{"Go": 1}, nil)
+	mockRepo.On("GetLanguages", "b", "").WaitUntil(aEmitted).Return(models.Languages{"Go": 1}, nil)
+
+	var names []string
+	err := NewRepositoryUseCase(mockRepo, testCursorSecret).StreamRepositories(rsp, func(event string, data interface{}) error {
+		if repo, ok := data.(*models.Repository); ok {
+			names = append(names, repo.FullName)
+			if repo.FullName == "a" {
+				close(aEmitted)
+			}
+		}
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, names)
+}
+
+func TestStreamRepositoriesStop(t *testing.T) {
+	rsp := &models.RepositorySearchParams{Query: "language:go", Language: "go", PerPage: "2", Page: "1"}
+
+	mockRepo := new(mockGitHubRepository)
+	mockRepo.On("SearchRepositories", rsp).Return(&models.RepositorySearchResponse{TotalCount: 1}, nil)
+
+	gone := errors.New("client gone")
+	calls := 0
+	err := NewRepositoryUseCase(mockRepo, testCursorSecret).StreamRepositories(rsp, func(event string, data interface{}) error {
+		calls++
+		return gone
+	})
+
+	assert.ErrorIs(t, err, gone)
+	assert.Equal(t, 1, calls)
+}
@@ -0,0 +1,164 @@
+package usecases
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+)
+
+const monthLayout = "2006-01"
+
+// LanguageTimeseries counts the repositories of a language month by month, with a search per month
+// Searches run concurrently, at most maxConcurrentSearches at a time. When GitHub rate limits the token,
+// the remaining months are not searched and the series is returned incomplete.
+func (lu *languageUseCase) LanguageTimeseries(params *models.TimeseriesParams) (*models.LanguageTimeseries, error) {
+	language, months, err := validateTimeseries(params)
+	if err != nil {
+		return nil, err
+	}
+
+	series := &models.LanguageTimeseries{
+		Language: language,
+		Query:    params.Query,
+		By:       params.By,
+		From:     months[0].from.Format(monthLayout),
+		To:       months[len(months)-1].from.Format(monthLayout),
+		Points:   make([]models.TimeseriesPoint, len(months)),
+		Complete: true,
+	}
+
+	var (
+		stopped int32
+		mu      sync.Mutex
+		errs    []error
+		wg      sync.WaitGroup
+	)
+	slots := make(chan struct{}, maxConcurrentSearches)
+
+	for i, month := range months {
+		series.Points[i].Month = month.from.Format(monthLayout)
+
+		wg.Add(1)
+		i, month := i, month
+
+		go func() {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			// A failed month stops the months not started yet
+			if atomic.LoadInt32(&stopped) == 1 {
+				return
+			}
+
+			count, err := lu.count(monthQuery(params.Query, params.By, month, language), params.Header)
+			if err != nil {
+				atomic.StoreInt32(&stopped, 1)
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("error counting %s: %w", month.from.Format(monthLayout), err))
+				mu.Unlock()
+				return
+			}
+
+			c := count.count
+			series.Points[i].Count = &c
+			series.Points[i].Incomplete = count.incomplete
+		}()
+	}
+
+	wg.Wait()
+
+	if len(errs) == 0 {
+		return series, nil
+	}
+
+	// Only rate limits give partial results, any other error fails the series
+	for _, err := range errs {
+		if !errors.Is(err, ErrRateLimited) {
+			return nil, err
+		}
+	}
+
+	counted := 0
+	for _, point := range series.Points {
+		if point.Count != nil {
+			counted++
+		}
+	}
+	if counted == 0 {
+		return nil, errs[0]
+	}
+
+	series.Complete = false
+	series.Error = errs[0].Error()
+	return series, nil
+}
+
+// monthQuery restricts a query to a language and to the repositories created or pushed during a month
+func monthQuery(query, by string, month dateWindow, language string) string {
+	return languageQuery(strings.TrimSpace(query+" "+by+":"+month.String()), language)
+}
+
+// validateTimeseries verifies a time series request and returns its language and months
+// By defaults to TimeseriesByCreated, months cannot be before the creation of GitHub nor after the current month.
+func validateTimeseries(params *models.TimeseriesParams) (string, []dateWindow, error) {
+	language, err := normalizeLanguage(params.Language)
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch params.By {
+	case "":
+		params.By = models.TimeseriesByCreated
+	case models.TimeseriesByCreated, models.TimeseriesByPushed:
+	default:
+		return "", nil, fmt.Errorf("by must be either '%s' or '%s'", models.TimeseriesByCreated, models.TimeseriesByPushed)
+	}
+
+	if err := validateCountQuery(params.Query); err != nil {
+		return "", nil, err
+	}
+	for _, part := range strings.Fields(params.Query) {
+		if strings.HasPrefix(part, params.By+":") {
+			return "", nil, fmt.Errorf("q cannot contain a %s qualifier, the series sets it for each month", params.By)
+		}
+	}
+
+	from, err := time.Parse(monthLayout, params.From)
+	if err != nil {
+		return "", nil, fmt.Errorf("from must be a month in YYYY-MM format, got '%s'", params.From)
+	}
+	to, err := time.Parse(monthLayout, params.To)
+	if err != nil {
+		return "", nil, fmt.Errorf("to must be a month in YYYY-MM format, got '%s'", params.To)
+	}
+
+	current := today()
+	switch {
+	case to.Before(from):
+		return "", nil, fmt.Errorf("from must be before to")
+	case from.Before(githubCreation):
+		return "", nil, fmt.Errorf("from cannot be before %s", githubCreation.Format(monthLayout))
+	case to.After(time.Date(current.Year(), current.Month(), 1, 0, 0, 0, 0, time.UTC)):
+		return "", nil, fmt.Errorf("to cannot be after the current month")
+	}
+
+	var months []dateWindow
+	for month := from; !month.After(to); month = month.AddDate(0, 1, 0) {
+		if len(months) == models.MaxTimeseriesMonths {
+			return "", nil, fmt.Errorf("a series cannot span more than %d months", models.MaxTimeseriesMonths)
+		}
+		months = append(months, dateWindow{from: month, to: month.AddDate(0, 1, -1)})
+	}
+
+	if err := verifyQueryLength(monthQuery(params.Query, params.By, months[0], language)); err != nil {
+		return "", nil, err
+	}
+
+	return language, months, nil
+}
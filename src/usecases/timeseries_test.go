@@ -0,0 +1,171 @@
+package usecases
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/Scalingo/sclng-backend-test-v1/src/models"
+	"github.com/Scalingo/sclng-backend-test-v1/src/repositories"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestLanguageTimeseries(t *testing.T) {
+	current := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	count := func(c int) *int { return &c }
+	rateLimited := fmt.Errorf("%w (status 403): API rate limit exceeded", repositories.ErrRateLimited)
+
+	tests := map[string]struct {
+		params   *models.TimeseriesParams
+		mockCall func(*mockGitHubRepository)
+		wantErr  assert.ErrorAssertionFunc
+		want     *models.LanguageTimeseries
+	}{
+		"nominal": {
+			params: &models.TimeseriesParams{Language: "Go", From: "2024-01", To: "2024-02", Query: "stars:>10", Header: "token"},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", countParams("stars:>10 created:2024-01-01..2024-01-31 language:go")).Return(&models.RepositorySearchResponse{TotalCount: 10}, nil)
+				m.On("SearchRepositories", countParams("stars:>10 created:2024-02-01..2024-02-29 language:go")).Return(&models.RepositorySearchResponse{TotalCount: 20, IncompleteResults: true}, nil)
+			},
+			wantErr: assert.NoError,
+			want: &models.LanguageTimeseries{
+				Language: "go",
+				Query:    "stars:>10",
+				By:       models.TimeseriesByCreated,
+				From:     "2024-01",
+				To:       "2024-02",
+				Points: []models.TimeseriesPoint{
+					{Month: "2024-01", Count: count(10)},
+					{Month: "2024-02", Count: count(20), Incomplete: true},
+				},
+				Complete: true,
+			},
+		},
+		"rate limited, return partial series": {
+			params: &models.TimeseriesParams{Language: "go", From: "2024-01", To: "2024-02", By: models.TimeseriesByPushed, Header: "token"},
+			mockCall: func(m *mockGitHubRepository) {
+				// The rate limit is only hit once January is counted, otherwise January would not be searched
+				counted := make(chan struct{})
+				m.On("SearchRepositories", countParams("pushed:2024-01-01..2024-01-31 language:go")).
+					Run(func(mock.Arguments) { close(counted) }).
+					Return(&models.RepositorySearchResponse{TotalCount: 10}, nil)
+				m.On("SearchRepositories", countParams("pushed:2024-02-01..2024-02-29 language:go")).
+					Run(func(mock.Arguments) { <-counted }).
+					Return((*models.RepositorySearchResponse)(nil), rateLimited)
+			},
+			wantErr: assert.NoError,
+			want: &models.LanguageTimeseries{
+				Language: "go",
+				By:       models.TimeseriesByPushed,
+				From:     "2024-01",
+				To:       "2024-02",
+				Points: []models.TimeseriesPoint{
+					{Month: "2024-01", Count: count(10)},
+					{Month: "2024-02"},
+				},
+				Error: "error counting 2024-02: " + rateLimited.Error(),
+			},
+		},
+		"rate limited before any count, return error": {
+			params: &models.TimeseriesParams{Language: "go", From: "2024-01", To: "2024-01", Header: "token"},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", mock.Anything).Return((*models.RepositorySearchResponse)(nil), rateLimited)
+			},
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrRateLimited)
+			},
+		},
+		"search fails, return error": {
+			params: &models.TimeseriesParams{Language: "go", From: "2024-01", To: "2024-02", Header: "token"},
+			mockCall: func(m *mockGitHubRepository) {
+				m.On("SearchRepositories", countParams("created:2024-01-01..2024-01-31 language:go")).Return(&models.RepositorySearchResponse{TotalCount: 10}, nil).Maybe()
+				m.On("SearchRepositories", countParams("created:2024-02-01..2024-02-29 language:go")).Return((*models.RepositorySearchResponse)(nil), assert.AnError)
+			},
+			wantErr: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			m := new(mockGitHubRepository)
+			if tt.mockCall != nil {
+				tt.mockCall(m)
+			}
+
+			series, err := NewLanguageUseCase(m).LanguageTimeseries(tt.params)
+			tt.wantErr(t, err)
+			assert.Equal(t, tt.want, series)
+		})
+	}
+}
+
+func TestValidateTimeseries(t *testing.T) {
+	current := time.Date(2024, 6, 15, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return current }
+	defer func() { now = time.Now }()
+
+	tests := map[string]struct {
+		params     *models.TimeseriesParams
+		wantErr    assert.ErrorAssertionFunc
+		wantMonths int
+	}{
+		"nominal": {
+			params:     &models.TimeseriesParams{Language: "go", From: "2020-01", To: "2024-06"},
+			wantErr:    assert.NoError,
+			wantMonths: 54,
+		},
+		"single month": {
+			params:     &models.TimeseriesParams{Language: "go", From: "2024-06", To: "2024-06", By: models.TimeseriesByPushed},
+			wantErr:    assert.NoError,
+			wantMonths: 1,
+		},
+		"unknown by, return error": {
+			params:  &models.TimeseriesParams{Language: "go", From: "2024-01", To: "2024-02", By: "updated"},
+			wantErr: assert.Error,
+		},
+		"invalid month, return error": {
+			params:  &models.TimeseriesParams{Language: "go", From: "2024-1", To: "2024-02"},
+			wantErr: assert.Error,
+		},
+		"reversed period, return error": {
+			params:  &models.TimeseriesParams{Language: "go", From: "2024-02", To: "2024-01"},
+			wantErr: assert.Error,
+		},
+		"before github, return error": {
+			params:  &models.TimeseriesParams{Language: "go", From: "2007-12", To: "2008-02"},
+			wantErr: assert.Error,
+		},
+		"future month, return error": {
+			params:  &models.TimeseriesParams{Language: "go", From: "2024-01", To: "2024-07"},
+			wantErr: assert.Error,
+		},
+		"too many months, return error": {
+			params:  &models.TimeseriesParams{Language: "go", From: "2010-01", To: "2024-01"},
+			wantErr: assert.Error,
+		},
+		"by qualifier in query, return error": {
+			params:  &models.TimeseriesParams{Language: "go", From: "2024-01", To: "2024-02", Query: "created:>2020-01-01"},
+			wantErr: assert.Error,
+		},
+		"language qualifier in query, return error": {
+			params:  &models.TimeseriesParams{Language: "go", From: "2024-01", To: "2024-02", Query: "language:rust"},
+			wantErr: assert.Error,
+		},
+		"empty language, return error": {
+			params:  &models.TimeseriesParams{From: "2024-01", To: "2024-02"},
+			wantErr: assert.Error,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, months, err := validateTimeseries(tt.params)
+			tt.wantErr(t, err)
+			assert.Len(t, months, tt.wantMonths)
+		})
+	}
+}